@@ -0,0 +1,162 @@
+// Passkey (WebAuthn) ceremonies for the settings page and /auth
+//
+// Registration: <form data-passkey-register="/app/passkeys" data-options-url="/app/passkeys/options">
+//   Fetches creation options, calls navigator.credentials.create and posts
+//   name + credential with htmx.ajax so the response swaps like any settings form.
+//
+// Sign in: <button data-passkey-login="form-id"> with a form holding a hidden "credential" input
+//   and data-options-url. Fetches request options, calls navigator.credentials.get
+//   and submits the form.
+//
+// Elements marked data-passkey-support are hidden when the browser has no WebAuthn.
+(function () {
+  if (window.__passkeyInit) return;
+  window.__passkeyInit = true;
+
+  function toBuffer(value) {
+    const base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+    const binary = atob(base64 + "=".repeat((4 - (base64.length % 4)) % 4));
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) bytes[i] = binary.charCodeAt(i);
+    return bytes.buffer;
+  }
+
+  function toBase64URL(buffer) {
+    if (!buffer) return null;
+    const bytes = new Uint8Array(buffer);
+    let binary = "";
+    for (let i = 0; i < bytes.length; i++) binary += String.fromCharCode(bytes[i]);
+    return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+  }
+
+  function csrfToken() {
+    const meta = document.querySelector('meta[name="csrf-token"]');
+    return meta ? meta.getAttribute("content") : "";
+  }
+
+  async function fetchOptions(url) {
+    const response = await fetch(url, {
+      method: "POST",
+      credentials: "same-origin",
+      headers: { "X-CSRF-Token": csrfToken() },
+    });
+    const body = await response.json().catch(function () {
+      return {};
+    });
+    if (!response.ok) {
+      throw new Error(body.error || "Something went wrong. Please try again.");
+    }
+    return body.publicKey;
+  }
+
+  async function createCredential(optionsURL) {
+    const options = await fetchOptions(optionsURL);
+    options.challenge = toBuffer(options.challenge);
+    options.user.id = toBuffer(options.user.id);
+    (options.excludeCredentials || []).forEach(function (credential) {
+      credential.id = toBuffer(credential.id);
+    });
+
+    const credential = await navigator.credentials.create({ publicKey: options });
+    return JSON.stringify({
+      id: credential.id,
+      rawId: toBase64URL(credential.rawId),
+      type: credential.type,
+      authenticatorAttachment: credential.authenticatorAttachment,
+      clientExtensionResults: credential.getClientExtensionResults(),
+      response: {
+        clientDataJSON: toBase64URL(credential.response.clientDataJSON),
+        attestationObject: toBase64URL(credential.response.attestationObject),
+        transports: credential.response.getTransports ? credential.response.getTransports() : [],
+      },
+    });
+  }
+
+  async function getCredential(optionsURL) {
+    const options = await fetchOptions(optionsURL);
+    options.challenge = toBuffer(options.challenge);
+    (options.allowCredentials || []).forEach(function (credential) {
+      credential.id = toBuffer(credential.id);
+    });
+
+    const credential = await navigator.credentials.get({ publicKey: options });
+    return JSON.stringify({
+      id: credential.id,
+      rawId: toBase64URL(credential.rawId),
+      type: credential.type,
+      authenticatorAttachment: credential.authenticatorAttachment,
+      clientExtensionResults: credential.getClientExtensionResults(),
+      response: {
+        clientDataJSON: toBase64URL(credential.response.clientDataJSON),
+        authenticatorData: toBase64URL(credential.response.authenticatorData),
+        signature: toBase64URL(credential.response.signature),
+        userHandle: toBase64URL(credential.response.userHandle),
+      },
+    });
+  }
+
+  function errorMessage(err) {
+    if (err.name === "NotAllowedError" || err.name === "AbortError") {
+      return "The passkey request was cancelled or timed out.";
+    }
+    if (err.name === "InvalidStateError") {
+      return "This passkey is already registered.";
+    }
+    return err.message;
+  }
+
+  function showError(container, message) {
+    const el = container.querySelector("[data-passkey-error]");
+    if (!el) return;
+    el.textContent = message;
+    el.hidden = !message;
+  }
+
+  document.addEventListener("submit", async function (e) {
+    const form = e.target.closest("[data-passkey-register]");
+    if (!form) return;
+    e.preventDefault();
+    showError(form, "");
+
+    try {
+      const credential = await createCredential(form.dataset.optionsUrl);
+      await htmx.ajax("POST", form.dataset.passkeyRegister, {
+        swap: "none",
+        values: { name: form.elements.name.value, credential: credential },
+      });
+    } catch (err) {
+      showError(form, errorMessage(err));
+    }
+  });
+
+  document.addEventListener("click", async function (e) {
+    const button = e.target.closest("[data-passkey-login]");
+    if (!button) return;
+    e.preventDefault();
+
+    const form = document.getElementById(button.dataset.passkeyLogin);
+    if (!form) return;
+    showError(form, "");
+
+    try {
+      form.elements.credential.value = await getCredential(form.dataset.optionsUrl);
+      form.submit();
+    } catch (err) {
+      showError(form, errorMessage(err));
+    }
+  });
+
+  function hideUnsupported() {
+    if (window.PublicKeyCredential) return;
+    document.querySelectorAll("[data-passkey-support]").forEach(function (el) {
+      el.hidden = true;
+    });
+  }
+
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", hideUnsupported);
+  } else {
+    hideUnsupported();
+  }
+  document.addEventListener("htmx:afterSettle", hideUnsupported);
+})();
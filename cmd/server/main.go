@@ -27,6 +27,11 @@ func main() {
 		}
 	}()
 
+	app.Queue.Start()
+	if cfg.SchedulerEnabled {
+		app.Scheduler.Start()
+	}
+
 	handler := routes.SetupRoutes(app)
 	slog.Info("server starting", "port", cfg.Port, "env", cfg.AppEnv, "url", "http://localhost:"+cfg.Port)
 
@@ -6,26 +6,43 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/config"
 	"github.com/templui/goilerplate/internal/db"
+	"github.com/templui/goilerplate/internal/mail"
+	"github.com/templui/goilerplate/internal/oidc"
+	"github.com/templui/goilerplate/internal/queue"
 	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/scheduler"
 	"github.com/templui/goilerplate/internal/service"
 	"github.com/templui/goilerplate/internal/service/payment"
 	"github.com/templui/goilerplate/internal/storage"
 )
 
 type App struct {
-	Cfg                 *config.Config
-	DB                  *sqlx.DB
-	AuthService         *service.AuthService
-	UserService         *service.UserService
-	ProfileService      *service.ProfileService
-	EmailService        *service.EmailService
-	FileService         *service.FileService
-	SubscriptionService *service.SubscriptionService
-	PaymentService      payment.Provider
-	GoalService         *service.GoalService
-	BlogService         *service.BlogService
-	DocsService         *service.DocsService
-	LegalService        *service.LegalService
+	Cfg                  *config.Config
+	DB                   *sqlx.DB
+	FileStorage          storage.Storage
+	MailTransport        mail.Transport
+	OIDCProviders        *oidc.Registry
+	AuthService          *service.AuthService
+	PasskeyService       *service.PasskeyService
+	UserService          *service.UserService
+	ProfileService       *service.ProfileService
+	APITokenService      *service.APITokenService
+	WebhookService       *service.WebhookService
+	EmailService         *service.EmailService
+	FileService          *service.FileService
+	SubscriptionService  *service.SubscriptionService
+	OrganizationService  *service.OrganizationService
+	PaymentService       payment.Provider
+	GoalService          *service.GoalService
+	GoalAnalyticsService *service.GoalAnalyticsService
+	BlogService          *service.BlogService
+	DocsService          *service.DocsService
+	LegalService         *service.LegalService
+	NotificationService  *service.NotificationService
+	AdminService         *service.AdminService
+	AuditService         *service.AuditService
+	Scheduler            *scheduler.Scheduler
+	Queue                *queue.Queue
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -45,10 +62,26 @@ func New(cfg *config.Config) (*App, error) {
 	userRepository := repository.NewUserRepository(database)
 	profileRepository := repository.NewProfileRepository(database)
 	tokenRepository := repository.NewTokenRepository(database)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(database)
+	sessionRepository := repository.NewSessionRepository(database)
 	fileRepository := repository.NewFileRepository(database)
 	subscriptionRepository := repository.NewSubscriptionRepository(database)
 	goalRepository := repository.NewGoalRepository(database)
 	goalEntryRepository := repository.NewGoalEntryRepository(database)
+	jobRepository := repository.NewJobRepository(database)
+	passkeyRepository := repository.NewPasskeyRepository(database)
+	userIdentityRepository := repository.NewUserIdentityRepository(database)
+	apiTokenRepository := repository.NewAPITokenRepository(database)
+	webhookEndpointRepository := repository.NewWebhookEndpointRepository(database)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(database)
+	organizationRepository := repository.NewOrganizationRepository(database)
+	membershipRepository := repository.NewMembershipRepository(database)
+	invitationRepository := repository.NewInvitationRepository(database)
+	adminAuditLogRepository := repository.NewAdminAuditLogRepository(database)
+	auditEventRepository := repository.NewAuditEventRepository(database)
+	knownDeviceRepository := repository.NewKnownDeviceRepository(database)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(database)
+	txManager := repository.NewTxManager(database)
 
 	// Storage
 	fileStorage, err := storage.New(cfg)
@@ -56,6 +89,15 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize storage: %v", err)
 	}
 
+	// Email transport
+	mailTransport, err := mail.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize email transport: %v", err)
+	}
+
+	// Job queue (workers started in main)
+	jobQueue := queue.New(jobRepository, cfg.QueueWorkers, cfg.QueuePollInterval)
+
 	// Services
 	emailService := service.NewEmailService(
 		cfg.ResendAPIKey,
@@ -63,10 +105,24 @@ func New(cfg *config.Config) (*App, error) {
 		cfg.ResendAudienceID,
 		cfg.AppURL,
 		cfg.AppName,
+		cfg.SupportEmail,
+		cfg.EmailLogoURL,
 		cfg.IsDevelopment(),
+		mailTransport,
+		jobQueue,
 	)
+	jobQueue.Register(service.JobSendEmail, emailService.Deliver)
+	webhookService := service.NewWebhookService(
+		webhookEndpointRepository,
+		webhookDeliveryRepository,
+		subscriptionRepository,
+		jobQueue,
+		cfg.AppName,
+		cfg.IsDevelopment(),
+	)
+	jobQueue.Register(service.JobDeliverWebhook, webhookService.Deliver)
 	fileService := service.NewFileService(fileRepository, fileStorage)
-	subscriptionService := service.NewSubscriptionService(subscriptionRepository)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepository, membershipRepository, webhookService)
 
 	// Initialize payment provider based on config
 	paymentProvider, err := payment.NewProvider(cfg, subscriptionService)
@@ -74,13 +130,36 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize payment provider: %v", err)
 	}
 
-	goalService := service.NewGoalService(goalRepository, goalEntryRepository, fileRepository, subscriptionService)
+	organizationService := service.NewOrganizationService(
+		organizationRepository,
+		membershipRepository,
+		invitationRepository,
+		userRepository,
+		goalRepository,
+		fileService,
+		txManager,
+		subscriptionService,
+		paymentProvider,
+		emailService,
+	)
+	goalService := service.NewGoalService(goalRepository, goalEntryRepository, fileRepository, fileService, txManager, subscriptionService, webhookService)
+	goalAnalyticsService := service.NewGoalAnalyticsService(goalRepository, goalEntryRepository)
+	auditService := service.NewAuditService(auditEventRepository)
 	authService := service.NewAuthService(
 		userRepository,
 		profileRepository,
 		tokenRepository,
-		subscriptionService,
+		recoveryCodeRepository,
+		sessionRepository,
+		userIdentityRepository,
+		passkeyRepository,
+		knownDeviceRepository,
+		loginAttemptRepository,
+		txManager,
+		organizationService,
 		emailService,
+		auditService,
+		cfg.AppName,
 		cfg.JWTSecret,
 		cfg.IsProduction(),
 		cfg.JWTExpiry,
@@ -89,30 +168,101 @@ func New(cfg *config.Config) (*App, error) {
 		cfg.TokenEmailChangeExpiry,
 		cfg.TokenMagicLinkExpiry,
 	)
-	userService := service.NewUserService(userRepository, profileRepository, fileService, emailService, subscriptionService)
+	passkeyService, err := service.NewPasskeyService(
+		passkeyRepository,
+		userRepository,
+		cfg.AppName,
+		cfg.AppURL,
+		cfg.JWTSecret,
+		cfg.IsProduction(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize passkeys: %v", err)
+	}
+	userService := service.NewUserService(userRepository, profileRepository, fileService, emailService, organizationService, auditService, txManager)
 	profileService := service.NewProfileService(profileRepository)
+	apiTokenService := service.NewAPITokenService(apiTokenRepository)
 	blogService := service.NewBlogService(cfg.ContentPath)
 	docsService := service.NewDocsService(cfg.ContentPath)
 	legalService := service.NewLegalService(cfg.ContentPath)
+	notificationService := service.NewNotificationService(
+		profileRepository,
+		userRepository,
+		goalRepository,
+		goalEntryRepository,
+		emailService,
+		cfg.JWTSecret,
+	)
+
+	adminService := service.NewAdminService(
+		userRepository,
+		profileRepository,
+		goalRepository,
+		adminAuditLogRepository,
+		fileService,
+		subscriptionService,
+		organizationService,
+		authService,
+		userService,
+	)
+	adminService.GrantAdmins(cfg.AdminEmails)
+
+	// Background jobs (started in main when enabled)
+	jobScheduler := scheduler.New(cfg.SchedulerInterval)
+	jobScheduler.Add("goal-reminders", notificationService.SendDueReminders)
+	jobScheduler.Add("weekly-digest", notificationService.SendDueDigests)
+	jobScheduler.Add("job-cleanup", jobQueue.Cleanup)
+	jobScheduler.Add("webhook-delivery-cleanup", webhookService.Cleanup)
 
 	return &App{
-		Cfg:                 cfg,
-		DB:                  database,
-		AuthService:         authService,
-		UserService:         userService,
-		ProfileService:      profileService,
-		EmailService:        emailService,
-		FileService:         fileService,
-		SubscriptionService: subscriptionService,
-		PaymentService:      paymentProvider,
-		GoalService:         goalService,
-		BlogService:         blogService,
-		DocsService:         docsService,
-		LegalService:        legalService,
+		Cfg:                  cfg,
+		DB:                   database,
+		FileStorage:          fileStorage,
+		MailTransport:        mailTransport,
+		OIDCProviders:        oidc.New(cfg),
+		AuthService:          authService,
+		PasskeyService:       passkeyService,
+		UserService:          userService,
+		ProfileService:       profileService,
+		APITokenService:      apiTokenService,
+		WebhookService:       webhookService,
+		EmailService:         emailService,
+		FileService:          fileService,
+		SubscriptionService:  subscriptionService,
+		OrganizationService:  organizationService,
+		PaymentService:       paymentProvider,
+		GoalService:          goalService,
+		GoalAnalyticsService: goalAnalyticsService,
+		BlogService:          blogService,
+		DocsService:          docsService,
+		LegalService:         legalService,
+		NotificationService:  notificationService,
+		AdminService:         adminService,
+		AuditService:         auditService,
+		Scheduler:            jobScheduler,
+		Queue:                jobQueue,
 	}, nil
 }
 
 func (a *App) Close() error {
+	if a.Scheduler != nil {
+		a.Scheduler.Stop()
+	}
+	if a.Queue != nil {
+		a.Queue.Stop()
+	}
+	if localStorage, ok := a.FileStorage.(*storage.LocalStorage); ok {
+		err := localStorage.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if outbox, ok := a.MailTransport.(*mail.OutboxTransport); ok {
+		err := outbox.Close()
+		if err != nil {
+			return err
+		}
+	}
 	if a.DB != nil {
 		return a.DB.Close()
 	}
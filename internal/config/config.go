@@ -31,16 +31,30 @@ type Config struct {
 	TokenEmailChangeExpiry   time.Duration
 	TokenMagicLinkExpiry     time.Duration
 
+	// Admin console, these accounts are made admins on startup
+	AdminEmails []string
+
 	// OAuth
 	GoogleClientID     string
 	GoogleClientSecret string
 	GitHubClientID     string
 	GitHubClientSecret string
 
+	// OpenID Connect (any number of providers, see loadOIDCProviders)
+	OIDCProviders []OIDCProvider
+
 	// Email
+	EmailDriver      string // "resend", "smtp", "outbox" or "log"
 	EmailFrom        string
+	EmailLogoURL     string // Optional PNG/JPG logo shown in the email header
+	EmailOutboxPath  string // Directory for the outbox driver
 	ResendAPIKey     string
 	ResendAudienceID string
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPTLS          string // "starttls", "implicit" or "none"
 
 	// Payment
 	PaymentProvider string // "polar" or "stripe"
@@ -70,14 +84,26 @@ type Config struct {
 	// Observability (optional)
 	SentryDSN string
 
+	// Background jobs (goal reminders, weekly digest)
+	SchedulerEnabled  bool          // Disable on all but one instance when running several
+	SchedulerInterval time.Duration // How often due jobs are checked
+
+	// Job queue (outbound email, persisted and retried)
+	QueueWorkers      int           // Concurrent workers per instance
+	QueuePollInterval time.Duration // How often idle workers check for new jobs
+
+	// Storage
+	StorageDriver    string // "s3" (default) or "local"
+	StorageLocalPath string // Base directory for the local driver
+
 	// Storage (S3-compatible: MinIO, AWS S3, Cloudflare R2, DigitalOcean Spaces, etc.)
 	S3Region               string
 	S3Bucket               string
 	S3AccessKey            string
 	S3SecretKey            string
 	S3Endpoint             string        // Optional: for S3-compatible services (MinIO, DO Spaces, R2, etc.)
-	S3PresignExpiryPublic  time.Duration // Expiry for public files (avatars, profile pics) - default: 7 days
-	S3PresignExpiryPrivate time.Duration // Expiry for private files (documents, uploads) - default: 1 hour
+	S3PresignExpiryPublic  time.Duration // Expiry for public files (avatars, profile pics) - default: 7 days (also used by the local driver)
+	S3PresignExpiryPrivate time.Duration // Expiry for private files (documents, uploads) - default: 1 hour (also used by the local driver)
 }
 
 func Load() *Config {
@@ -109,16 +135,28 @@ func Load() *Config {
 		TokenEmailChangeExpiry:   envDuration("TOKEN_EMAIL_CHANGE_EXPIRY", 24*time.Hour),  // 24 hours
 		TokenMagicLinkExpiry:     envDuration("TOKEN_MAGIC_LINK_EXPIRY", 10*time.Minute),  // 10 minutes
 
+		// Admin
+		AdminEmails: envList("ADMIN_EMAILS", nil),
+
 		// OAuth
 		GoogleClientID:     envString("GOOGLE_CLIENT_ID", ""),
 		GoogleClientSecret: envString("GOOGLE_CLIENT_SECRET", ""),
 		GitHubClientID:     envString("GITHUB_CLIENT_ID", ""),
 		GitHubClientSecret: envString("GITHUB_CLIENT_SECRET", ""),
+		OIDCProviders:      loadOIDCProviders(),
 
-		// Email (RESEND_API_KEY optional in development, required in production)
+		// Email (driver defaults to resend in production, log in development)
+		EmailDriver:      envString("EMAIL_DRIVER", ""),
 		EmailFrom:        envString("EMAIL_FROM", "noreply@example.com"),
+		EmailLogoURL:     envString("EMAIL_LOGO_URL", ""),
+		EmailOutboxPath:  envString("EMAIL_OUTBOX_PATH", "./data/outbox"),
 		ResendAPIKey:     envString("RESEND_API_KEY", ""),
 		ResendAudienceID: envString("RESEND_AUDIENCE_ID", ""),
+		SMTPHost:         envString("SMTP_HOST", ""),
+		SMTPPort:         envString("SMTP_PORT", "587"),
+		SMTPUsername:     envString("SMTP_USERNAME", ""),
+		SMTPPassword:     envString("SMTP_PASSWORD", ""),
+		SMTPTLS:          envString("SMTP_TLS", "starttls"),
 
 		// Payment (provider selection and configuration)
 		PaymentProvider:                 envString("PAYMENT_PROVIDER", "polar"), // Default: polar
@@ -146,16 +184,32 @@ func Load() *Config {
 		// Observability
 		SentryDSN: envString("SENTRY_DSN", ""),
 
-		// Storage (S3-compatible - required for avatar uploads)
-		S3Region:               envRequired("S3_REGION"),
-		S3Bucket:               envRequired("S3_BUCKET"),
-		S3AccessKey:            envRequired("S3_ACCESS_KEY"),
-		S3SecretKey:            envRequired("S3_SECRET_KEY"),
+		// Background jobs
+		SchedulerEnabled:  envBool("SCHEDULER_ENABLED", true),
+		SchedulerInterval: envDuration("SCHEDULER_INTERVAL", 5*time.Minute),
+		QueueWorkers:      envInt("QUEUE_WORKERS", 2),
+		QueuePollInterval: envDuration("QUEUE_POLL_INTERVAL", 2*time.Second),
+
+		// Storage (driver selection, default: s3)
+		StorageDriver:    envString("STORAGE_DRIVER", "s3"),
+		StorageLocalPath: envString("STORAGE_LOCAL_PATH", "./data/uploads"),
+
+		// Storage (S3-compatible - required when STORAGE_DRIVER=s3)
+		S3Region:               envString("S3_REGION", ""),
+		S3Bucket:               envString("S3_BUCKET", ""),
+		S3AccessKey:            envString("S3_ACCESS_KEY", ""),
+		S3SecretKey:            envString("S3_SECRET_KEY", ""),
 		S3Endpoint:             envString("S3_ENDPOINT", ""),                           // Optional: for non-AWS providers
 		S3PresignExpiryPublic:  envDuration("S3_PRESIGN_EXPIRY_PUBLIC", 168*time.Hour), // Default: 7 days for public files
 		S3PresignExpiryPrivate: envDuration("S3_PRESIGN_EXPIRY_PRIVATE", 1*time.Hour),  // Default: 1 hour for private files
 	}
 
+	// Storage: S3 credentials are only required for the S3 driver
+	validateStorage(cfg)
+
+	// Email: each driver needs different settings
+	validateEmail(cfg)
+
 	// Production: validate required services
 	if cfg.IsProduction() {
 		validateProduction(cfg)
@@ -167,9 +221,65 @@ func Load() *Config {
 // validateProduction ensures all required services are configured for production deployments.
 // Development allows some services (like email) to use fallback modes for easier local testing.
 func validateProduction(cfg *Config) {
-	if cfg.ResendAPIKey == "" {
-		slog.Error("production deployment requires RESEND_API_KEY",
-			"hint", "set APP_ENV=development for local testing with email log mode")
+	if cfg.EmailDriver == "log" || cfg.EmailDriver == "outbox" {
+		slog.Error("production deployment requires a sending email driver", "email_driver", cfg.EmailDriver,
+			"hint", "set EMAIL_DRIVER to 'resend' or 'smtp'")
+		os.Exit(1)
+	}
+}
+
+// validateEmail picks the default driver and ensures it has everything it needs.
+func validateEmail(cfg *Config) {
+	if cfg.EmailDriver == "" {
+		cfg.EmailDriver = "log"
+		if cfg.IsProduction() {
+			cfg.EmailDriver = "resend"
+		}
+	}
+
+	switch cfg.EmailDriver {
+	case "log", "outbox":
+		return
+	case "resend":
+		if cfg.ResendAPIKey == "" {
+			slog.Error("config required env var missing", "key", "RESEND_API_KEY", "email_driver", cfg.EmailDriver,
+				"hint", "set EMAIL_DRIVER=log or EMAIL_DRIVER=outbox for local testing")
+			os.Exit(1)
+		}
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			slog.Error("config required env var missing", "key", "SMTP_HOST", "email_driver", cfg.EmailDriver)
+			os.Exit(1)
+		}
+	default:
+		slog.Error("unknown email driver", "email_driver", cfg.EmailDriver,
+			"hint", "set EMAIL_DRIVER to 'resend', 'smtp', 'outbox' or 'log'")
+		os.Exit(1)
+	}
+}
+
+// validateStorage ensures the selected storage driver has everything it needs.
+// The local driver works without external services, S3 needs credentials.
+func validateStorage(cfg *Config) {
+	switch cfg.StorageDriver {
+	case "local":
+		return
+	case "s3":
+		required := map[string]string{
+			"S3_REGION":     cfg.S3Region,
+			"S3_BUCKET":     cfg.S3Bucket,
+			"S3_ACCESS_KEY": cfg.S3AccessKey,
+			"S3_SECRET_KEY": cfg.S3SecretKey,
+		}
+		for key, value := range required {
+			if value == "" {
+				slog.Error("config required env var missing", "key", key, "storage_driver", cfg.StorageDriver)
+				os.Exit(1)
+			}
+		}
+	default:
+		slog.Error("unknown storage driver", "storage_driver", cfg.StorageDriver,
+			"hint", "set STORAGE_DRIVER to 's3' or 'local'")
 		os.Exit(1)
 	}
 }
@@ -195,6 +305,19 @@ func envBool(key string, def bool) bool {
 	return b
 }
 
+func envInt(key string, def int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("config invalid int, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return i
+}
+
 func envDuration(key string, def time.Duration) time.Duration {
 	v, ok := os.LookupEnv(key)
 	if !ok || v == "" {
@@ -241,6 +364,7 @@ func (c *Config) Sanitized() *Config {
 
 		GoogleClientID: c.GoogleClientID,
 		GitHubClientID: c.GitHubClientID,
+		OIDCProviders:  sanitizedOIDCProviders(c.OIDCProviders),
 
 		UmamiWebsiteID:    c.UmamiWebsiteID,
 		UmamiHost:         c.UmamiHost,
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// OIDCProvider configures one OpenID Connect identity provider.
+// The ID is used in URLs (/auth/oidc/{id}) and as the provider of linked identities,
+// so changing it (or the issuer) disconnects everyone who signed in with it.
+type OIDCProvider struct {
+	ID           string
+	Name         string // Shown on the sign-in button and in settings
+	Issuer       string // Discovery runs against {Issuer}/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// Claim mapping, defaults follow the OIDC spec
+	SubjectClaim       string // Default: sub
+	EmailClaim         string // Default: email
+	EmailVerifiedClaim string // Default: email_verified
+	NameClaim          string // Default: name
+
+	// TrustEmail treats every email from this provider as verified,
+	// for IdPs that don't send email_verified (e.g. Microsoft Entra ID, most company IdPs)
+	TrustEmail bool
+}
+
+var oidcProviderID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// loadOIDCProviders reads OIDC_PROVIDERS (comma-separated ids) and the
+// OIDC_{ID}_* variables for each one, e.g. for OIDC_PROVIDERS=keycloak:
+//
+//	OIDC_KEYCLOAK_NAME=Company SSO
+//	OIDC_KEYCLOAK_ISSUER=https://sso.example.com/realms/main
+//	OIDC_KEYCLOAK_CLIENT_ID=...
+//	OIDC_KEYCLOAK_CLIENT_SECRET=...
+//	OIDC_KEYCLOAK_SCOPES=openid,email,profile
+//	OIDC_KEYCLOAK_SUBJECT_CLAIM=sub
+//	OIDC_KEYCLOAK_EMAIL_CLAIM=email
+//	OIDC_KEYCLOAK_EMAIL_VERIFIED_CLAIM=email_verified
+//	OIDC_KEYCLOAK_NAME_CLAIM=name
+//	OIDC_KEYCLOAK_TRUST_EMAIL=false
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+
+	for _, id := range strings.Split(envString("OIDC_PROVIDERS", ""), ",") {
+		id = strings.TrimSpace(strings.ToLower(id))
+		if id == "" {
+			continue
+		}
+
+		if !oidcProviderID.MatchString(id) || id == "google" || id == "github" {
+			slog.Error("invalid oidc provider id", "id", id,
+				"hint", "use lowercase letters, digits and dashes; google and github are reserved")
+			os.Exit(1)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		provider := OIDCProvider{
+			ID:                 id,
+			Name:               envString(prefix+"NAME", id),
+			Issuer:             envRequired(prefix + "ISSUER"), // Must match the issuer in the discovery document exactly
+			ClientID:           envRequired(prefix + "CLIENT_ID"),
+			ClientSecret:       envString(prefix+"CLIENT_SECRET", ""),
+			Scopes:             envList(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+			SubjectClaim:       envString(prefix+"SUBJECT_CLAIM", "sub"),
+			EmailClaim:         envString(prefix+"EMAIL_CLAIM", "email"),
+			EmailVerifiedClaim: envString(prefix+"EMAIL_VERIFIED_CLAIM", "email_verified"),
+			NameClaim:          envString(prefix+"NAME_CLAIM", "name"),
+			TrustEmail:         envBool(prefix+"TRUST_EMAIL", false),
+		}
+
+		for _, existing := range providers {
+			if existing.ID == id {
+				slog.Error("duplicate oidc provider id", "id", id)
+				os.Exit(1)
+			}
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// OIDCProviderByID returns the configured provider or nil
+func (c *Config) OIDCProviderByID(id string) *OIDCProvider {
+	for i := range c.OIDCProviders {
+		if c.OIDCProviders[i].ID == id {
+			return &c.OIDCProviders[i]
+		}
+	}
+	return nil
+}
+
+// sanitizedOIDCProviders keeps what the UI needs (id and name) and drops the secrets
+func sanitizedOIDCProviders(providers []OIDCProvider) []OIDCProvider {
+	var sanitized []OIDCProvider
+	for _, p := range providers {
+		sanitized = append(sanitized, OIDCProvider{
+			ID:   p.ID,
+			Name: p.Name,
+		})
+	}
+	return sanitized
+}
+
+func envList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	return strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+}
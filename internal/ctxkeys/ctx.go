@@ -14,9 +14,14 @@ const (
 	UserKey         contextKey = "user"
 	ProfileKey      contextKey = "profile"
 	SubscriptionKey contextKey = "subscription"
+	SessionKey      contextKey = "session"
 	URLPathKey      contextKey = "url_path"
 	ConfigKey       contextKey = "config"
 	CSRFTokenKey    contextKey = "csrf_token"
+	APITokenKey     contextKey = "api_token"
+	MembershipKey   contextKey = "membership"
+	MembershipsKey  contextKey = "memberships"
+	ImpersonatorKey contextKey = "impersonator"
 )
 
 func User(ctx context.Context) *model.User {
@@ -64,6 +69,15 @@ func WithSubscription(ctx context.Context, subscription *model.Subscription) con
 	return context.WithValue(ctx, SubscriptionKey, subscription)
 }
 
+func Session(ctx context.Context) *model.Session {
+	session, _ := ctx.Value(SessionKey).(*model.Session)
+	return session
+}
+
+func WithSession(ctx context.Context, session *model.Session) context.Context {
+	return context.WithValue(ctx, SessionKey, session)
+}
+
 func CSRFToken(ctx context.Context) string {
 	token, _ := ctx.Value(CSRFTokenKey).(string)
 	return token
@@ -72,3 +86,42 @@ func CSRFToken(ctx context.Context) string {
 func WithCSRFToken(ctx context.Context, token string) context.Context {
 	return context.WithValue(ctx, CSRFTokenKey, token)
 }
+
+func APIToken(ctx context.Context) *model.APIToken {
+	token, _ := ctx.Value(APITokenKey).(*model.APIToken)
+	return token
+}
+
+func WithAPIToken(ctx context.Context, token *model.APIToken) context.Context {
+	return context.WithValue(ctx, APITokenKey, token)
+}
+
+// Membership is the user's membership in the active workspace
+func Membership(ctx context.Context) *model.Membership {
+	membership, _ := ctx.Value(MembershipKey).(*model.Membership)
+	return membership
+}
+
+func WithMembership(ctx context.Context, membership *model.Membership) context.Context {
+	return context.WithValue(ctx, MembershipKey, membership)
+}
+
+// Memberships lists all workspaces of the user, for the workspace switcher
+func Memberships(ctx context.Context) []*model.Membership {
+	memberships, _ := ctx.Value(MembershipsKey).([]*model.Membership)
+	return memberships
+}
+
+func WithMemberships(ctx context.Context, memberships []*model.Membership) context.Context {
+	return context.WithValue(ctx, MembershipsKey, memberships)
+}
+
+// Impersonator is the admin acting as the current user, nil outside impersonation
+func Impersonator(ctx context.Context) *model.User {
+	impersonator, _ := ctx.Value(ImpersonatorKey).(*model.User)
+	return impersonator
+}
+
+func WithImpersonator(ctx context.Context, impersonator *model.User) context.Context {
+	return context.WithValue(ctx, ImpersonatorKey, impersonator)
+}
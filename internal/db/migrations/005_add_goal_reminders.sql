@@ -0,0 +1,22 @@
+-- +goose Up
+-- Goal reminder and weekly digest preferences
+-- Times are evaluated in the user's timezone (IANA name, e.g. Europe/Berlin)
+
+ALTER TABLE profiles ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE profiles ADD COLUMN reminders_enabled BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE profiles ADD COLUMN reminder_hour INTEGER NOT NULL DEFAULT 9;
+ALTER TABLE profiles ADD COLUMN reminder_inactive_days INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE profiles ADD COLUMN digest_enabled BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE profiles ADD COLUMN digest_weekday INTEGER NOT NULL DEFAULT 1; -- 0 = Sunday, 1 = Monday, ...
+ALTER TABLE profiles ADD COLUMN last_reminder_at TIMESTAMP NULL;
+ALTER TABLE profiles ADD COLUMN last_digest_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE profiles DROP COLUMN last_digest_at;
+ALTER TABLE profiles DROP COLUMN last_reminder_at;
+ALTER TABLE profiles DROP COLUMN digest_weekday;
+ALTER TABLE profiles DROP COLUMN digest_enabled;
+ALTER TABLE profiles DROP COLUMN reminder_inactive_days;
+ALTER TABLE profiles DROP COLUMN reminder_hour;
+ALTER TABLE profiles DROP COLUMN reminders_enabled;
+ALTER TABLE profiles DROP COLUMN timezone;
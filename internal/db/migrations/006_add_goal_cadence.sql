@@ -0,0 +1,10 @@
+-- +goose Up
+-- Goals of arbitrary length with an optional cadence
+-- Existing goals keep their 100 steps and have no due dates
+
+ALTER TABLE goals ADD COLUMN target_steps INTEGER NOT NULL DEFAULT 100;
+ALTER TABLE goals ADD COLUMN cadence TEXT NOT NULL DEFAULT 'none'; -- none, daily, weekly
+
+-- +goose Down
+ALTER TABLE goals DROP COLUMN cadence;
+ALTER TABLE goals DROP COLUMN target_steps;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- ============================================================================
+-- API TOKENS TABLE
+-- Personal access tokens for the /api/v1 JSON API
+-- Only the SHA-256 hash is stored, the plain token is shown once on creation
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL, -- First characters of the token, for display
+    scope TEXT NOT NULL DEFAULT 'read', -- read, write
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_api_tokens_user_id;
+DROP TABLE IF EXISTS api_tokens;
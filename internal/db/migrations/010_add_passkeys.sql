@@ -0,0 +1,27 @@
+-- +goose Up
+-- ============================================================================
+-- PASSKEYS TABLE
+-- WebAuthn credentials, a user can register several authenticators
+-- Binary values (credential id, public key, aaguid) are stored base64url encoded
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS passkeys (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    credential_id TEXT NOT NULL UNIQUE,
+    public_key TEXT NOT NULL, -- COSE encoded
+    attestation_type TEXT NOT NULL DEFAULT '',
+    aaguid TEXT NOT NULL DEFAULT '',
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    flags INTEGER NOT NULL DEFAULT 0, -- Authenticator flags from registration (backup eligible, ...)
+    transports TEXT NOT NULL DEFAULT '', -- Comma-separated, e.g. "internal,hybrid"
+    last_used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_passkeys_user_id;
+DROP TABLE IF EXISTS passkeys;
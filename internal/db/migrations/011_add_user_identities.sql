@@ -0,0 +1,24 @@
+-- +goose Up
+-- ============================================================================
+-- USER IDENTITIES TABLE
+-- OAuth accounts linked to a user, keyed by the provider's stable user id
+-- so a changed email at the provider still resolves to the same account
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS user_identities (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    provider TEXT NOT NULL, -- google, github
+    provider_user_id TEXT NOT NULL, -- Subject at the provider (Google "id", GitHub numeric id)
+    email TEXT NOT NULL DEFAULT '', -- Last email reported by the provider, for display
+    last_login_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_user_id),
+    UNIQUE (user_id, provider),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP TABLE IF EXISTS user_identities;
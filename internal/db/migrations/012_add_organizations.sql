@@ -0,0 +1,166 @@
+-- +goose Up
+-- ============================================================================
+-- ORGANIZATIONS TABLE
+-- Workspaces that own goals and subscriptions
+-- Every user has a personal workspace, created at signup
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS organizations (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    personal BOOLEAN NOT NULL DEFAULT FALSE, -- Personal workspaces have one member and can't be shared
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================================================
+-- MEMBERSHIPS TABLE
+-- A user's role in an organization: owner, admin or member
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS memberships (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member', -- owner, admin, member
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, user_id),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_memberships_user_id ON memberships(user_id);
+
+-- ============================================================================
+-- INVITATIONS TABLE
+-- Pending email invitations, the link token lives in the tokens table
+-- (type org_invitation, owned by the inviting user)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS invitations (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    token_id TEXT NOT NULL,
+    invited_by TEXT NOT NULL,
+    accepted_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (token_id) REFERENCES tokens(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_organization_id ON invitations(organization_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(email);
+
+-- Personal workspace for every existing user, sharing the user's id
+-- so subscriptions and checkout metadata keyed by user id still resolve
+INSERT INTO organizations (id, name, personal, created_at, updated_at)
+SELECT id, 'Personal', TRUE, created_at, created_at FROM users;
+
+INSERT INTO memberships (id, organization_id, user_id, role, created_at)
+SELECT id, id, id, 'owner', created_at FROM users;
+
+-- ============================================================================
+-- GOALS
+-- Owned by a workspace, user_id is the member who created the goal
+-- Nullable only because SQLite can't add a NOT NULL reference, always set by the app
+-- ============================================================================
+ALTER TABLE goals ADD COLUMN organization_id TEXT REFERENCES organizations(id) ON DELETE CASCADE;
+
+UPDATE goals SET organization_id = user_id;
+
+CREATE INDEX IF NOT EXISTS idx_goals_organization_id ON goals(organization_id);
+
+-- ============================================================================
+-- SUBSCRIPTIONS
+-- One per workspace instead of one per user
+-- Recreated because the UNIQUE user_id constraint can't be dropped in SQLite
+-- ============================================================================
+CREATE TABLE subscriptions_new (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT UNIQUE NOT NULL,
+    plan_id TEXT NOT NULL DEFAULT 'free',
+    status TEXT NOT NULL DEFAULT 'active',
+    provider TEXT NOT NULL DEFAULT 'polar',
+    provider_customer_id TEXT,
+    provider_subscription_id TEXT,
+    current_period_end TIMESTAMP,
+    amount INTEGER,
+    currency TEXT,
+    interval TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+INSERT INTO subscriptions_new (
+    id, organization_id, plan_id, status, provider,
+    provider_customer_id, provider_subscription_id,
+    current_period_end, amount, currency, interval,
+    created_at, updated_at
+)
+SELECT
+    id, user_id, plan_id, status, provider,
+    provider_customer_id, provider_subscription_id,
+    current_period_end, amount, currency, interval,
+    created_at, updated_at
+FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_status ON subscriptions(status);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_provider ON subscriptions(provider);
+
+-- +goose Down
+-- Shared workspaces and their goals and subscriptions are dropped,
+-- personal ones go back to their owner
+CREATE TABLE subscriptions_old (
+    id TEXT PRIMARY KEY,
+    user_id TEXT UNIQUE NOT NULL,
+    plan_id TEXT NOT NULL DEFAULT 'free',
+    status TEXT NOT NULL DEFAULT 'active',
+    provider TEXT NOT NULL DEFAULT 'polar',
+    provider_customer_id TEXT,
+    provider_subscription_id TEXT,
+    current_period_end TIMESTAMP,
+    amount INTEGER,
+    currency TEXT,
+    interval TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO subscriptions_old (
+    id, user_id, plan_id, status, provider,
+    provider_customer_id, provider_subscription_id,
+    current_period_end, amount, currency, interval,
+    created_at, updated_at
+)
+SELECT
+    s.id, m.user_id, s.plan_id, s.status, s.provider,
+    s.provider_customer_id, s.provider_subscription_id,
+    s.current_period_end, s.amount, s.currency, s.interval,
+    s.created_at, s.updated_at
+FROM subscriptions s
+JOIN organizations o ON o.id = s.organization_id AND o.personal = TRUE
+JOIN memberships m ON m.organization_id = o.id AND m.role = 'owner';
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_old RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_status ON subscriptions(status);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_provider ON subscriptions(provider);
+
+DELETE FROM goals WHERE organization_id IN (SELECT id FROM organizations WHERE personal = FALSE);
+DROP INDEX IF EXISTS idx_goals_organization_id;
+ALTER TABLE goals DROP COLUMN organization_id;
+
+DROP INDEX IF EXISTS idx_invitations_email;
+DROP INDEX IF EXISTS idx_invitations_organization_id;
+DROP TABLE IF EXISTS invitations;
+
+DROP INDEX IF EXISTS idx_memberships_user_id;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Paid plans are billed per seat, seats is the quantity bought from the payment provider
+-- Existing subscriptions were bought with a quantity of one
+
+ALTER TABLE subscriptions ADD COLUMN seats INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN seats;
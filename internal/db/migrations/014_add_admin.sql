@@ -0,0 +1,30 @@
+-- +goose Up
+-- Operators with access to the /admin console
+-- Granted from ADMIN_EMAILS on startup or by another admin
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================================================
+-- ADMIN AUDIT LOGS TABLE
+-- Every action taken in the admin console
+-- No foreign keys: entries outlive the admin and the user they are about
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id TEXT PRIMARY KEY,
+    admin_id TEXT NOT NULL,
+    admin_email TEXT NOT NULL,
+    action TEXT NOT NULL, -- e.g. plan_override, force_logout, user_delete
+    target_type TEXT NOT NULL, -- user or organization
+    target_id TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_target ON admin_audit_logs(target_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_admin_audit_logs_target;
+DROP INDEX IF EXISTS idx_admin_audit_logs_created_at;
+DROP TABLE IF EXISTS admin_audit_logs;
+ALTER TABLE users DROP COLUMN is_admin;
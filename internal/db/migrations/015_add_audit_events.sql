@@ -0,0 +1,25 @@
+-- +goose Up
+-- ============================================================================
+-- AUDIT EVENTS TABLE
+-- Security-relevant account events: sign-ins, credential and email changes, deletion
+-- No foreign keys: events outlive the account, the email is copied for that reason
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS audit_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    actor_id TEXT NOT NULL DEFAULT '', -- who did it, differs from user_id for admin actions
+    event TEXT NOT NULL, -- e.g. login, password_set, email_changed, account_deleted
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '{}', -- JSON object with event details
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_audit_events_created_at;
+DROP INDEX IF EXISTS idx_audit_events_user_id;
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- ============================================================================
+-- KNOWN DEVICES TABLE
+-- Devices a user signed in from, a sign-in from an unknown one sends an alert
+-- The fingerprint is the browser family plus the IP prefix, see model.DeviceFingerprint
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS known_devices (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '', -- Last full user agent, for display
+    ip_address TEXT NOT NULL DEFAULT '', -- Last IP address, for display
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, fingerprint),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS known_devices;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- ============================================================================
+-- LOGIN ATTEMPTS TABLE
+-- Failed password sign-ins per normalized email, shared by all app instances
+-- Tracked for unknown emails too, so lockouts don't reveal which accounts exist
+-- A successful sign-in or an admin unlock deletes the row
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS login_attempts (
+    email TEXT PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempts;
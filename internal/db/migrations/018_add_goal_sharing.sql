@@ -0,0 +1,17 @@
+-- +goose Up
+-- Goals can be shared read-only at /g/{share_slug}, NULL while not shared
+-- Revoking clears the slug, sharing again creates a new one so old links stay dead
+-- Shared pages are noindex unless share_indexable is set
+-- Notes only appear on the shared page when note_shared is set on the entry
+
+ALTER TABLE goals ADD COLUMN share_slug TEXT NULL;
+ALTER TABLE goals ADD COLUMN share_indexable BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE goal_entries ADD COLUMN note_shared BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_goals_share_slug ON goals(share_slug) WHERE share_slug IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goals_share_slug;
+ALTER TABLE goal_entries DROP COLUMN note_shared;
+ALTER TABLE goals DROP COLUMN share_indexable;
+ALTER TABLE goals DROP COLUMN share_slug;
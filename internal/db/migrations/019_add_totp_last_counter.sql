@@ -0,0 +1,7 @@
+-- +goose Up
+-- Time step of the last accepted TOTP code, a code is only accepted once
+-- Codes of this step or earlier ones are rejected, even within the clock skew window
+ALTER TABLE users ADD COLUMN totp_last_counter BIGINT NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN totp_last_counter;
@@ -7,7 +7,10 @@ import (
 	"strings"
 
 	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/model"
 	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/totp"
 	"github.com/templui/goilerplate/internal/ui"
 	"github.com/templui/goilerplate/internal/ui/components/toast"
 	"github.com/templui/goilerplate/internal/ui/layouts"
@@ -16,16 +19,18 @@ import (
 )
 
 type AccountHandler struct {
-	authService *service.AuthService
-	userService *service.UserService
-	fileService *service.FileService
+	authService  *service.AuthService
+	userService  *service.UserService
+	fileService  *service.FileService
+	auditService *service.AuditService
 }
 
-func NewAccountHandler(authService *service.AuthService, userService *service.UserService, fileService *service.FileService) *AccountHandler {
+func NewAccountHandler(authService *service.AuthService, userService *service.UserService, fileService *service.FileService, auditService *service.AuditService) *AccountHandler {
 	return &AccountHandler{
-		authService: authService,
-		userService: userService,
-		fileService: fileService,
+		authService:  authService,
+		userService:  userService,
+		fileService:  fileService,
+		auditService: auditService,
 	}
 }
 
@@ -58,7 +63,7 @@ func (h *AccountHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.authService.RequestEmailChange(user.ID, email)
+	err = h.authService.RequestEmailChange(user.ID, email, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("email change request failed", "error", err, "user_id", user.ID, "new_email", email)
 
@@ -130,7 +135,7 @@ func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.userService.UpdatePassword(user.ID, currentPassword, newPassword)
+	err = h.userService.UpdatePassword(user.ID, currentPassword, newPassword, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("password update failed", "error", err, "user_id", user.ID)
 
@@ -150,6 +155,14 @@ func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
 	}
 
 	slog.Info("password updated", "user_id", user.ID)
+
+	// Sign out other devices that may know the old password
+	session := ctxkeys.Session(r.Context())
+	err = h.authService.RevokeOtherSessions(user.ID, session.ID)
+	if err != nil {
+		slog.Warn("failed to revoke sessions after password change", "error", err, "user_id", user.ID)
+	}
+
 	ui.RenderOOB(w, r, toast.Toast(toast.Props{
 		Title:       "Success",
 		Description: "Password updated successfully",
@@ -158,6 +171,7 @@ func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
 		Dismissible: true,
 	}), "beforeend:#toast-container")
 	ui.RenderFragment(w, r, pages.SettingsPasswordSection(), "settings-password-form")
+	h.renderSessions(w, r)
 }
 
 func (h *AccountHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
@@ -200,7 +214,7 @@ func (h *AccountHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.authService.SetPassword(user.ID, newPassword)
+	err = h.authService.SetPassword(user.ID, newPassword, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("set password failed", "error", err, "user_id", user.ID)
 
@@ -246,7 +260,7 @@ func (h *AccountHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
 func (h *AccountHandler) RemovePassword(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
 
-	err := h.authService.RemovePassword(user.ID)
+	err := h.authService.RemovePassword(user.ID, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("remove password failed", "error", err, "user_id", user.ID)
 
@@ -280,6 +294,12 @@ func (h *AccountHandler) RemovePassword(w http.ResponseWriter, r *http.Request)
 
 	slog.Info("password removed", "user_id", user.ID)
 
+	session := ctxkeys.Session(r.Context())
+	err = h.authService.RevokeOtherSessions(user.ID, session.ID)
+	if err != nil {
+		slog.Warn("failed to revoke sessions after password removal", "error", err, "user_id", user.ID)
+	}
+
 	ui.RenderOOB(w, r, toast.Toast(toast.Props{
 		Title:       "Success",
 		Description: "Password removed. You can now only sign in with magic links.",
@@ -288,6 +308,7 @@ func (h *AccountHandler) RemovePassword(w http.ResponseWriter, r *http.Request)
 		Dismissible: true,
 	}), "beforeend:#toast-container")
 	ui.RenderFragment(w, r.WithContext(ctx), pages.SettingsPasswordSection(), "settings-password-form")
+	h.renderSessions(w, r)
 }
 
 func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
@@ -306,7 +327,7 @@ func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.userService.DeleteAccount(user.ID)
+	err := h.userService.DeleteAccount(user.ID, middleware.AuditContext(r))
 	if err != nil {
 		// Handle active subscription error with specific message
 		if errors.Is(err, service.ErrActiveSubscription) {
@@ -321,6 +342,18 @@ func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, service.ErrSoleWorkspaceOwner) {
+			slog.Warn("account deletion failed: sole workspace owner", "error", err, "user_id", user.ID)
+			ui.RenderOOB(w, r, toast.Toast(toast.Props{
+				Title:       "Workspace Owner",
+				Description: "You are the only owner of a workspace with other members. Make someone else an owner or delete the workspace first.",
+				Variant:     toast.VariantError,
+				Icon:        true,
+				Dismissible: true,
+			}), "beforeend:#toast-container")
+			return
+		}
+
 		// Unexpected error - log as error for Sentry
 		slog.Error("account deletion failed", "error", err, "user_id", user.ID)
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
@@ -465,3 +498,284 @@ func (h *AccountHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
 		ui.Render(w, r, layouts.AppSidebarDropdown(updatedUser, profile))
 	}
 }
+
+func (h *AccountHandler) BeginTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	secret, uri, err := h.authService.BeginTwoFactorSetup(user.ID)
+	if err != nil {
+		slog.Warn("two-factor setup failed", "error", err, "user_id", user.ID)
+
+		errMsg := "Failed to start two-factor setup"
+		if errors.Is(err, service.ErrTwoFactorAlreadyEnabled) {
+			errMsg = "Two-factor authentication is already enabled"
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	qrCode, err := totp.QRCodeDataURL(uri)
+	if err != nil {
+		slog.Error("failed to generate two-factor qr code", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to start two-factor setup",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.Render(w, r, pages.SettingsTwoFactorSetup(secret, qrCode))
+}
+
+func (h *AccountHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	codes, err := h.authService.EnableTwoFactor(user.ID, r.FormValue("code"))
+	if err != nil {
+		slog.Warn("enable two-factor failed", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: twoFactorErrorMessage(err),
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	slog.Info("two-factor enabled", "user_id", user.ID)
+	h.auditService.Record(user, model.AuditEventTwoFactorEnabled, middleware.AuditContext(r), nil)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Two-factor authentication enabled",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	ui.Render(w, r, pages.SettingsTwoFactorRecoveryCodes(codes))
+}
+
+func (h *AccountHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	codes, err := h.authService.RegenerateRecoveryCodes(user.ID, r.FormValue("code"), middleware.AuditContext(r))
+	if err != nil {
+		slog.Warn("regenerate recovery codes failed", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: twoFactorErrorMessage(err),
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	h.auditService.Record(user, model.AuditEventRecoveryCodesRegenerated, middleware.AuditContext(r), nil)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "New recovery codes generated. Old codes no longer work.",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	ui.Render(w, r, pages.SettingsTwoFactorRecoveryCodes(codes))
+}
+
+func (h *AccountHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	err := h.authService.DisableTwoFactor(user.ID, r.FormValue("code"), middleware.AuditContext(r))
+	if err != nil {
+		slog.Warn("disable two-factor failed", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: twoFactorErrorMessage(err),
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	// Reload user from DB to get cleared totp_enabled_at
+	updatedUser, err := h.userService.ByID(user.ID)
+	if err != nil {
+		slog.Error("failed to reload user after disabling two-factor", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Two-factor disabled but failed to refresh. Please reload the page.",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ctx := ctxkeys.WithUser(r.Context(), updatedUser)
+
+	slog.Info("two-factor disabled", "user_id", user.ID)
+	h.auditService.Record(user, model.AuditEventTwoFactorDisabled, middleware.AuditContext(r), nil)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Two-factor authentication disabled",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	ui.RenderFragment(w, r.WithContext(ctx), pages.SettingsTwoFactorSection(), "settings-two-factor")
+}
+
+// twoFactorErrorMessage maps two-factor service errors to user-facing messages
+func twoFactorErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		return "Invalid authentication code"
+	case errors.Is(err, service.ErrTwoFactorSetupRequired):
+		return "Please start two-factor setup again"
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		return "Two-factor authentication is already enabled"
+	case errors.Is(err, service.ErrTwoFactorNotEnabled):
+		return "Two-factor authentication is not enabled"
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return "Too many invalid codes. Please wait a moment and try again."
+	case errors.Is(err, service.ErrTwoFactorLocked):
+		return "Too many invalid codes. Please try again later."
+	default:
+		return "Something went wrong. Please try again."
+	}
+}
+
+func (h *AccountHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	session := ctxkeys.Session(r.Context())
+	sessionID := r.PathValue("id")
+
+	if sessionID == session.ID {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Use sign out to end your current session",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	err := h.authService.RevokeSession(user.ID, sessionID)
+	if err != nil {
+		slog.Warn("revoke session failed", "error", err, "user_id", user.ID, "session_id", sessionID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to sign out session",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	h.auditService.Record(user, model.AuditEventSessionRevoked, middleware.AuditContext(r), map[string]string{"session_id": sessionID})
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Session signed out",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderSessions(w, r)
+}
+
+func (h *AccountHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	session := ctxkeys.Session(r.Context())
+
+	err := h.authService.RevokeOtherSessions(user.ID, session.ID)
+	if err != nil {
+		slog.Error("revoke other sessions failed", "error", err, "user_id", user.ID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to sign out other sessions",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	h.auditService.Record(user, model.AuditEventSessionRevoked, middleware.AuditContext(r), map[string]string{"scope": "other_sessions"})
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Signed out of all other sessions",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderSessions(w, r)
+}
+
+// UnlinkIdentity disconnects an OAuth provider from the account
+func (h *AccountHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	provider := r.PathValue("provider")
+
+	err := h.authService.UnlinkIdentity(user.ID, provider, middleware.AuditContext(r))
+	if err != nil {
+		errMsg := "Failed to disconnect account"
+		switch {
+		case errors.Is(err, service.ErrLastLoginMethod),
+			errors.Is(err, service.ErrIdentityNotLinked):
+			errMsg = err.Error()
+		default:
+			slog.Error("unlink identity failed", "error", err, "user_id", user.ID, "provider", provider)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: identityProviderName(r, provider) + " disconnected",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+
+	identities, err := h.authService.Identities(user.ID)
+	if err != nil {
+		slog.Error("failed to load identities", "error", err, "user_id", user.ID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.SettingsIdentitiesSection(identities), "settings-identities")
+}
+
+// renderSessions re-renders the active sessions list after a revocation
+func (h *AccountHandler) renderSessions(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	sessions, err := h.authService.Sessions(user.ID)
+	if err != nil {
+		slog.Error("failed to load sessions", "error", err, "user_id", user.ID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.SettingsSessionsSection(sessions), "settings-sessions")
+}
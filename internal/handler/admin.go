@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// AdminHandler serves the /admin console, routes are wrapped in RequireAdmin
+type AdminHandler struct {
+	adminService *service.AdminService
+	auditService *service.AuditService
+}
+
+func NewAdminHandler(adminService *service.AdminService, auditService *service.AuditService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		auditService: auditService,
+	}
+}
+
+func (h *AdminHandler) UsersPage(w http.ResponseWriter, r *http.Request) {
+	search := r.URL.Query().Get("q")
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+
+	users, total, err := h.adminService.Users(search, page)
+	if err != nil {
+		slog.Error("failed to list users", "error", err)
+		http.Error(w, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.AdminUsers(users, search, page, total))
+}
+
+func (h *AdminHandler) UserPage(w http.ResponseWriter, r *http.Request) {
+	detail, err := h.adminService.UserDetail(r.PathValue("id"))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load user", "error", err, "user_id", r.PathValue("id"))
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.AdminUser(detail))
+}
+
+func (h *AdminHandler) AuditLogPage(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+
+	logs, total, err := h.adminService.AuditLogs(page)
+	if err != nil {
+		slog.Error("failed to list audit logs", "error", err)
+		http.Error(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.AdminAuditLog(logs, page, total))
+}
+
+// SecurityEventsPage searches the account audit events of all users
+func (h *AdminHandler) SecurityEventsPage(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+
+	events, total, err := h.auditService.Search(auditEventFilter(r), page)
+	if err != nil {
+		slog.Error("failed to search audit events", "error", err)
+		http.Error(w, "Failed to load security events", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.AdminSecurityEvents(events, r.URL.Query(), page, total))
+}
+
+// ExportSecurityEvents downloads the events matching the page filters as CSV
+func (h *AdminHandler) ExportSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+
+	events, err := h.auditService.Export(auditEventFilter(r))
+	if err != nil {
+		slog.Error("failed to export audit events", "error", err, "admin_id", admin.ID)
+		http.Error(w, "Failed to export security events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=security-events.csv")
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"created_at", "user_id", "email", "actor_id", "event", "ip_address", "user_agent", "metadata"})
+	for _, event := range events {
+		row := []string{
+			event.CreatedAt.UTC().Format(time.RFC3339),
+			event.UserID,
+			event.Email,
+			event.ActorID,
+			event.Event,
+			event.IPAddress,
+			event.UserAgent,
+			event.Metadata,
+		}
+		for i, cell := range row {
+			row[i] = csvSafeCell(cell)
+		}
+		_ = writer.Write(row)
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		slog.Error("failed to write audit events csv", "error", err, "admin_id", admin.ID)
+	}
+}
+
+// csvSafeCell stops spreadsheets from running user controlled values as formulas
+// Emails, user agents and metadata come from users, =HYPERLINK(...) would become a link.
+func csvSafeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// auditEventFilter reads the security event filters from the query
+// Dates are whole days, to includes the given day.
+func auditEventFilter(r *http.Request) model.AuditEventFilter {
+	query := r.URL.Query()
+	filter := model.AuditEventFilter{
+		User:  query.Get("user"),
+		Event: query.Get("event"),
+	}
+
+	from, err := time.Parse(time.DateOnly, query.Get("from"))
+	if err == nil {
+		filter.From = from
+	}
+	to, err := time.Parse(time.DateOnly, query.Get("to"))
+	if err == nil {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter
+}
+
+func (h *AdminHandler) OverridePlan(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+	seats, _ := strconv.Atoi(r.FormValue("seats"))
+
+	err := h.adminService.OverridePlan(admin, middleware.ClientIP(r), userID, r.FormValue("organization_id"), r.FormValue("plan_id"), seats)
+	if err != nil {
+		errMsg := "Failed to change plan"
+		if errors.Is(err, service.ErrInvalidPlan) || errors.Is(err, service.ErrAdminWorkspaceMissing) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to override plan", "error", err, "user_id", userID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/admin/users/"+userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// ForceLogout signs the user out on every device
+func (h *AdminHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.ForceLogout(admin, middleware.ClientIP(r), userID)
+	if err != nil {
+		slog.Error("failed to force logout", "error", err, "user_id", userID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to sign the user out",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/admin/users/"+userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// UnlockLogin lifts a password sign-in lockout
+func (h *AdminHandler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.UnlockLogin(admin, middleware.ClientIP(r), userID)
+	if err != nil {
+		slog.Error("failed to unlock login", "error", err, "user_id", userID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to unlock sign-in",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/admin/users/"+userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AdminHandler) SendMagicLink(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.SendMagicLink(admin, middleware.ClientIP(r), userID)
+	if err != nil {
+		slog.Error("failed to send magic link", "error", err, "user_id", userID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to send magic link",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Magic link sent",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+// ResendEmailChange sends the verification link for a pending email change again
+func (h *AdminHandler) ResendEmailChange(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.ResendEmailChange(admin, middleware.ClientIP(r), userID)
+	if err != nil {
+		errMsg := "Failed to resend verification"
+		if errors.Is(err, service.ErrNoPendingEmailChange) || errors.Is(err, service.ErrEmailAlreadyExists) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to resend email change", "error", err, "user_id", userID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Verification link sent to the new address",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+// SetAdmin grants or revokes admin access, is_admin is "true" or "false"
+func (h *AdminHandler) SetAdmin(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.SetAdmin(admin, middleware.ClientIP(r), userID, r.FormValue("is_admin") == "true")
+	if err != nil {
+		errMsg := "Failed to update admin access"
+		if errors.Is(err, service.ErrAdminSelf) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to set admin", "error", err, "user_id", userID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/admin/users/"+userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Impersonate signs the admin in as the user until StopImpersonation
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	session := ctxkeys.Session(r.Context())
+	userID := r.PathValue("id")
+
+	err := h.adminService.StartImpersonation(w, admin, session, middleware.ClientIP(r), userID)
+	if err != nil {
+		errMsg := "Failed to impersonate user"
+		if errors.Is(err, service.ErrAdminSelf) || errors.Is(err, service.ErrImpersonateAdmin) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to start impersonation", "error", err, "user_id", userID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	// The admin's active workspace means nothing for the user
+	clearWorkspaceCookie(w)
+	w.Header().Set("HX-Redirect", "/app/dashboard")
+	w.WriteHeader(http.StatusOK)
+}
+
+// StopImpersonation restores the admin's own session, called from the app banner
+func (h *AdminHandler) StopImpersonation(w http.ResponseWriter, r *http.Request) {
+	impersonator := ctxkeys.Impersonator(r.Context())
+	if impersonator == nil {
+		http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+		return
+	}
+	user := ctxkeys.User(r.Context())
+
+	err := h.adminService.StopImpersonation(w, impersonator, ctxkeys.Session(r.Context()), middleware.ClientIP(r), user)
+	if err != nil {
+		slog.Error("failed to stop impersonation", "error", err, "admin_id", impersonator.ID, "user_id", user.ID)
+		http.Error(w, "Failed to stop impersonation", http.StatusInternalServerError)
+		return
+	}
+
+	clearWorkspaceCookie(w)
+	redirect := "/admin/users/" + user.ID
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", redirect)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// DeleteUser deletes the account, confirmation must be the user's email
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	admin := ctxkeys.User(r.Context())
+	userID := r.PathValue("id")
+
+	user, err := h.adminService.User(userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get user", "error", err, "user_id", userID)
+		http.Error(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("confirmation") != user.Email {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Type the user's email to confirm",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	err = h.adminService.DeleteUser(admin, middleware.ClientIP(r), userID)
+	if err != nil {
+		errMsg := "Failed to delete user"
+		if errors.Is(err, service.ErrAdminSelf) ||
+			errors.Is(err, service.ErrActiveSubscription) ||
+			errors.Is(err, service.ErrSoleWorkspaceOwner) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to delete user", "error", err, "user_id", userID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/admin/users")
+	w.WriteHeader(http.StatusOK)
+}
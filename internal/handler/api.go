@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/validation"
+)
+
+// apiMaxBodySize caps JSON request bodies
+const apiMaxBodySize = 1 << 20
+
+// APIHandler serves the versioned JSON API (/api/v1)
+// Requests are authenticated by middleware.RequireAPIToken
+type APIHandler struct {
+	goalService         *service.GoalService
+	profileService      *service.ProfileService
+	subscriptionService *service.SubscriptionService
+}
+
+func NewAPIHandler(goalService *service.GoalService, profileService *service.ProfileService, subscriptionService *service.SubscriptionService) *APIHandler {
+	return &APIHandler{
+		goalService:         goalService,
+		profileService:      profileService,
+		subscriptionService: subscriptionService,
+	}
+}
+
+// ============================================================================
+// Response types (models have no json tags, the API shape is defined here)
+// ============================================================================
+
+type apiUser struct {
+	ID               string    `json:"id"`
+	Email            string    `json:"email"`
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type apiProfile struct {
+	Name                 string `json:"name"`
+	Timezone             string `json:"timezone"`
+	RemindersEnabled     bool   `json:"reminders_enabled"`
+	ReminderHour         int    `json:"reminder_hour"`
+	ReminderInactiveDays int    `json:"reminder_inactive_days"`
+	DigestEnabled        bool   `json:"digest_enabled"`
+	DigestWeekday        int    `json:"digest_weekday"`
+}
+
+type apiSubscription struct {
+	Plan             string     `json:"plan"`
+	Status           string     `json:"status"`
+	Interval         *string    `json:"interval"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end"`
+	GoalLimit        int        `json:"goal_limit"` // -1 for unlimited
+}
+
+type apiGoal struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Status      string         `json:"status"`
+	CurrentStep int            `json:"current_step"`
+	TargetSteps int            `json:"target_steps"`
+	Cadence     string         `json:"cadence"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Entries     []apiGoalEntry `json:"entries,omitempty"`
+}
+
+type apiGoalEntry struct {
+	Step        int        `json:"step"`
+	Completed   bool       `json:"completed"`
+	Note        string     `json:"note"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+func newAPIUser(user *model.User) apiUser {
+	return apiUser{
+		ID:               user.ID,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.HasTwoFactor(),
+		CreatedAt:        user.CreatedAt,
+	}
+}
+
+func newAPIProfile(profile *model.Profile) apiProfile {
+	return apiProfile{
+		Name:                 profile.Name,
+		Timezone:             profile.Timezone,
+		RemindersEnabled:     profile.RemindersEnabled,
+		ReminderHour:         profile.ReminderHour,
+		ReminderInactiveDays: profile.ReminderInactiveDays,
+		DigestEnabled:        profile.DigestEnabled,
+		DigestWeekday:        profile.DigestWeekday,
+	}
+}
+
+func newAPISubscription(subscription *model.Subscription) apiSubscription {
+	return apiSubscription{
+		Plan:             subscription.PlanID,
+		Status:           subscription.Status,
+		Interval:         subscription.Interval,
+		CurrentPeriodEnd: subscription.CurrentPeriodEnd,
+		GoalLimit:        subscription.GetGoalLimit(),
+	}
+}
+
+func newAPIGoal(goal *model.Goal, entries []*model.GoalEntry) apiGoal {
+	result := apiGoal{
+		ID:          goal.ID,
+		Title:       goal.Title,
+		Description: goal.Description,
+		Status:      goal.Status,
+		CurrentStep: goal.CurrentStep,
+		TargetSteps: goal.TargetSteps,
+		Cadence:     goal.Cadence,
+		CreatedAt:   goal.CreatedAt,
+		UpdatedAt:   goal.UpdatedAt,
+	}
+	for _, entry := range entries {
+		result.Entries = append(result.Entries, apiGoalEntry{
+			Step:        entry.Step,
+			Completed:   entry.Completed,
+			Note:        entry.Note,
+			CompletedAt: entry.CompletedAt,
+		})
+	}
+	return result
+}
+
+// ============================================================================
+// Account
+// ============================================================================
+
+// Me returns the authenticated user with profile and subscription
+func (h *APIHandler) Me(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"user":         newAPIUser(ctxkeys.User(r.Context())),
+		"profile":      newAPIProfile(ctxkeys.Profile(r.Context())),
+		"subscription": newAPISubscription(ctxkeys.Subscription(r.Context())),
+	})
+}
+
+func (h *APIHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, newAPIProfile(ctxkeys.Profile(r.Context())))
+}
+
+// UpdateProfile applies a partial update, omitted fields keep their current value
+func (h *APIHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	profile := ctxkeys.Profile(r.Context())
+
+	var body struct {
+		Name                 *string `json:"name"`
+		Timezone             *string `json:"timezone"`
+		RemindersEnabled     *bool   `json:"reminders_enabled"`
+		ReminderHour         *int    `json:"reminder_hour"`
+		ReminderInactiveDays *int    `json:"reminder_inactive_days"`
+		DigestEnabled        *bool   `json:"digest_enabled"`
+		DigestWeekday        *int    `json:"digest_weekday"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	if body.Name != nil {
+		err := validation.ValidateName(*body.Name)
+		if err != nil {
+			writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		err = h.profileService.UpdateName(user.ID, *body.Name)
+		if err != nil {
+			slog.Error("failed to update name", "error", err, "user_id", user.ID)
+			writeAPIError(w, http.StatusInternalServerError, "failed to update profile")
+			return
+		}
+	}
+
+	if body.Timezone != nil || body.RemindersEnabled != nil || body.ReminderHour != nil ||
+		body.ReminderInactiveDays != nil || body.DigestEnabled != nil || body.DigestWeekday != nil {
+		settings := service.NotificationSettings{
+			Timezone:             valueOr(body.Timezone, profile.Timezone),
+			RemindersEnabled:     valueOr(body.RemindersEnabled, profile.RemindersEnabled),
+			ReminderHour:         valueOr(body.ReminderHour, profile.ReminderHour),
+			ReminderInactiveDays: valueOr(body.ReminderInactiveDays, profile.ReminderInactiveDays),
+			DigestEnabled:        valueOr(body.DigestEnabled, profile.DigestEnabled),
+			DigestWeekday:        valueOr(body.DigestWeekday, profile.DigestWeekday),
+		}
+
+		err := h.profileService.UpdateNotificationSettings(user.ID, settings)
+		if errors.Is(err, service.ErrInvalidTimezone) || errors.Is(err, service.ErrInvalidReminderSettings) {
+			writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if err != nil {
+			slog.Error("failed to update notification settings", "error", err, "user_id", user.ID)
+			writeAPIError(w, http.StatusInternalServerError, "failed to update profile")
+			return
+		}
+	}
+
+	updated, err := h.profileService.ByUserID(user.ID)
+	if err != nil {
+		slog.Error("failed to reload profile", "error", err, "user_id", user.ID)
+		writeAPIError(w, http.StatusInternalServerError, "failed to load profile")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIProfile(updated))
+}
+
+func (h *APIHandler) Subscription(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, newAPISubscription(ctxkeys.Subscription(r.Context())))
+}
+
+// NotFound answers unknown /api/ paths with JSON instead of the HTML 404 page
+func (h *APIHandler) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, http.StatusNotFound, "not found")
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		slog.Error("failed to encode json response", "error", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// decodeJSON parses a JSON request body, writing a 400 response on failure
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeAPIError(w, http.StatusBadRequest, "invalid json body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func valueOr[T any](value *T, fallback T) T {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/service"
+)
+
+func (h *APIHandler) ListGoals(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = repository.GoalSortRecent
+	}
+
+	goals, err := h.goalService.Goals(membership.OrganizationID, sortBy)
+	if err != nil {
+		slog.Error("failed to get goals", "error", err, "user_id", user.ID)
+		writeAPIError(w, http.StatusInternalServerError, "failed to load goals")
+		return
+	}
+
+	result := make([]apiGoal, 0, len(goals))
+	for _, goal := range goals {
+		result = append(result, newAPIGoal(goal, nil))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"goals": result})
+}
+
+func (h *APIHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	var body struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		TargetSteps *int   `json:"target_steps"`
+		Cadence     string `json:"cadence"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	body.Title = strings.TrimSpace(body.Title)
+	if body.Title == "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+
+	if body.Cadence == "" {
+		body.Cadence = model.GoalCadenceNone
+	}
+
+	goal, err := h.goalService.Create(membership.OrganizationID, user.ID, body.Title, body.Description, valueOr(body.TargetSteps, model.DefaultGoalSteps), body.Cadence)
+	if errors.Is(err, service.ErrInvalidGoalSteps) || errors.Is(err, service.ErrInvalidGoalCadence) {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrGoalLimitReached) {
+		writeAPIError(w, http.StatusPaymentRequired, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create goal", "error", err, "user_id", user.ID)
+		writeAPIError(w, http.StatusInternalServerError, "failed to create goal")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAPIGoal(goal, nil))
+}
+
+// GetGoal returns a goal including all of its step entries
+func (h *APIHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIGoal(goal, entries))
+}
+
+// UpdateGoal applies a partial update, omitted fields keep their current value
+// Status follows the steps and can't be set directly
+func (h *APIHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	var body struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		Cadence     *string `json:"cadence"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	title := strings.TrimSpace(valueOr(body.Title, goal.Title))
+	if title == "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+
+	err = h.goalService.Update(membership.OrganizationID, goalID, title, valueOr(body.Description, goal.Description), goal.Status, valueOr(body.Cadence, goal.Cadence))
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	h.GetGoal(w, r)
+}
+
+func (h *APIHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	err := h.goalService.Delete(membership.OrganizationID, goalID)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) CompleteEntry(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	step, ok := apiStep(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.goalService.CompleteEntry(membership.OrganizationID, user.ID, goalID, step)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	h.GetGoal(w, r)
+}
+
+func (h *APIHandler) UncompleteEntry(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	step, ok := apiStep(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.goalService.UncompleteEntry(membership.OrganizationID, goalID, step)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	h.GetGoal(w, r)
+}
+
+// UpdateEntry changes the note or completion date of a completed step
+func (h *APIHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	goalID := r.PathValue("id")
+
+	step, ok := apiStep(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Note        *string    `json:"note"`
+		CompletedAt *time.Time `json:"completed_at"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	_, err := h.goalService.ByID(membership.OrganizationID, goalID)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	entry, err := h.goalService.EntryByGoalAndStep(goalID, step)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	completedAt := entry.CompletedAt
+	if body.CompletedAt != nil {
+		completedAt = body.CompletedAt
+	}
+
+	err = h.goalService.UpdateEntry(membership.OrganizationID, goalID, step, valueOr(body.Note, entry.Note), entry.NoteShared, completedAt)
+	if err != nil {
+		h.goalError(w, err, user.ID, goalID)
+		return
+	}
+
+	h.GetGoal(w, r)
+}
+
+// goalError maps goal service errors to API responses
+func (h *APIHandler) goalError(w http.ResponseWriter, err error, userID, goalID string) {
+	switch {
+	case errors.Is(err, repository.ErrGoalNotFound), errors.Is(err, repository.ErrGoalEntryNotFound):
+		writeAPIError(w, http.StatusNotFound, "goal not found")
+	case errors.Is(err, service.ErrInvalidGoalCadence):
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrInvalidStep),
+		errors.Is(err, service.ErrGoalAlreadyCompleted),
+		errors.Is(err, service.ErrEntryNotCompleted),
+		errors.Is(err, service.ErrNotLastStep):
+		writeAPIError(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error("goal api request failed", "error", err, "user_id", userID, "goal_id", goalID)
+		writeAPIError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func apiStep(w http.ResponseWriter, r *http.Request) (int, bool) {
+	step, err := strconv.Atoi(r.PathValue("step"))
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
+		writeAPIError(w, http.StatusBadRequest, "invalid step number")
+		return 0, false
+	}
+	return step, true
+}
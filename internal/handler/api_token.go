@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// APITokenHandler manages personal access tokens from the settings page
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+}
+
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	_, plain, err := h.apiTokenService.Create(user.ID, r.FormValue("name"), r.FormValue("scope"))
+	if err != nil {
+		errMsg := "Failed to create token"
+		if errors.Is(err, service.ErrAPITokenNameRequired) ||
+			errors.Is(err, service.ErrInvalidAPITokenScope) ||
+			errors.Is(err, service.ErrAPITokenLimitReached) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to create api token", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Token created",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderTokens(w, r, plain)
+}
+
+func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	tokenID := r.PathValue("id")
+
+	err := h.apiTokenService.Revoke(user.ID, tokenID)
+	if err != nil {
+		slog.Warn("revoke api token failed", "error", err, "user_id", user.ID, "token_id", tokenID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to revoke token",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Token revoked",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderTokens(w, r, "")
+}
+
+// renderTokens re-renders the token list, including a newly created token once
+func (h *APITokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, created string) {
+	user := ctxkeys.User(r.Context())
+
+	tokens, err := h.apiTokenService.Tokens(user.ID)
+	if err != nil {
+		slog.Error("failed to load api tokens", "error", err, "user_id", user.ID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.SettingsAPITokensSection(tokens, created), "settings-api-tokens")
+}
@@ -5,13 +5,18 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/templui/goilerplate/internal/config"
 	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/oidc"
 	"github.com/templui/goilerplate/internal/service"
 	"github.com/templui/goilerplate/internal/ui"
 	"github.com/templui/goilerplate/internal/ui/components/toast"
@@ -28,13 +33,15 @@ type authHandler struct {
 	subscriptionService *service.SubscriptionService
 	googleOAuthConfig   *oauth2.Config
 	githubOAuthConfig   *oauth2.Config
+	oidcProviders       *oidc.Registry
 }
 
-func NewAuthHandler(authService *service.AuthService, userService *service.UserService, subscriptionService *service.SubscriptionService, cfg *config.Config) *authHandler {
+func NewAuthHandler(authService *service.AuthService, userService *service.UserService, subscriptionService *service.SubscriptionService, oidcProviders *oidc.Registry, cfg *config.Config) *authHandler {
 	return &authHandler{
 		authService:         authService,
 		userService:         userService,
 		subscriptionService: subscriptionService,
+		oidcProviders:       oidcProviders,
 		googleOAuthConfig: &oauth2.Config{
 			ClientID:     cfg.GoogleClientID,
 			ClientSecret: cfg.GoogleClientSecret,
@@ -53,6 +60,19 @@ func NewAuthHandler(authService *service.AuthService, userService *service.UserS
 }
 
 func (h *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	session := ctxkeys.Session(r.Context())
+	// While impersonating, the session is the admin's
+	if impersonator := ctxkeys.Impersonator(r.Context()); impersonator != nil {
+		user = impersonator
+	}
+	if user != nil && session != nil {
+		err := h.authService.RevokeSession(user.ID, session.ID)
+		if err != nil {
+			slog.Warn("failed to revoke session on logout", "error", err, "user_id", user.ID)
+		}
+	}
+
 	h.authService.ClearJWTCookie(w)
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -95,54 +115,107 @@ func (h *authHandler) VerifyForgotPassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if user.HasPassword() {
-		err = h.authService.RemovePassword(user.ID)
+	// Redirect to settings with query param for toast notification
+	next := "/app/settings?password_removed=1"
+
+	// Whoever holds the email alone must not be able to remove the password or
+	// sign out every device, with 2FA that waits for the second factor
+	if user.HasTwoFactor() {
+		challenge, err := h.authService.GenerateTwoFactorChallenge(user, next, true)
 		if err != nil {
-			slog.Error("failed to remove password during forgot password flow", "error", err, "user_id", user.ID)
+			slog.Error("failed to create two-factor challenge", "error", err, "user_id", user.ID)
 			ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
 			return
 		}
-		slog.Info("password removed via forgot password flow", "user_id", user.ID)
+		h.authService.SetTwoFactorCookie(w, challenge)
+		http.Redirect(w, r, "/auth/2fa", http.StatusSeeOther)
+		return
 	}
 
-	jwtToken, err := h.authService.GenerateJWT(user)
+	err = h.forgetPassword(r, user)
 	if err != nil {
-		slog.Error("failed to generate JWT", "error", err, "user_id", user.ID)
 		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
 		return
 	}
 
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
+	err = h.authService.StartSession(w, user, "forgot_password", middleware.AuditContext(r))
+	if err != nil {
+		slog.Error("failed to sign in", "error", err, "user_id", user.ID)
+		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
+		return
+	}
 
 	slog.Info("user logged in via forgot password flow", "user_id", user.ID, "email", user.Email)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
 
-	// Redirect to settings with query param for toast notification
-	http.Redirect(w, r, "/app/settings?password_removed=1", http.StatusSeeOther)
+// forgetPassword removes the password of a forgot password sign-in
+// The old password may be compromised, so every session is signed out too.
+func (h *authHandler) forgetPassword(r *http.Request, user *model.User) error {
+	if !user.HasPassword() {
+		return nil
+	}
+
+	err := h.authService.RemovePassword(user.ID, middleware.AuditContext(r))
+	if err != nil {
+		slog.Error("failed to remove password during forgot password flow", "error", err, "user_id", user.ID)
+		return err
+	}
+	user.PasswordHash = nil
+	slog.Info("password removed via forgot password flow", "user_id", user.ID)
+
+	err = h.authService.RevokeAllSessions(user.ID)
+	if err != nil {
+		slog.Warn("failed to revoke sessions during forgot password flow", "error", err, "user_id", user.ID)
+	}
+	return nil
 }
 
 func (h *authHandler) VerifyEmailChange(w http.ResponseWriter, r *http.Request) {
 	token := r.PathValue("token")
 
-	user, err := h.authService.VerifyEmailChange(token)
+	user, err := h.authService.VerifyEmailChange(token, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("email change verification failed", "error", err, "token", token)
 		ui.Render(w, r, pages.VerifyEmailError("Invalid or expired verification link"))
 		return
 	}
 
-	jwtToken, err := h.authService.GenerateJWT(user)
-	if err != nil {
-		slog.Error("failed to generate JWT after email change", "error", err, "user_id", user.ID)
-		ui.Render(w, r, pages.VerifyEmailError("An error occurred. Please try again."))
-		return
+	// Accounts with 2FA must sign in again with their second factor
+	// (all previous sessions were revoked by the email change)
+	if !user.HasTwoFactor() {
+		err = h.authService.StartSession(w, user, "email_change", middleware.AuditContext(r))
+		if err != nil {
+			slog.Error("failed to start session after email change", "error", err, "user_id", user.ID)
+			ui.Render(w, r, pages.VerifyEmailError("An error occurred. Please try again."))
+			return
+		}
 	}
 
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
-
 	slog.Info("email changed", "user_id", user.ID, "new_email", user.Email)
 	ui.Render(w, r, pages.VerifyEmailSuccess())
 }
 
+// SecureAccountPage is the "this wasn't me" link of a new-device alert
+func (h *authHandler) SecureAccountPage(w http.ResponseWriter, r *http.Request) {
+	ui.Render(w, r, pages.SecureAccount(r.PathValue("token"), ""))
+}
+
+// SecureAccount signs out every device of the account the link was sent to
+func (h *authHandler) SecureAccount(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	_, err := h.authService.SecureAccount(token, middleware.AuditContext(r))
+	if err != nil {
+		slog.Warn("secure account failed", "error", err)
+		ui.Render(w, r, pages.SecureAccount(token, "This link is invalid, expired or was already used."))
+		return
+	}
+
+	h.authService.ClearJWTCookie(w)
+	ui.Render(w, r, pages.SecureAccountDone())
+}
+
 func (h *authHandler) SendMagicLink(w http.ResponseWriter, r *http.Request) {
 	email := strings.TrimSpace(r.FormValue("email"))
 
@@ -188,28 +261,26 @@ func (h *authHandler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jwtToken, err := h.authService.GenerateJWT(user)
-	if err != nil {
-		slog.Error("failed to generate JWT", "error", err, "user_id", user.ID)
-		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
-		return
-	}
-
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
-
 	needsOnboarding, err := h.authService.NeedsOnboarding(user.ID)
 	if err != nil {
 		slog.Warn("failed to check onboarding status", "error", err, "user_id", user.ID)
 	}
 
+	next := "/app/dashboard"
 	if needsOnboarding {
 		slog.Info("new user needs onboarding", "user_id", user.ID, "email", user.Email)
-		http.Redirect(w, r, "/auth/onboarding", http.StatusSeeOther)
+		next = "/auth/onboarding"
+	}
+
+	redirectURL, err := h.signIn(w, r, user, "magic_link", next)
+	if err != nil {
+		slog.Error("failed to sign in", "error", err, "user_id", user.ID)
+		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
 		return
 	}
 
 	slog.Info("user logged in via magic link", "user_id", user.ID, "email", user.Email)
-	http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 }
 
 func (h *authHandler) AuthPage(w http.ResponseWriter, r *http.Request) {
@@ -259,206 +330,393 @@ func (h *authHandler) PasswordAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.authService.Login(email, password)
+	user, err := h.authService.Login(email, password, middleware.AuditContext(r))
 	if err != nil {
 		slog.Warn("password login failed", "error", err, "email", email)
-		ui.Render(w, r, pages.AuthPassword("Invalid email or password"))
+		switch {
+		case errors.Is(err, service.ErrAccountLocked):
+			ui.Render(w, r, pages.AuthPassword("Too many failed attempts. Password sign-in is locked for a while, use a magic link or passkey instead."))
+		case errors.Is(err, service.ErrTooManyAttempts):
+			ui.Render(w, r, pages.AuthPassword("Too many failed attempts. Please wait a moment and try again."))
+		default:
+			ui.Render(w, r, pages.AuthPassword("Invalid email or password"))
+		}
 		return
 	}
 
-	jwtToken, err := h.authService.GenerateJWT(user)
+	redirectURL, err := h.signIn(w, r, user, "password", "/app/dashboard")
 	if err != nil {
-		slog.Error("failed to generate JWT", "error", err, "user_id", user.ID)
+		slog.Error("failed to sign in", "error", err, "user_id", user.ID)
 		ui.Render(w, r, pages.AuthPassword("An error occurred. Please try again."))
 		return
 	}
 
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
-
 	slog.Info("user logged in with password", "user_id", user.ID, "email", user.Email)
-	http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 }
 
 // GoogleAuth redirects user to Google OAuth consent screen
 func (h *authHandler) GoogleAuth(w http.ResponseWriter, r *http.Request) {
+	h.startOAuth(w, r, "", oauthCodeURL(h.googleOAuthConfig))
+}
+
+// GoogleCallback handles the OAuth callback from Google
+func (h *authHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+	h.oauthCallback(w, r, model.IdentityProviderGoogle, oauthProfile(h.googleOAuthConfig, fetchGoogleProfile))
+}
+
+// GitHubAuth redirects user to GitHub OAuth consent screen
+func (h *authHandler) GitHubAuth(w http.ResponseWriter, r *http.Request) {
+	h.startOAuth(w, r, "", oauthCodeURL(h.githubOAuthConfig))
+}
+
+// GitHubCallback handles the OAuth callback from GitHub
+func (h *authHandler) GitHubCallback(w http.ResponseWriter, r *http.Request) {
+	h.oauthCallback(w, r, model.IdentityProviderGitHub, oauthProfile(h.githubOAuthConfig, fetchGitHubProfile))
+}
+
+// OIDCAuth redirects to a configured OpenID Connect provider
+func (h *authHandler) OIDCAuth(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.oidcProviders.Get(r.PathValue("provider"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.startOAuth(w, r, "", h.oidcCodeURL(w, r, provider))
+}
+
+// OIDCCallback handles the callback from a configured OpenID Connect provider
+func (h *authHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.oidcProviders.Get(r.PathValue("provider"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.oauthCallback(w, r, provider.ID(), h.oidcProfile(w, provider))
+}
+
+// LinkIdentity starts the OAuth flow for connecting a provider from the settings page
+// The callback sees the oauth_intent cookie and links instead of signing in
+func (h *authHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	providerID := r.PathValue("provider")
+
+	switch providerID {
+	case model.IdentityProviderGoogle:
+		h.startOAuth(w, r, oauthIntentLink, oauthCodeURL(h.googleOAuthConfig))
+	case model.IdentityProviderGitHub:
+		h.startOAuth(w, r, oauthIntentLink, oauthCodeURL(h.githubOAuthConfig))
+	default:
+		provider, ok := h.oidcProviders.Get(providerID)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h.startOAuth(w, r, oauthIntentLink, h.oidcCodeURL(w, r, provider))
+	}
+}
+
+const oauthIntentLink = "link"
+
+// startOAuth stores the state (and intent, if any) and redirects to the consent screen
+// codeURL builds the provider's authorization URL for the state
+func (h *authHandler) startOAuth(w http.ResponseWriter, r *http.Request, intent string, codeURL func(state string) (string, error)) {
 	// Generate secure state token for CSRF protection
 	state := generateOAuthState()
 
-	cfg := ctxkeys.Config(r.Context())
-	isProduction := cfg != nil && cfg.IsProduction()
+	authURL, err := codeURL(state)
+	if err != nil {
+		slog.Error("failed to start oauth", "error", err)
+		if intent == oauthIntentLink {
+			http.Redirect(w, r, "/app/settings?identity_error=failed", http.StatusSeeOther)
+			return
+		}
+		ui.Render(w, r, pages.Auth("This sign-in method is unavailable right now. Please try again later."))
+		return
+	}
 
 	// Store state in secure cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   isProduction, // Secure flag based on APP_ENV (safer than r.TLS behind load balancers)
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   600, // 10 minutes
-	})
+	setOAuthCookie(w, r, "oauth_state", state)
+	setOAuthCookie(w, r, "oauth_intent", intent)
 
-	url := h.googleOAuthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
 }
 
-// GoogleCallback handles the OAuth callback from Google
-func (h *authHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+// oauthCallback validates the state and resolves the provider profile from the code,
+// then either links the identity to the signed-in user or signs in with it
+func (h *authHandler) oauthCallback(
+	w http.ResponseWriter,
+	r *http.Request,
+	provider string,
+	resolveProfile func(r *http.Request, code string) (service.OAuthProfile, error),
+) {
+	linking := false
+	intentCookie, err := r.Cookie("oauth_intent")
+	if err == nil && intentCookie.Value == oauthIntentLink {
+		linking = true
+	}
+
+	fail := func(message string) {
+		if linking {
+			http.Redirect(w, r, "/app/settings?identity_error=failed", http.StatusSeeOther)
+			return
+		}
+		ui.Render(w, r, pages.Auth(message))
+	}
+
 	// Validate state parameter for CSRF protection
 	state := r.URL.Query().Get("state")
 	cookie, err := r.Cookie("oauth_state")
 	if err != nil || cookie.Value != state || state == "" {
-		slog.Warn("google oauth state validation failed", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+		slog.Warn("oauth state validation failed", "error", err, "provider", provider)
+		fail("OAuth authentication failed. Please try again.")
 		return
 	}
 
-	// Clear state cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:   "oauth_state",
-		Value:  "",
-		Path:   "/",
-		MaxAge: -1,
-	})
+	// Clear state and intent cookies
+	clearOAuthCookie(w, "oauth_state")
+	clearOAuthCookie(w, "oauth_intent")
 
 	code := r.URL.Query().Get("code")
 	if code == "" {
-		slog.Warn("google oauth callback missing code")
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+		slog.Warn("oauth callback missing code", "provider", provider, "error", r.URL.Query().Get("error"))
+		fail("OAuth authentication failed. Please try again.")
 		return
 	}
 
-	// Exchange code for token
-	token, err := h.googleOAuthConfig.Exchange(context.Background(), code)
+	profile, err := resolveProfile(r, code)
 	if err != nil {
-		slog.Error("google oauth token exchange failed", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+		slog.Error("failed to get oauth profile", "error", err, "provider", provider)
+		fail("OAuth authentication failed. Please try again.")
 		return
 	}
+	profile.Provider = provider
 
-	// Get user info from Google
-	client := h.googleOAuthConfig.Client(context.Background(), token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
-	if err != nil {
-		slog.Error("failed to get google user info", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+	if linking {
+		h.linkIdentity(w, r, profile)
 		return
 	}
-	defer func() {
-		closeErr := resp.Body.Close()
-		if closeErr != nil {
-			slog.Error("failed to close response body", "error", closeErr)
-		}
-	}()
 
-	var userInfo struct {
-		Email string `json:"email"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+	// Authenticate or create user
+	user, err := h.authService.AuthenticateOAuth(profile)
 	if err != nil {
-		slog.Error("failed to decode google user info", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+		errMsg := "Authentication failed. Please try again."
+		switch {
+		case errors.Is(err, service.ErrOAuthAccountExists):
+			errMsg = fmt.Sprintf("An account with this email already exists. Sign in with your email, then connect %s from Settings.", identityProviderName(r, provider))
+		case errors.Is(err, service.ErrOAuthEmailNotVerified):
+			errMsg = fmt.Sprintf("Please verify your email with %s first.", identityProviderName(r, provider))
+		}
+		slog.Warn("oauth authentication failed", "error", err, "provider", provider, "email", profile.Email)
+		ui.Render(w, r, pages.Auth(errMsg))
 		return
 	}
 
-	// Authenticate or create user
-	user, err := h.authService.AuthenticateOAuth(userInfo.Email, "google")
+	// Check if user needs onboarding
+	needsOnboarding, err := h.authService.NeedsOnboarding(user.ID)
 	if err != nil {
-		slog.Error("oauth authentication failed", "error", err, "email", userInfo.Email)
-		ui.Render(w, r, pages.Auth("Authentication failed. Please try again."))
-		return
+		slog.Warn("failed to check onboarding status", "error", err, "user_id", user.ID)
+	}
+
+	next := "/app/dashboard"
+	if needsOnboarding {
+		next = "/auth/onboarding"
 	}
 
-	// Generate JWT
-	jwtToken, err := h.authService.GenerateJWT(user)
+	redirectURL, err := h.signIn(w, r, user, profile.Provider, next)
 	if err != nil {
-		slog.Error("failed to generate JWT", "error", err, "user_id", user.ID)
+		slog.Error("failed to sign in", "error", err, "user_id", user.ID)
 		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
 		return
 	}
 
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
+	slog.Info("user logged in with oauth", "user_id", user.ID, "email", user.Email, "provider", provider)
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
 
-	slog.Info("user logged in with google oauth", "user_id", user.ID, "email", user.Email)
+// linkIdentity finishes a link flow started from the settings page
+func (h *authHandler) linkIdentity(w http.ResponseWriter, r *http.Request, profile service.OAuthProfile) {
+	user := ctxkeys.User(r.Context())
+	if user == nil {
+		ui.Render(w, r, pages.Auth("Please sign in to connect an account."))
+		return
+	}
 
-	// Check if user needs onboarding
-	needsOnboarding, err := h.authService.NeedsOnboarding(user.ID)
+	err := h.authService.LinkIdentity(user.ID, profile, middleware.AuditContext(r))
 	if err != nil {
-		slog.Warn("failed to check onboarding status", "error", err, "user_id", user.ID)
+		code := "failed"
+		switch {
+		case errors.Is(err, service.ErrIdentityAlreadyLinked):
+			code = "already_linked"
+		case errors.Is(err, service.ErrIdentityLinkedToOtherUser):
+			code = "other_user"
+		default:
+			slog.Error("failed to link identity", "error", err, "user_id", user.ID, "provider", profile.Provider)
+		}
+		http.Redirect(w, r, "/app/settings?identity_error="+code, http.StatusSeeOther)
+		return
 	}
 
-	if needsOnboarding {
-		http.Redirect(w, r, "/auth/onboarding", http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+	http.Redirect(w, r, "/app/settings?identity_linked="+profile.Provider, http.StatusSeeOther)
+}
+
+// oauthCodeURL builds the consent screen URL for a plain OAuth provider
+func oauthCodeURL(oauthConfig *oauth2.Config) func(state string) (string, error) {
+	return func(state string) (string, error) {
+		return oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
 	}
 }
 
-// GitHubAuth redirects user to GitHub OAuth consent screen
-func (h *authHandler) GitHubAuth(w http.ResponseWriter, r *http.Request) {
-	// Generate secure state token for CSRF protection
-	state := generateOAuthState()
+// oauthProfile exchanges the code and reads the profile from the provider's API
+func oauthProfile(oauthConfig *oauth2.Config, fetchProfile func(client *http.Client) (service.OAuthProfile, error)) func(r *http.Request, code string) (service.OAuthProfile, error) {
+	return func(r *http.Request, code string) (service.OAuthProfile, error) {
+		token, err := oauthConfig.Exchange(context.Background(), code)
+		if err != nil {
+			return service.OAuthProfile{}, fmt.Errorf("token exchange failed: %w", err)
+		}
 
-	// Get config from context to determine if we're in production
+		return fetchProfile(oauthConfig.Client(context.Background(), token))
+	}
+}
+
+// oidcCodeURL stores a fresh nonce and PKCE verifier next to the state cookie
+func (h *authHandler) oidcCodeURL(w http.ResponseWriter, r *http.Request, provider *oidc.Provider) func(state string) (string, error) {
+	return func(state string) (string, error) {
+		nonce := oidc.GenerateNonce()
+		verifier := oauth2.GenerateVerifier()
+
+		authURL, err := provider.AuthCodeURL(r.Context(), state, nonce, verifier)
+		if err != nil {
+			return "", err
+		}
+
+		setOAuthCookie(w, r, "oidc_nonce", nonce)
+		setOAuthCookie(w, r, "oidc_verifier", verifier)
+		return authURL, nil
+	}
+}
+
+// oidcProfile redeems the code with the stored verifier and checks the ID token against the stored nonce
+func (h *authHandler) oidcProfile(w http.ResponseWriter, provider *oidc.Provider) func(r *http.Request, code string) (service.OAuthProfile, error) {
+	return func(r *http.Request, code string) (service.OAuthProfile, error) {
+		nonce, err := r.Cookie("oidc_nonce")
+		if err != nil {
+			return service.OAuthProfile{}, fmt.Errorf("missing nonce cookie: %w", err)
+		}
+		verifier, err := r.Cookie("oidc_verifier")
+		if err != nil {
+			return service.OAuthProfile{}, fmt.Errorf("missing verifier cookie: %w", err)
+		}
+		clearOAuthCookie(w, "oidc_nonce")
+		clearOAuthCookie(w, "oidc_verifier")
+
+		claims, err := provider.Exchange(r.Context(), code, nonce.Value, verifier.Value)
+		if err != nil {
+			return service.OAuthProfile{}, err
+		}
+
+		return service.OAuthProfile{
+			Subject:       claims.Subject,
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified,
+		}, nil
+	}
+}
+
+// setOAuthCookie stores short-lived sign-in state (state, intent, nonce, PKCE verifier)
+// SameSite Lax so the cookie comes back on the provider's top-level redirect
+func setOAuthCookie(w http.ResponseWriter, r *http.Request, name, value string) {
 	cfg := ctxkeys.Config(r.Context())
 	isProduction := cfg != nil && cfg.IsProduction()
 
-	// Store state in secure cookie
 	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
+		Name:     name,
+		Value:    value,
 		Path:     "/",
 		HttpOnly: true,
 		Secure:   isProduction, // Secure flag based on APP_ENV (safer than r.TLS behind load balancers)
 		SameSite: http.SameSiteLaxMode,
 		MaxAge:   600, // 10 minutes
 	})
-
-	url := h.githubOAuthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
 }
 
-// GitHubCallback handles the OAuth callback from GitHub
-func (h *authHandler) GitHubCallback(w http.ResponseWriter, r *http.Request) {
-	// Validate state parameter for CSRF protection
-	state := r.URL.Query().Get("state")
-	cookie, err := r.Cookie("oauth_state")
-	if err != nil || cookie.Value != state || state == "" {
-		slog.Warn("github oauth state validation failed", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-		return
-	}
-
-	// Clear state cookie
+func clearOAuthCookie(w http.ResponseWriter, name string) {
 	http.SetCookie(w, &http.Cookie{
-		Name:   "oauth_state",
+		Name:   name,
 		Value:  "",
 		Path:   "/",
 		MaxAge: -1,
 	})
+}
 
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		slog.Warn("github oauth callback missing code")
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-		return
+// identityProviderName returns the display name for google, github or a configured OIDC provider
+func identityProviderName(r *http.Request, provider string) string {
+	cfg := ctxkeys.Config(r.Context())
+	if cfg != nil {
+		if oidcProvider := cfg.OIDCProviderByID(provider); oidcProvider != nil {
+			return oidcProvider.Name
+		}
 	}
+	return model.IdentityProviderName(provider)
+}
 
-	// Exchange code for token
-	token, err := h.githubOAuthConfig.Exchange(context.Background(), code)
+// fetchGoogleProfile reads the account id and email from the Google userinfo endpoint
+func fetchGoogleProfile(client *http.Client) (service.OAuthProfile, error) {
+	var userInfo struct {
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
+	}
+	err := getOAuthJSON(client, "https://www.googleapis.com/oauth2/v2/userinfo", &userInfo)
 	if err != nil {
-		slog.Error("github oauth token exchange failed", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-		return
+		return service.OAuthProfile{}, err
+	}
+
+	return service.OAuthProfile{
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+	}, nil
+}
+
+// fetchGitHubProfile reads the numeric account id and the verified primary email
+// The email on /user is whatever the user made public, so /user/emails is the source of truth
+func fetchGitHubProfile(client *http.Client) (service.OAuthProfile, error) {
+	var userInfo struct {
+		ID int64 `json:"id"`
+	}
+	err := getOAuthJSON(client, "https://api.github.com/user", &userInfo)
+	if err != nil {
+		return service.OAuthProfile{}, err
 	}
 
-	// Get user info from GitHub
-	client := h.githubOAuthConfig.Client(context.Background(), token)
-	resp, err := client.Get("https://api.github.com/user")
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	err = getOAuthJSON(client, "https://api.github.com/user/emails", &emails)
 	if err != nil {
-		slog.Error("failed to get github user info", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-		return
+		return service.OAuthProfile{}, err
+	}
+
+	profile := service.OAuthProfile{Subject: strconv.FormatInt(userInfo.ID, 10)}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return profile, nil
+}
+
+func getOAuthJSON(client *http.Client, endpoint string, v any) error {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return err
 	}
 	defer func() {
 		closeErr := resp.Body.Close()
@@ -467,89 +725,102 @@ func (h *authHandler) GitHubCallback(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	var userInfo struct {
-		Email string `json:"email"`
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, endpoint)
 	}
-	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (h *authHandler) TwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(service.TwoFactorCookieName)
 	if err != nil {
-		slog.Error("failed to decode github user info", "error", err)
-		ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
+		http.Redirect(w, r, "/auth", http.StatusSeeOther)
 		return
 	}
 
-	// GitHub API may not return email in main response if it's private
-	// Need to fetch from /user/emails endpoint
-	if userInfo.Email == "" {
-		emailResp, err := client.Get("https://api.github.com/user/emails")
-		if err != nil {
-			slog.Error("failed to get github user emails", "error", err)
-			ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-			return
-		}
-		defer func() {
-			closeErr := emailResp.Body.Close()
-			if closeErr != nil {
-				slog.Error("failed to close email response body", "error", closeErr)
-			}
-		}()
-
-		var emails []struct {
-			Email   string `json:"email"`
-			Primary bool   `json:"primary"`
-		}
-		err = json.NewDecoder(emailResp.Body).Decode(&emails)
-		if err != nil {
-			slog.Error("failed to decode github emails", "error", err)
-			ui.Render(w, r, pages.Auth("OAuth authentication failed. Please try again."))
-			return
-		}
+	_, err = h.authService.VerifyTwoFactorChallenge(cookie.Value)
+	if err != nil {
+		h.authService.ClearTwoFactorCookie(w)
+		ui.Render(w, r, pages.Auth("Your sign-in session expired. Please try again."))
+		return
+	}
 
-		// Find primary email
-		for _, e := range emails {
-			if e.Primary {
-				userInfo.Email = e.Email
-				break
-			}
-		}
+	ui.Render(w, r, pages.TwoFactorChallenge(""))
+}
+
+// TwoFactorVerify completes sign-in after the first factor with a TOTP or recovery code
+func (h *authHandler) TwoFactorVerify(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(service.TwoFactorCookieName)
+	if err != nil {
+		http.Redirect(w, r, "/auth", http.StatusSeeOther)
+		return
 	}
 
-	if userInfo.Email == "" {
-		slog.Warn("github oauth: no email found")
-		ui.Render(w, r, pages.Auth("Could not retrieve email from GitHub. Please make sure your email is public."))
+	challenge, err := h.authService.VerifyTwoFactorChallenge(cookie.Value)
+	if err != nil {
+		h.authService.ClearTwoFactorCookie(w)
+		ui.Render(w, r, pages.Auth("Your sign-in session expired. Please try again."))
 		return
 	}
+	user := challenge.User
 
-	// Authenticate or create user
-	user, err := h.authService.AuthenticateOAuth(userInfo.Email, "github")
+	err = h.authService.VerifyTwoFactorCode(user, r.FormValue("code"), middleware.AuditContext(r))
 	if err != nil {
-		slog.Error("oauth authentication failed", "error", err, "email", userInfo.Email)
-		ui.Render(w, r, pages.Auth("Authentication failed. Please try again."))
+		slog.Warn("two-factor verification failed", "error", err, "user_id", user.ID)
+		switch {
+		case errors.Is(err, service.ErrTwoFactorLocked):
+			// The challenge is used up, signing in again starts a new one once the lock ends
+			h.authService.ClearTwoFactorCookie(w)
+			ui.Render(w, r, pages.Auth("Too many invalid codes. Two-factor sign-in is locked for a while, please try again later."))
+		case errors.Is(err, service.ErrTooManyAttempts):
+			ui.Render(w, r, pages.TwoFactorChallenge("Too many invalid codes. Please wait a moment and try again."))
+		default:
+			ui.Render(w, r, pages.TwoFactorChallenge("Invalid authentication code"))
+		}
 		return
 	}
 
-	// Generate JWT
-	jwtToken, err := h.authService.GenerateJWT(user)
+	if challenge.RemovePassword {
+		err = h.forgetPassword(r, user)
+		if err != nil {
+			ui.Render(w, r, pages.TwoFactorChallenge("An error occurred. Please try again."))
+			return
+		}
+	}
+
+	err = h.authService.StartSession(w, user, "two_factor", middleware.AuditContext(r))
 	if err != nil {
-		slog.Error("failed to generate JWT", "error", err, "user_id", user.ID)
-		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
+		slog.Error("failed to start session", "error", err, "user_id", user.ID)
+		ui.Render(w, r, pages.TwoFactorChallenge("An error occurred. Please try again."))
 		return
 	}
 
-	h.authService.SetJWTCookie(w, jwtToken, time.Now().Add(7*24*time.Hour))
+	h.authService.ClearTwoFactorCookie(w)
 
-	slog.Info("user logged in with github oauth", "user_id", user.ID, "email", user.Email)
+	slog.Info("user completed two-factor sign in", "user_id", user.ID, "email", user.Email)
+	http.Redirect(w, r, challenge.Next, http.StatusSeeOther)
+}
 
-	// Check if user needs onboarding
-	needsOnboarding, err := h.authService.NeedsOnboarding(user.ID)
-	if err != nil {
-		slog.Warn("failed to check onboarding status", "error", err, "user_id", user.ID)
+// signIn finishes a successful first factor
+// Users with 2FA get a short-lived challenge cookie and are sent to /auth/2fa,
+// everyone else gets a new session. method is recorded with the sign-in. Returns where to redirect.
+func (h *authHandler) signIn(w http.ResponseWriter, r *http.Request, user *model.User, method, next string) (string, error) {
+	if user.HasTwoFactor() {
+		challenge, err := h.authService.GenerateTwoFactorChallenge(user, next, false)
+		if err != nil {
+			return "", err
+		}
+		h.authService.SetTwoFactorCookie(w, challenge)
+		return "/auth/2fa", nil
 	}
 
-	if needsOnboarding {
-		http.Redirect(w, r, "/auth/onboarding", http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+	err := h.authService.StartSession(w, user, method, middleware.AuditContext(r))
+	if err != nil {
+		return "", err
 	}
+
+	return next, nil
 }
 
 // generateOAuthState creates cryptographically secure random state token for OAuth CSRF protection
@@ -1,35 +1,55 @@
 package handler
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/templui/goilerplate/internal/ctxkeys"
 	"github.com/templui/goilerplate/internal/service"
 	"github.com/templui/goilerplate/internal/service/payment"
 	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
 	"github.com/templui/goilerplate/internal/ui/pages"
 )
 
 type BillingHandler struct {
 	subscriptionService *service.SubscriptionService
 	paymentService      payment.Provider
+	organizationService *service.OrganizationService
 }
 
-func NewBillingHandler(subscriptionService *service.SubscriptionService, paymentService payment.Provider) *BillingHandler {
+func NewBillingHandler(subscriptionService *service.SubscriptionService, paymentService payment.Provider, organizationService *service.OrganizationService) *BillingHandler {
 	return &BillingHandler{
 		subscriptionService: subscriptionService,
 		paymentService:      paymentService,
+		organizationService: organizationService,
 	}
 }
 
 func (h *BillingHandler) BillingPage(w http.ResponseWriter, r *http.Request) {
-	ui.Render(w, r, pages.Billing())
+	membership := ctxkeys.Membership(r.Context())
+
+	seatsUsed, err := h.organizationService.SeatsUsed(membership.OrganizationID)
+	if err != nil {
+		slog.Error("failed to count seats", "error", err, "organization_id", membership.OrganizationID)
+		http.Error(w, "Failed to load billing", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.Billing(seatsUsed))
 }
 
+// CreateCheckout starts a checkout for the active workspace, owners only
 func (h *BillingHandler) CreateCheckout(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	if !membership.CanManageBilling() {
+		http.Error(w, "Only workspace owners can manage billing", http.StatusForbidden)
+		return
+	}
 
 	profile := ctxkeys.Profile(r.Context())
 	if profile == nil {
@@ -48,17 +68,60 @@ func (h *BillingHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 		interval = "monthly"
 	}
 
-	checkoutURL, err := h.paymentService.CreateCheckoutURL(user.ID, planID, interval, user.Email, profile.Name)
+	// Paid plans are billed per seat, start with everyone already in the workspace
+	seats, err := h.organizationService.SeatsUsed(membership.OrganizationID)
 	if err != nil {
-		slog.Error("failed to create checkout", "error", err, "user_id", user.ID, "plan_id", planID, "provider", h.paymentService.Name())
+		slog.Error("failed to count seats", "error", err, "organization_id", membership.OrganizationID)
 		http.Error(w, "Failed to create checkout session", http.StatusInternalServerError)
 		return
 	}
 
-	slog.Info("redirecting to checkout", "user_id", user.ID, "provider", h.paymentService.Name(), "checkout_url", checkoutURL)
+	checkoutURL, err := h.paymentService.CreateCheckoutURL(membership.OrganizationID, planID, interval, max(seats, 1), user.Email, profile.Name)
+	if err != nil {
+		slog.Error("failed to create checkout", "error", err, "user_id", user.ID, "organization_id", membership.OrganizationID, "plan_id", planID, "provider", h.paymentService.Name())
+		http.Error(w, "Failed to create checkout session", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("redirecting to checkout", "user_id", user.ID, "organization_id", membership.OrganizationID, "provider", h.paymentService.Name(), "checkout_url", checkoutURL)
 	http.Redirect(w, r, checkoutURL, http.StatusSeeOther)
 }
 
+// UpdateSeats changes the purchased seats of the active workspace, owners only
+func (h *BillingHandler) UpdateSeats(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	seats, err := strconv.Atoi(r.FormValue("seats"))
+	if err != nil {
+		err = service.ErrInvalidSeats
+	} else {
+		err = h.organizationService.UpdateSeats(membership, seats)
+	}
+	if err != nil {
+		errMsg := "Failed to update seats"
+		if errors.Is(err, service.ErrWorkspacePermission) ||
+			errors.Is(err, service.ErrInvalidSeats) ||
+			errors.Is(err, service.ErrSeatsNotAvailable) ||
+			errors.Is(err, service.ErrSeatsInUse) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to update seats", "error", err, "organization_id", membership.OrganizationID, "provider", h.paymentService.Name())
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/app/billing")
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *BillingHandler) Webhook(w http.ResponseWriter, r *http.Request) {
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -86,10 +149,15 @@ func (h *BillingHandler) Webhook(w http.ResponseWriter, r *http.Request) {
 
 func (h *BillingHandler) CustomerPortal(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+	if !membership.CanManageBilling() {
+		http.Error(w, "Only workspace owners can manage billing", http.StatusForbidden)
+		return
+	}
 
-	portalURL, err := h.paymentService.CustomerPortalURL(user.ID)
+	portalURL, err := h.paymentService.CustomerPortalURL(membership.OrganizationID)
 	if err != nil {
-		slog.Error("failed to get customer portal", "error", err, "user_id", user.ID, "provider", h.paymentService.Name())
+		slog.Error("failed to get customer portal", "error", err, "user_id", user.ID, "organization_id", membership.OrganizationID, "provider", h.paymentService.Name())
 		http.Error(w, "Failed to access customer portal", http.StatusInternalServerError)
 		return
 	}
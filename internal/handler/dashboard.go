@@ -1,18 +1,75 @@
 package handler
 
 import (
+	"encoding/json"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/service"
 	"github.com/templui/goilerplate/internal/ui"
 	"github.com/templui/goilerplate/internal/ui/pages"
 )
 
-type DashboardHandler struct{}
+type DashboardHandler struct {
+	goalAnalyticsService *service.GoalAnalyticsService
+}
 
-func NewDashboardHandler() *DashboardHandler {
-	return &DashboardHandler{}
+func NewDashboardHandler(goalAnalyticsService *service.GoalAnalyticsService) *DashboardHandler {
+	return &DashboardHandler{
+		goalAnalyticsService: goalAnalyticsService,
+	}
 }
 
 func (h *DashboardHandler) DashboardPage(w http.ResponseWriter, r *http.Request) {
-	ui.Render(w, r, pages.Dashboard())
+	user := ctxkeys.User(r.Context())
+
+	analytics, err := h.analytics(r)
+	if err != nil {
+		slog.Error("failed to compute goal analytics", "error", err, "user_id", user.ID)
+		http.Error(w, "Failed to load dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.Dashboard(analytics))
+}
+
+// ExportAnalytics downloads the dashboard numbers as JSON, a Pro feature like the goal export
+func (h *DashboardHandler) ExportAnalytics(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	subscription := ctxkeys.Subscription(r.Context())
+
+	if !subscription.HasFeature(model.FeatureExport) {
+		http.Error(w, "Upgrade to Pro to export your analytics", http.StatusForbidden)
+		return
+	}
+
+	analytics, err := h.analytics(r)
+	if err != nil {
+		slog.Error("failed to compute goal analytics", "error", err, "user_id", user.ID)
+		http.Error(w, "Failed to export analytics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=goal-analytics.json")
+
+	err = json.NewEncoder(w).Encode(analytics)
+	if err != nil {
+		slog.Error("failed to encode analytics", "error", err, "user_id", user.ID)
+	}
+}
+
+// analytics computes the workspace analytics in the user's timezone
+func (h *DashboardHandler) analytics(r *http.Request) (*model.GoalAnalytics, error) {
+	membership := ctxkeys.Membership(r.Context())
+
+	loc := time.UTC
+	if profile := ctxkeys.Profile(r.Context()); profile != nil {
+		loc = profile.Location()
+	}
+
+	return h.goalAnalyticsService.Analytics(membership.OrganizationID, time.Now(), loc)
 }
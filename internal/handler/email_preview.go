@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// EmailPreviewHandler renders every email template with sample data (development only)
+type EmailPreviewHandler struct {
+	emailService *service.EmailService
+}
+
+func NewEmailPreviewHandler(emailService *service.EmailService) *EmailPreviewHandler {
+	return &EmailPreviewHandler{
+		emailService: emailService,
+	}
+}
+
+func (h *EmailPreviewHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	names := h.emailService.EmailPreviewNames()
+
+	name := r.PathValue("name")
+	if name == "" {
+		name = names[0]
+	}
+
+	preview, err := h.emailService.EmailPreview(name)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailPreviewNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		slog.Error("failed to render email preview", "error", err, "name", name)
+		http.Error(w, "Failed to render email", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.DevEmailPreview(names, preview))
+}
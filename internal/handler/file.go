@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/storage"
+)
+
+type FileHandler struct {
+	storage *storage.LocalStorage
+}
+
+func NewFileHandler(storage *storage.LocalStorage) *FileHandler {
+	return &FileHandler{
+		storage: storage,
+	}
+}
+
+// Serve streams a file from local storage
+// Public files (avatars) are served to anyone and cacheable, private files
+// need a valid signed URL and are never cached
+func (h *FileHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	filePath := r.PathValue("path")
+	public := storage.IsPublic(filePath)
+
+	if !public {
+		err := h.storage.Verify(filePath, r.URL.Query().Get("expires"), r.URL.Query().Get("signature"))
+		if err != nil {
+			if errors.Is(err, storage.ErrURLExpired) {
+				http.Error(w, "Link expired", http.StatusGone)
+				return
+			}
+			slog.Warn("file signature validation failed", "error", err, "path", filePath)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
+	file, err := h.storage.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		slog.Error("failed to open file", "error", err, "path", filePath)
+		http.Error(w, "Failed to open file", http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			slog.Error("failed to close file", "error", closeErr)
+		}
+	}()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if public {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), file)
+}
@@ -27,13 +27,14 @@ func NewGoalHandler(goalService *service.GoalService) *GoalHandler {
 
 func (h *GoalHandler) GoalsPage(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	sortBy := r.URL.Query().Get("sort")
 	if sortBy == "" {
 		sortBy = "recent"
 	}
 
-	goals, err := h.goalService.Goals(user.ID, sortBy)
+	goals, err := h.goalService.Goals(membership.OrganizationID, sortBy)
 	if err != nil {
 		slog.Error("failed to get goals", "error", err, "user_id", user.ID)
 		http.Error(w, "Failed to load goals", http.StatusInternalServerError)
@@ -51,10 +52,11 @@ func (h *GoalHandler) GoalsPage(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) GoalDetailPage(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 
-	goal, entries, err := h.goalService.GoalWithEntries(user.ID, goalID)
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to get goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Goal not found", http.StatusNotFound)
@@ -66,6 +68,7 @@ func (h *GoalHandler) GoalDetailPage(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	title := r.FormValue("title")
 	description := r.FormValue("description")
@@ -81,7 +84,32 @@ func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.goalService.Create(user.ID, title, description)
+	targetSteps := model.DefaultGoalSteps
+	if value := r.FormValue("target_steps"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			parsed = -1 // Rejected by the service
+		}
+		targetSteps = parsed
+	}
+
+	cadence := r.FormValue("cadence")
+	if cadence == "" {
+		cadence = model.GoalCadenceNone
+	}
+
+	_, err := h.goalService.Create(membership.OrganizationID, user.ID, title, description, targetSteps, cadence)
+	if err == service.ErrInvalidGoalSteps || err == service.ErrInvalidGoalCadence {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: err.Error(),
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
 	if err == service.ErrGoalLimitReached {
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
 			Title:       "Upgrade Required",
@@ -110,7 +138,7 @@ func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 		sortBy = "recent"
 	}
 
-	goals, err := h.goalService.Goals(user.ID, sortBy)
+	goals, err := h.goalService.Goals(membership.OrganizationID, sortBy)
 	if err != nil {
 		slog.Error("failed to reload goals", "error", err, "user_id", user.ID)
 		goals = []*model.Goal{} // Fallback to empty list
@@ -129,17 +157,18 @@ func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) CompleteEntry(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 	stepStr := r.PathValue("step")
 
 	step, err := strconv.Atoi(stepStr)
-	if err != nil || step < 1 || step > 100 {
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
 		http.Error(w, "Invalid step number", http.StatusBadRequest)
 		return
 	}
 
-	err = h.goalService.CompleteEntry(user.ID, goalID, step)
+	err = h.goalService.CompleteEntry(membership.OrganizationID, user.ID, goalID, step)
 	if err == service.ErrInvalidStep {
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
 			Title:       "Error",
@@ -168,7 +197,7 @@ func (h *GoalHandler) CompleteEntry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, entries, err := h.goalService.GoalWithEntries(user.ID, goalID)
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to reload goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Failed to reload goal", http.StatusInternalServerError)
@@ -179,19 +208,19 @@ func (h *GoalHandler) CompleteEntry(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *GoalHandler) EntryDialog(w http.ResponseWriter, r *http.Request) {
-	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 	stepStr := r.PathValue("step")
 
 	step, err := strconv.Atoi(stepStr)
-	if err != nil || step < 1 || step > 100 {
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
 		http.Error(w, "Invalid step number", http.StatusBadRequest)
 		return
 	}
 
 	// Get goal for ownership check
-	goal, err := h.goalService.ByID(user.ID, goalID)
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
 	if err != nil {
 		http.Error(w, "Goal not found", http.StatusNotFound)
 		return
@@ -209,17 +238,25 @@ func (h *GoalHandler) EntryDialog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ui.Render(w, r, pages.GoalEntryDialog(goal, entry))
+	attachments, usage, err := h.attachmentsWithUsage(membership.OrganizationID, goalID, step)
+	if err != nil {
+		slog.Error("failed to get attachments", "error", err, "goal_id", goalID, "step", step)
+		http.Error(w, "Failed to get attachments", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalEntryDialog(goal, entry, attachments, usage))
 }
 
 func (h *GoalHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 	stepStr := r.PathValue("step")
 
 	step, err := strconv.Atoi(stepStr)
-	if err != nil || step < 1 || step > 100 {
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
 		http.Error(w, "Invalid step number", http.StatusBadRequest)
 		return
 	}
@@ -239,14 +276,14 @@ func (h *GoalHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 		completedAt = &parsed
 	}
 
-	err = h.goalService.UpdateEntry(user.ID, goalID, step, note, completedAt)
+	err = h.goalService.UpdateEntry(membership.OrganizationID, goalID, step, note, r.FormValue("note_shared") == "on", completedAt)
 	if err != nil {
 		slog.Error("failed to update entry", "error", err, "user_id", user.ID, "goal_id", goalID, "step", step)
 		http.Error(w, "Failed to update entry", http.StatusInternalServerError)
 		return
 	}
 
-	goal, entries, err := h.goalService.GoalWithEntries(user.ID, goalID)
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to reload goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Failed to reload goal", http.StatusInternalServerError)
@@ -265,17 +302,18 @@ func (h *GoalHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) UncompleteEntry(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 	stepStr := r.PathValue("step")
 
 	step, err := strconv.Atoi(stepStr)
-	if err != nil || step < 1 || step > 100 {
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
 		http.Error(w, "Invalid step number", http.StatusBadRequest)
 		return
 	}
 
-	err = h.goalService.UncompleteEntry(user.ID, goalID, step)
+	err = h.goalService.UncompleteEntry(membership.OrganizationID, goalID, step)
 	if err != nil {
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
 			Title:       "Error",
@@ -287,7 +325,7 @@ func (h *GoalHandler) UncompleteEntry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, entries, err := h.goalService.GoalWithEntries(user.ID, goalID)
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to reload goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Failed to reload goal", http.StatusInternalServerError)
@@ -306,6 +344,7 @@ func (h *GoalHandler) UncompleteEntry(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 	title := r.FormValue("title")
@@ -322,14 +361,19 @@ func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, err := h.goalService.ByID(user.ID, goalID)
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to get goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Goal not found", http.StatusNotFound)
 		return
 	}
 
-	err = h.goalService.Update(user.ID, goalID, title, description, goal.Status)
+	cadence := r.FormValue("cadence")
+	if cadence == "" {
+		cadence = goal.Cadence
+	}
+
+	err = h.goalService.Update(membership.OrganizationID, goalID, title, description, goal.Status, cadence)
 	if err != nil {
 		slog.Error("failed to update goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
@@ -342,7 +386,7 @@ func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, entries, err := h.goalService.GoalWithEntries(user.ID, goalID)
+	goal, entries, err := h.goalService.GoalWithEntries(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to reload goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Failed to reload goal", http.StatusInternalServerError)
@@ -361,10 +405,11 @@ func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 
-	err := h.goalService.Delete(user.ID, goalID)
+	err := h.goalService.Delete(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to delete goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		ui.RenderOOB(w, r, toast.Toast(toast.Props{
@@ -383,10 +428,11 @@ func (h *GoalHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) EditDialog(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 
-	goal, err := h.goalService.ByID(user.ID, goalID)
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to get goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Goal not found", http.StatusNotFound)
@@ -398,10 +444,11 @@ func (h *GoalHandler) EditDialog(w http.ResponseWriter, r *http.Request) {
 
 func (h *GoalHandler) DeleteDialog(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 
 	goalID := r.PathValue("id")
 
-	goal, err := h.goalService.ByID(user.ID, goalID)
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
 	if err != nil {
 		slog.Error("failed to get goal", "error", err, "user_id", user.ID, "goal_id", goalID)
 		http.Error(w, "Goal not found", http.StatusNotFound)
@@ -411,8 +458,10 @@ func (h *GoalHandler) DeleteDialog(w http.ResponseWriter, r *http.Request) {
 	ui.Render(w, r, pages.GoalDeleteDialog(goal))
 }
 
+// Export downloads all goals with their entries as JSON (importable), CSV or Markdown
 func (h *GoalHandler) Export(w http.ResponseWriter, r *http.Request) {
 	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
 	subscription := ctxkeys.Subscription(r.Context())
 
 	if !subscription.HasFeature(model.FeatureExport) {
@@ -420,20 +469,71 @@ func (h *GoalHandler) Export(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goals, err := h.goalService.Goals(user.ID, "")
+	export, err := h.goalService.ExportGoals(membership.OrganizationID)
 	if err != nil {
-		slog.Error("failed to list goals for export", "error", err, "user_id", user.ID)
+		slog.Error("failed to export goals", "error", err, "user_id", user.ID)
 		http.Error(w, "Failed to export goals", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Disposition", "attachment; filename=goals-export.json")
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=goals-export.csv")
+		err = writeGoalExportCSV(w, export)
+	case "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=goals-export.md")
+		err = writeGoalExportMarkdown(w, export)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", "attachment; filename=goals-export.json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(export)
+	}
+	if err != nil {
+		slog.Error("failed to write goal export", "error", err, "user_id", user.ID)
+	}
+}
 
-	err = json.NewEncoder(w).Encode(goals)
+// Import creates goals from a JSON export and shows what happened to each one
+func (h *GoalHandler) Import(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, goalImportMaxSize)
+	err := r.ParseMultipartForm(goalImportMaxSize)
 	if err != nil {
-		slog.Error("failed to encode goals", "error", err, "user_id", user.ID)
-		http.Error(w, "Failed to export goals", http.StatusInternalServerError)
+		ui.Render(w, r, pages.GoalImportResults(nil, "The file is too large, exports can be up to 10MB"))
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		ui.Render(w, r, pages.GoalImportResults(nil, "Choose an export file to import"))
+		return
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			slog.Error("failed to close file", "error", closeErr)
+		}
+	}()
+
+	export, err := service.ParseGoalExport(file)
+	if err != nil {
+		ui.Render(w, r, pages.GoalImportResults(nil, err.Error()))
 		return
 	}
+
+	results, err := h.goalService.ImportGoals(membership.OrganizationID, user.ID, export)
+	if err != nil {
+		slog.Error("failed to import goals", "error", err, "user_id", user.ID)
+		ui.Render(w, r, pages.GoalImportResults(results, "The import stopped because of an error, goals listed as imported were saved"))
+		return
+	}
+
+	slog.Info("goals imported", "user_id", user.ID, "organization_id", membership.OrganizationID, "goals", len(results))
+	ui.Render(w, r, pages.GoalImportResults(results, ""))
 }
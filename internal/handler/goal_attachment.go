@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+	"github.com/templui/goilerplate/internal/validation"
+)
+
+// attachmentMaxSize is the largest upload accepted, PDFs up to validation.DocumentConstraints
+const attachmentMaxSize = 10 << 20
+
+func (h *GoalHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+	step, err := strconv.Atoi(r.PathValue("step"))
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
+		http.Error(w, "Invalid step number", http.StatusBadRequest)
+		return
+	}
+
+	// Multipart overhead on top of the file itself
+	r.Body = http.MaxBytesReader(w, r.Body, attachmentMaxSize+(1<<20))
+	err = r.ParseMultipartForm(attachmentMaxSize)
+	if err != nil {
+		h.renderAttachmentError(w, r, goalID, step, "File is too large, PDFs can be up to 10MB")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.renderAttachmentError(w, r, goalID, step, "No file uploaded")
+		return
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			slog.Error("failed to close file", "error", closeErr)
+		}
+	}()
+
+	err = validation.ValidateFile(header, validation.ImageConstraints, validation.DocumentConstraints)
+	if err != nil {
+		h.renderAttachmentError(w, r, goalID, step, err.Error())
+		return
+	}
+
+	_, err = h.goalService.AddAttachment(membership.OrganizationID, user.ID, goalID, step, file, header)
+	if errors.Is(err, service.ErrEntryNotCompleted) {
+		// The step was reopened meanwhile, its attachments are gone with the section
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Attachments can only be added to completed steps",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+	if errors.Is(err, service.ErrTooManyAttachments) || errors.Is(err, service.ErrStorageQuotaExceeded) {
+		h.renderAttachmentError(w, r, goalID, step, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to upload attachment", "error", err, "user_id", user.ID, "goal_id", goalID, "step", step)
+		h.renderAttachmentError(w, r, goalID, step, "Failed to upload attachment")
+		return
+	}
+
+	h.renderAttachments(w, r, goalID, step)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Attachment uploaded successfully",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+func (h *GoalHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+	step, err := strconv.Atoi(r.PathValue("step"))
+	if err != nil || step < 1 || step > model.MaxGoalSteps {
+		http.Error(w, "Invalid step number", http.StatusBadRequest)
+		return
+	}
+
+	err = h.goalService.DeleteAttachment(membership.OrganizationID, goalID, step, r.PathValue("fileID"))
+	if errors.Is(err, service.ErrAttachmentNotFound) {
+		h.renderAttachmentError(w, r, goalID, step, "Attachment not found")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete attachment", "error", err, "user_id", user.ID, "goal_id", goalID, "step", step)
+		h.renderAttachmentError(w, r, goalID, step, "Failed to delete attachment")
+		return
+	}
+
+	h.renderAttachments(w, r, goalID, step)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Attachment removed",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+// renderAttachments renders the attachments section of the entry dialog
+func (h *GoalHandler) renderAttachments(w http.ResponseWriter, r *http.Request, goalID string, step int) {
+	membership := ctxkeys.Membership(r.Context())
+
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
+	if err != nil {
+		http.Error(w, "Goal not found", http.StatusNotFound)
+		return
+	}
+
+	entry, err := h.goalService.EntryByGoalAndStep(goalID, step)
+	if err != nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	attachments, usage, err := h.attachmentsWithUsage(membership.OrganizationID, goalID, step)
+	if err != nil {
+		slog.Error("failed to get attachments", "error", err, "goal_id", goalID, "step", step)
+		http.Error(w, "Failed to get attachments", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalEntryAttachments(goal, entry, attachments, usage))
+}
+
+// renderAttachmentError keeps the section in place, the form swaps it on every response
+func (h *GoalHandler) renderAttachmentError(w http.ResponseWriter, r *http.Request, goalID string, step int, message string) {
+	h.renderAttachments(w, r, goalID, step)
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Error",
+		Description: message,
+		Variant:     toast.VariantError,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+func (h *GoalHandler) attachmentsWithUsage(organizationID, goalID string, step int) ([]*model.Attachment, model.StorageUsage, error) {
+	attachments, err := h.goalService.Attachments(organizationID, goalID, step)
+	if err != nil {
+		return nil, model.StorageUsage{}, err
+	}
+
+	usage, err := h.goalService.StorageUsage(organizationID)
+	if err != nil {
+		return nil, model.StorageUsage{}, err
+	}
+
+	return attachments, usage, nil
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+// goalImportMaxSize fits 1000 goals with all entries and notes
+const goalImportMaxSize = 10 << 20
+
+// writeGoalExportCSV writes one row per entry, goal columns repeat on every row
+func writeGoalExportCSV(w io.Writer, export *model.GoalExport) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"goal_id", "goal_title", "goal_status", "cadence", "target_steps", "goal_created_at", "step", "completed", "completed_at", "note"})
+	for _, goal := range export.Goals {
+		for _, entry := range goal.Entries {
+			completedAt := ""
+			if entry.CompletedAt != nil {
+				completedAt = entry.CompletedAt.UTC().Format(time.RFC3339)
+			}
+			_ = writer.Write([]string{
+				goal.ID,
+				goal.Title,
+				goal.Status,
+				goal.Cadence,
+				strconv.Itoa(goal.TargetSteps),
+				goal.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.Itoa(entry.Step),
+				strconv.FormatBool(entry.Completed),
+				completedAt,
+				entry.Note,
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeGoalExportMarkdown writes a readable report, completed steps only
+func writeGoalExportMarkdown(w io.Writer, export *model.GoalExport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Goals\n\nExported %s\n", export.ExportedAt.Format("Jan 2, 2006"))
+
+	for _, goal := range export.Goals {
+		fmt.Fprintf(&b, "\n## %s\n\n", goal.Title)
+		if goal.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", goal.Description)
+		}
+		fmt.Fprintf(&b, "- Progress: %d/%d steps (%s)\n", goal.CurrentStep, goal.TargetSteps, goal.Status)
+		fmt.Fprintf(&b, "- Cadence: %s\n", goal.Cadence)
+		fmt.Fprintf(&b, "- Started: %s\n", goal.CreatedAt.Format("Jan 2, 2006"))
+
+		if goal.CurrentStep == 0 {
+			continue
+		}
+		b.WriteString("\n| Step | Completed | Note |\n| --- | --- | --- |\n")
+		for _, entry := range goal.Entries {
+			if !entry.Completed {
+				continue
+			}
+			completedAt := ""
+			if entry.CompletedAt != nil {
+				completedAt = entry.CompletedAt.Format("Jan 2, 2006")
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", entry.Step, completedAt, markdownCell(entry.Note))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell keeps a note on one table row
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+func (h *GoalHandler) ShareDialog(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+
+	goal, err := h.goalService.ByID(membership.OrganizationID, goalID)
+	if err != nil {
+		slog.Error("failed to get goal", "error", err, "user_id", user.ID, "goal_id", goalID)
+		http.Error(w, "Goal not found", http.StatusNotFound)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalShareDialog(goal))
+}
+
+// Share creates the share link of a goal
+func (h *GoalHandler) Share(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+
+	goal, err := h.goalService.Share(membership.OrganizationID, goalID)
+	if err != nil {
+		slog.Error("failed to share goal", "error", err, "user_id", user.ID, "goal_id", goalID)
+		http.Error(w, "Failed to share goal", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalShareSettings(goal))
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Anyone with the link can now see this goal",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+// UpdateShare switches search engine indexing of a shared goal
+func (h *GoalHandler) UpdateShare(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+
+	goal, err := h.goalService.SetShareIndexable(membership.OrganizationID, goalID, r.FormValue("indexable") == "on")
+	if err != nil {
+		slog.Error("failed to update share settings", "error", err, "user_id", user.ID, "goal_id", goalID)
+		http.Error(w, "Failed to update share settings", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalShareSettings(goal))
+}
+
+// Unshare revokes the share link of a goal
+func (h *GoalHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	membership := ctxkeys.Membership(r.Context())
+
+	goalID := r.PathValue("id")
+
+	goal, err := h.goalService.Unshare(membership.OrganizationID, goalID)
+	if err != nil {
+		slog.Error("failed to revoke share link", "error", err, "user_id", user.ID, "goal_id", goalID)
+		http.Error(w, "Failed to revoke share link", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.GoalShareSettings(goal))
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Share link revoked",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
+
+// SharedGoalPage is the public read-only page of a shared goal
+func (h *GoalHandler) SharedGoalPage(w http.ResponseWriter, r *http.Request) {
+	goal, notes, ok := h.sharedGoal(w, r)
+	if !ok {
+		return
+	}
+
+	if !goal.ShareIndexable {
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	}
+	ui.Render(w, r, pages.SharedGoal(goal, notes))
+}
+
+// SharedGoalImage is the Open Graph image of a shared goal
+func (h *GoalHandler) SharedGoalImage(w http.ResponseWriter, r *http.Request) {
+	goal, _, ok := h.sharedGoal(w, r)
+	if !ok {
+		return
+	}
+
+	image, err := service.ShareImage(goal)
+	if err != nil {
+		slog.Error("failed to render share image", "error", err, "goal_id", goal.ID)
+		http.Error(w, "Failed to render image", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+	// Short enough that previews catch up with progress, revoked links stop working soon
+	w.Header().Set("Cache-Control", "public, max-age=600")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	_, _ = w.Write(image)
+}
+
+// sharedGoal loads the goal of the slug in the path, unknown and revoked links are a 404
+func (h *GoalHandler) sharedGoal(w http.ResponseWriter, r *http.Request) (*model.Goal, []*model.GoalEntry, bool) {
+	goal, notes, err := h.goalService.SharedGoal(r.PathValue("slug"))
+	if errors.Is(err, repository.ErrGoalNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		ui.Render(w, r, pages.NotFound())
+		return nil, nil, false
+	}
+	if err != nil {
+		slog.Error("failed to get shared goal", "error", err)
+		http.Error(w, "Failed to load goal", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return goal, notes, true
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/mail"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// MailboxHandler shows emails written by the outbox driver (development only)
+type MailboxHandler struct {
+	outbox *mail.OutboxTransport
+}
+
+func NewMailboxHandler(outbox *mail.OutboxTransport) *MailboxHandler {
+	return &MailboxHandler{
+		outbox: outbox,
+	}
+}
+
+func (h *MailboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	messages, err := h.outbox.Messages()
+	if err != nil {
+		slog.Error("failed to read outbox", "error", err)
+		http.Error(w, "Failed to read outbox", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.DevMailbox(messages))
+}
+
+func (h *MailboxHandler) Show(w http.ResponseWriter, r *http.Request) {
+	message, err := h.outbox.Message(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, mail.ErrMessageNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		slog.Error("failed to read outbox message", "error", err)
+		http.Error(w, "Failed to read message", http.StatusInternalServerError)
+		return
+	}
+
+	ui.Render(w, r, pages.DevMailboxMessage(message))
+}
+
+func (h *MailboxHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	err := h.outbox.Clear()
+	if err != nil {
+		slog.Error("failed to clear outbox", "error", err)
+		http.Error(w, "Failed to clear outbox", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/dev/mailbox", http.StatusSeeOther)
+}
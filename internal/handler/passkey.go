@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// PasskeyHandler serves both WebAuthn ceremonies:
+// registration from the settings page and discoverable sign-in from /auth
+type PasskeyHandler struct {
+	passkeyService *service.PasskeyService
+	authService    *service.AuthService
+}
+
+func NewPasskeyHandler(passkeyService *service.PasskeyService, authService *service.AuthService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+		authService:    authService,
+	}
+}
+
+// RegistrationOptions returns the options for navigator.credentials.create
+func (h *PasskeyHandler) RegistrationOptions(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	options, ceremony, err := h.passkeyService.BeginRegistration(user.ID)
+	if errors.Is(err, service.ErrPasskeyLimitReached) {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to begin passkey registration", "error", err, "user_id", user.ID)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to start passkey registration")
+		return
+	}
+
+	h.passkeyService.SetCeremonyCookie(w, service.PasskeyRegistrationCookieName, ceremony)
+	writeOptions(w, options)
+}
+
+// Register stores the credential created by the browser
+// Called with htmx.ajax, so it answers like the other settings forms (toast + fragment)
+func (h *PasskeyHandler) Register(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	ceremony := ""
+	cookie, err := r.Cookie(service.PasskeyRegistrationCookieName)
+	if err == nil {
+		ceremony = cookie.Value
+	}
+	h.passkeyService.ClearCeremonyCookie(w, service.PasskeyRegistrationCookieName)
+
+	_, err = h.passkeyService.FinishRegistration(user.ID, r.FormValue("name"), ceremony, []byte(r.FormValue("credential")))
+	if err != nil {
+		errMsg := "Failed to add passkey"
+		switch {
+		case errors.Is(err, service.ErrPasskeyNameTooLong),
+			errors.Is(err, service.ErrPasskeyLimitReached),
+			errors.Is(err, service.ErrPasskeyAlreadyRegistered),
+			errors.Is(err, service.ErrInvalidPasskeyCeremony):
+			errMsg = err.Error()
+		case errors.Is(err, service.ErrPasskeyVerificationFailed):
+			slog.Warn("passkey registration failed", "error", err, "user_id", user.ID)
+			errMsg = "The passkey could not be verified. Please try again."
+		default:
+			slog.Error("failed to register passkey", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Passkey added",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderPasskeys(w, r)
+}
+
+// Rename takes the new name from the hx-prompt dialog
+func (h *PasskeyHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	passkeyID := r.PathValue("id")
+
+	err := h.passkeyService.Rename(user.ID, passkeyID, r.Header.Get("HX-Prompt"))
+	if err != nil {
+		errMsg := "Failed to rename passkey"
+		if errors.Is(err, service.ErrPasskeyNameTooLong) {
+			errMsg = err.Error()
+		} else {
+			slog.Warn("rename passkey failed", "error", err, "user_id", user.ID, "passkey_id", passkeyID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	h.renderPasskeys(w, r)
+}
+
+func (h *PasskeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	passkeyID := r.PathValue("id")
+
+	err := h.passkeyService.Delete(user.ID, passkeyID)
+	if err != nil {
+		slog.Warn("delete passkey failed", "error", err, "user_id", user.ID, "passkey_id", passkeyID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to remove passkey",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Passkey removed",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderPasskeys(w, r)
+}
+
+// LoginOptions returns the options for navigator.credentials.get
+func (h *PasskeyHandler) LoginOptions(w http.ResponseWriter, r *http.Request) {
+	options, ceremony, err := h.passkeyService.BeginLogin()
+	if err != nil {
+		slog.Error("failed to begin passkey login", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to start passkey sign in")
+		return
+	}
+
+	h.passkeyService.SetCeremonyCookie(w, service.PasskeyLoginCookieName, ceremony)
+	writeOptions(w, options)
+}
+
+// Login verifies the assertion posted by the /auth page and starts a session
+// Passkeys satisfy two-factor on their own, so there is no /auth/2fa step
+func (h *PasskeyHandler) Login(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(service.PasskeyLoginCookieName)
+	if err != nil {
+		ui.Render(w, r, pages.Auth("Your passkey sign-in expired. Please try again."))
+		return
+	}
+	h.passkeyService.ClearCeremonyCookie(w, service.PasskeyLoginCookieName)
+
+	user, err := h.passkeyService.FinishLogin(cookie.Value, []byte(r.FormValue("credential")))
+	if err != nil {
+		slog.Warn("passkey login failed", "error", err)
+		errMsg := "Passkey sign-in failed. Please try again or use your email."
+		if errors.Is(err, service.ErrInvalidPasskeyCeremony) {
+			errMsg = "Your passkey sign-in expired. Please try again."
+		}
+		ui.Render(w, r, pages.Auth(errMsg))
+		return
+	}
+
+	err = h.authService.StartSession(w, user, "passkey", middleware.AuditContext(r))
+	if err != nil {
+		slog.Error("failed to start session", "error", err, "user_id", user.ID)
+		ui.Render(w, r, pages.Auth("An error occurred. Please try again."))
+		return
+	}
+
+	slog.Info("user logged in with passkey", "user_id", user.ID, "email", user.Email)
+	http.Redirect(w, r, "/app/dashboard", http.StatusSeeOther)
+}
+
+func (h *PasskeyHandler) renderPasskeys(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	passkeys, err := h.passkeyService.Passkeys(user.ID)
+	if err != nil {
+		slog.Error("failed to load passkeys", "error", err, "user_id", user.ID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.SettingsPasskeysSection(passkeys), "settings-passkeys")
+}
+
+// writeOptions sends ceremony options that are already JSON encoded
+func writeOptions(w http.ResponseWriter, options []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(options)
+	if err != nil {
+		slog.Error("failed to write passkey options", "error", err)
+	}
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/templui/goilerplate/internal/ctxkeys"
@@ -68,3 +70,54 @@ func (h *ProfileHandler) UpdateName(w http.ResponseWriter, r *http.Request) {
 		ui.Render(w, r, layouts.AppSidebarDropdown(user, profile))
 	}
 }
+
+func (h *ProfileHandler) UpdateNotifications(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	// Invalid numbers fall through to -1 and are rejected by the service
+	atoi := func(key string) int {
+		value, err := strconv.Atoi(r.FormValue(key))
+		if err != nil {
+			return -1
+		}
+		return value
+	}
+
+	settings := service.NotificationSettings{
+		Timezone:             r.FormValue("timezone"),
+		RemindersEnabled:     r.FormValue("reminders_enabled") == "on",
+		ReminderHour:         atoi("reminder_hour"),
+		ReminderInactiveDays: atoi("reminder_inactive_days"),
+		DigestEnabled:        r.FormValue("digest_enabled") == "on",
+		DigestWeekday:        atoi("digest_weekday"),
+	}
+
+	err := h.profileService.UpdateNotificationSettings(user.ID, settings)
+	if err != nil {
+		slog.Warn("failed to update notification settings", "error", err, "user_id", user.ID)
+
+		errMsg := "Failed to update notification settings"
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			errMsg = "Unknown timezone, use a name like Europe/Berlin"
+		} else if errors.Is(err, service.ErrInvalidReminderSettings) {
+			errMsg = "Invalid reminder settings"
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Notification settings saved",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+}
@@ -1,17 +1,32 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
 
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/service"
 	"github.com/templui/goilerplate/internal/ui"
 	"github.com/templui/goilerplate/internal/ui/components/toast"
 	"github.com/templui/goilerplate/internal/ui/pages"
 )
 
-type SettingsHandler struct{}
+type SettingsHandler struct {
+	authService     *service.AuthService
+	passkeyService  *service.PasskeyService
+	apiTokenService *service.APITokenService
+	webhookService  *service.WebhookService
+	auditService    *service.AuditService
+}
 
-func NewSettingsHandler() *SettingsHandler {
-	return &SettingsHandler{}
+func NewSettingsHandler(authService *service.AuthService, passkeyService *service.PasskeyService, apiTokenService *service.APITokenService, webhookService *service.WebhookService, auditService *service.AuditService) *SettingsHandler {
+	return &SettingsHandler{
+		authService:     authService,
+		passkeyService:  passkeyService,
+		apiTokenService: apiTokenService,
+		webhookService:  webhookService,
+		auditService:    auditService,
+	}
 }
 
 func (h *SettingsHandler) SettingsPage(w http.ResponseWriter, r *http.Request) {
@@ -27,5 +42,73 @@ func (h *SettingsHandler) SettingsPage(w http.ResponseWriter, r *http.Request) {
 		}), "beforeend:#toast-container")
 	}
 
-	ui.Render(w, r, pages.Settings())
+	if provider := r.URL.Query().Get("identity_linked"); provider != "" {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Success",
+			Description: identityProviderName(r, provider) + " connected",
+			Variant:     toast.VariantSuccess,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+	}
+	if code := r.URL.Query().Get("identity_error"); code != "" {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: identityErrorMessage(code),
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+	}
+
+	user := ctxkeys.User(r.Context())
+
+	sessions, err := h.authService.Sessions(user.ID)
+	if err != nil {
+		slog.Error("failed to load sessions", "error", err, "user_id", user.ID)
+	}
+
+	passkeys, err := h.passkeyService.Passkeys(user.ID)
+	if err != nil {
+		slog.Error("failed to load passkeys", "error", err, "user_id", user.ID)
+	}
+
+	identities, err := h.authService.Identities(user.ID)
+	if err != nil {
+		slog.Error("failed to load identities", "error", err, "user_id", user.ID)
+	}
+
+	apiTokens, err := h.apiTokenService.Tokens(user.ID)
+	if err != nil {
+		slog.Error("failed to load api tokens", "error", err, "user_id", user.ID)
+	}
+
+	webhookEndpoints, err := h.webhookService.Endpoints(user.ID)
+	if err != nil {
+		slog.Error("failed to load webhook endpoints", "error", err, "user_id", user.ID)
+	}
+
+	webhooksAvailable, err := h.webhookService.Available(user.ID)
+	if err != nil {
+		slog.Error("failed to check webhook availability", "error", err, "user_id", user.ID)
+	}
+
+	auditEvents, err := h.auditService.UserEvents(user.ID)
+	if err != nil {
+		slog.Error("failed to load audit events", "error", err, "user_id", user.ID)
+	}
+
+	ui.Render(w, r, pages.Settings(sessions, passkeys, identities, apiTokens, webhookEndpoints, webhooksAvailable, auditEvents))
+}
+
+// identityErrorMessage maps the identity_error codes set by the OAuth link callback
+func identityErrorMessage(code string) string {
+	switch code {
+	case "already_linked":
+		return service.ErrIdentityAlreadyLinked.Error()
+	case "other_user":
+		return service.ErrIdentityLinkedToOtherUser.Error()
+	default:
+		return "Failed to connect account. Please try again."
+	}
 }
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+type UnsubscribeHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewUnsubscribeHandler(notificationService *service.NotificationService) *UnsubscribeHandler {
+	return &UnsubscribeHandler{
+		notificationService: notificationService,
+	}
+}
+
+// Unsubscribe handles the link in reminder and digest emails (GET)
+// and the one-click unsubscribe button of mail clients (POST, RFC 8058)
+// The signed token identifies the user, no login required
+func (h *UnsubscribeHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	kind, err := h.notificationService.Unsubscribe(r.PathValue("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidUnsubscribeToken) {
+			slog.Error("unsubscribe failed", "error", err)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		if r.Method == http.MethodGet {
+			ui.Render(w, r, pages.UnsubscribeError())
+		}
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ui.Render(w, r, pages.Unsubscribed(kind))
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// WebhookHandler manages outbound webhook endpoints and shows their delivery log
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) DetailPage(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	endpointID := r.PathValue("id")
+
+	endpoint, err := h.webhookService.Endpoint(user.ID, endpointID)
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	deliveries, err := h.webhookService.Deliveries(user.ID, endpointID)
+	if err != nil {
+		slog.Error("failed to load webhook deliveries", "error", err, "user_id", user.ID, "endpoint_id", endpointID)
+	}
+
+	ui.Render(w, r, pages.WebhookDetail(endpoint, deliveries))
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.webhookService.CreateEndpoint(user.ID, r.FormValue("url"), r.FormValue("description"), r.Form["events"])
+	if err != nil {
+		errMsg := "Failed to add endpoint"
+		if errors.Is(err, service.ErrWebhooksNotAvailable) ||
+			errors.Is(err, service.ErrInvalidWebhookURL) ||
+			errors.Is(err, service.ErrInvalidWebhookEvents) ||
+			errors.Is(err, service.ErrWebhookLimitReached) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to create webhook endpoint", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	endpoints, err := h.webhookService.Endpoints(user.ID)
+	if err != nil {
+		slog.Error("failed to load webhook endpoints", "error", err, "user_id", user.ID)
+	}
+
+	// Creating an endpoint only succeeds when webhooks are available
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Endpoint added",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	ui.RenderFragment(w, r, pages.SettingsWebhooksSection(endpoints, true), "settings-webhooks")
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	endpointID := r.PathValue("id")
+
+	err := h.webhookService.DeleteEndpoint(user.ID, endpointID)
+	if err != nil {
+		slog.Warn("delete webhook endpoint failed", "error", err, "user_id", user.ID, "endpoint_id", endpointID)
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Failed to delete endpoint",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/app/settings")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	endpointID := r.PathValue("id")
+
+	err := h.webhookService.SendTest(user.ID, endpointID)
+	if err != nil {
+		errMsg := "Failed to send test event"
+		if errors.Is(err, service.ErrWebhooksNotAvailable) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to send test webhook", "error", err, "user_id", user.ID, "endpoint_id", endpointID)
+		}
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Test event queued, refresh the log to see the result",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.Deliveries(w, r)
+}
+
+// Deliveries re-renders the delivery log
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	endpointID := r.PathValue("id")
+
+	deliveries, err := h.webhookService.Deliveries(user.ID, endpointID)
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	ui.Render(w, r, pages.WebhookDeliveryLog(deliveries))
+}
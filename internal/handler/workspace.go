@@ -0,0 +1,477 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
+	"github.com/templui/goilerplate/internal/ui/pages"
+)
+
+// WorkspaceHandler manages workspaces, their members and invitations
+type WorkspaceHandler struct {
+	organizationService *service.OrganizationService
+}
+
+func NewWorkspaceHandler(organizationService *service.OrganizationService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		organizationService: organizationService,
+	}
+}
+
+// WorkspacesPage lists the user's workspaces and the invitations waiting for them
+func (h *WorkspaceHandler) WorkspacesPage(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	invitations, err := h.organizationService.PendingInvitations(user.Email)
+	if err != nil {
+		slog.Error("failed to load invitations", "error", err, "user_id", user.ID)
+	}
+
+	ui.Render(w, r, pages.Workspaces(invitations))
+}
+
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	organization, err := h.organizationService.CreateWorkspace(user.ID, r.FormValue("name"))
+	if err != nil {
+		errMsg := "Failed to create workspace"
+		if errors.Is(err, service.ErrWorkspaceNameRequired) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to create workspace", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	setWorkspaceCookie(w, r, organization.ID)
+	w.Header().Set("HX-Redirect", "/app/workspace")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Switch makes another of the user's workspaces the active one
+func (h *WorkspaceHandler) Switch(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+	organizationID := r.PathValue("id")
+
+	_, err := h.organizationService.Membership(organizationID, user.ID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrMembershipNotFound) {
+			slog.Error("failed to load membership", "error", err, "user_id", user.ID, "organization_id", organizationID)
+		}
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Workspace not found",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	setWorkspaceCookie(w, r, organizationID)
+	w.Header().Set("HX-Redirect", "/app/dashboard")
+	w.WriteHeader(http.StatusOK)
+}
+
+// WorkspacePage shows the active workspace with its members and invitations
+func (h *WorkspaceHandler) WorkspacePage(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	members, err := h.organizationService.Members(membership.OrganizationID)
+	if err != nil {
+		slog.Error("failed to load members", "error", err, "organization_id", membership.OrganizationID)
+		http.Error(w, "Failed to load workspace", http.StatusInternalServerError)
+		return
+	}
+
+	invitations, err := h.organizationService.Invitations(membership.OrganizationID)
+	if err != nil {
+		slog.Error("failed to load invitations", "error", err, "organization_id", membership.OrganizationID)
+	}
+
+	ui.Render(w, r, pages.Workspace(members, invitations))
+}
+
+func (h *WorkspaceHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	err := h.organizationService.Rename(membership, r.FormValue("name"))
+	if err != nil {
+		errMsg := "Failed to rename workspace"
+		if errors.Is(err, service.ErrWorkspaceNameRequired) || errors.Is(err, service.ErrWorkspacePermission) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to rename workspace", "error", err, "organization_id", membership.OrganizationID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	// Full reload so the switcher shows the new name
+	w.Header().Set("HX-Redirect", "/app/workspace")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WorkspaceHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+	profile := ctxkeys.Profile(r.Context())
+
+	_, err := h.organizationService.Invite(membership, profile.Name, r.FormValue("email"), r.FormValue("role"))
+	if err != nil {
+		errMsg := "Failed to send invitation"
+		if errors.Is(err, service.ErrWorkspacePermission) ||
+			errors.Is(err, service.ErrPersonalWorkspace) ||
+			errors.Is(err, service.ErrInvalidRole) ||
+			errors.Is(err, service.ErrInvalidEmail) ||
+			errors.Is(err, service.ErrAlreadyMember) ||
+			errors.Is(err, service.ErrInvitationExists) ||
+			errors.Is(err, service.ErrNoSeatsAvailable) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to invite member", "error", err, "organization_id", membership.OrganizationID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Invitation sent",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderInvitations(w, r)
+}
+
+func (h *WorkspaceHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+	invitationID := r.PathValue("id")
+
+	err := h.organizationService.RevokeInvitation(membership, invitationID)
+	if err != nil {
+		errMsg := "Failed to revoke invitation"
+		if errors.Is(err, service.ErrWorkspacePermission) {
+			errMsg = err.Error()
+		} else {
+			slog.Warn("revoke invitation failed", "error", err, "organization_id", membership.OrganizationID, "invitation_id", invitationID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Invitation revoked",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderInvitations(w, r)
+}
+
+func (h *WorkspaceHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+	memberID := r.PathValue("id")
+
+	err := h.organizationService.ChangeRole(membership, memberID, r.FormValue("role"))
+	if err != nil {
+		errMsg := "Failed to change role"
+		if errors.Is(err, service.ErrWorkspacePermission) ||
+			errors.Is(err, service.ErrInvalidRole) ||
+			errors.Is(err, service.ErrLastOwner) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to change role", "error", err, "organization_id", membership.OrganizationID, "membership_id", memberID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		h.renderMembers(w, r)
+		return
+	}
+
+	// Changing your own role changes what the page offers
+	if memberID == membership.ID {
+		w.Header().Set("HX-Redirect", "/app/workspace")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Role updated",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderMembers(w, r)
+}
+
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+	memberID := r.PathValue("id")
+
+	if memberID == membership.ID {
+		h.Leave(w, r)
+		return
+	}
+
+	err := h.organizationService.RemoveMember(membership, memberID)
+	if err != nil {
+		errMsg := "Failed to remove member"
+		if errors.Is(err, service.ErrWorkspacePermission) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to remove member", "error", err, "organization_id", membership.OrganizationID, "membership_id", memberID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	ui.RenderOOB(w, r, toast.Toast(toast.Props{
+		Title:       "Success",
+		Description: "Member removed",
+		Variant:     toast.VariantSuccess,
+		Icon:        true,
+		Dismissible: true,
+	}), "beforeend:#toast-container")
+	h.renderMembers(w, r)
+}
+
+func (h *WorkspaceHandler) Leave(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	err := h.organizationService.Leave(membership)
+	if err != nil {
+		errMsg := "Failed to leave workspace"
+		if errors.Is(err, service.ErrPersonalWorkspace) || errors.Is(err, service.ErrLastOwner) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to leave workspace", "error", err, "organization_id", membership.OrganizationID, "user_id", membership.UserID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	clearWorkspaceCookie(w)
+	w.Header().Set("HX-Redirect", "/app/dashboard")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WorkspaceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	if r.FormValue("confirmation") != membership.OrganizationName {
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: "Type the workspace name to confirm",
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	err := h.organizationService.DeleteWorkspace(membership)
+	if err != nil {
+		errMsg := "Failed to delete workspace"
+		if errors.Is(err, service.ErrWorkspacePermission) ||
+			errors.Is(err, service.ErrPersonalWorkspace) ||
+			errors.Is(err, service.ErrWorkspaceHasSubscription) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to delete workspace", "error", err, "organization_id", membership.OrganizationID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	clearWorkspaceCookie(w)
+	w.Header().Set("HX-Redirect", "/app/dashboard")
+	w.WriteHeader(http.StatusOK)
+}
+
+// InvitationPage shows an invitation from the email link
+// Guests are asked to sign in with the invited email first.
+func (h *WorkspaceHandler) InvitationPage(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.organizationService.InvitationByToken(r.PathValue("token"))
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidInvitation) {
+			slog.Error("failed to load invitation", "error", err)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		ui.Render(w, r, pages.InvitationInvalid())
+		return
+	}
+
+	ui.Render(w, r, pages.Invitation(invitation))
+}
+
+func (h *WorkspaceHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	membership, err := h.organizationService.AcceptInvitation(user, r.PathValue("token"))
+	if err != nil {
+		errMsg := "Failed to accept invitation"
+		if errors.Is(err, service.ErrInvalidInvitation) || errors.Is(err, service.ErrInvitationEmailMismatch) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to accept invitation", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	setWorkspaceCookie(w, r, membership.OrganizationID)
+	w.Header().Set("HX-Redirect", "/app/dashboard")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WorkspaceHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	user := ctxkeys.User(r.Context())
+
+	err := h.organizationService.DeclineInvitation(user, r.PathValue("token"))
+	if err != nil {
+		errMsg := "Failed to decline invitation"
+		if errors.Is(err, service.ErrInvalidInvitation) || errors.Is(err, service.ErrInvitationEmailMismatch) {
+			errMsg = err.Error()
+		} else {
+			slog.Error("failed to decline invitation", "error", err, "user_id", user.ID)
+		}
+
+		ui.RenderOOB(w, r, toast.Toast(toast.Props{
+			Title:       "Error",
+			Description: errMsg,
+			Variant:     toast.VariantError,
+			Icon:        true,
+			Dismissible: true,
+		}), "beforeend:#toast-container")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/app/workspaces")
+	w.WriteHeader(http.StatusOK)
+}
+
+// renderMembers re-renders the member list of the active workspace
+func (h *WorkspaceHandler) renderMembers(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	members, err := h.organizationService.Members(membership.OrganizationID)
+	if err != nil {
+		slog.Error("failed to load members", "error", err, "organization_id", membership.OrganizationID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.WorkspaceMembersSection(members), "workspace-members")
+}
+
+// renderInvitations re-renders the pending invitations of the active workspace
+func (h *WorkspaceHandler) renderInvitations(w http.ResponseWriter, r *http.Request) {
+	membership := ctxkeys.Membership(r.Context())
+
+	invitations, err := h.organizationService.Invitations(membership.OrganizationID)
+	if err != nil {
+		slog.Error("failed to load invitations", "error", err, "organization_id", membership.OrganizationID)
+		return
+	}
+
+	ui.RenderFragment(w, r, pages.WorkspaceInvitationsSection(invitations), "workspace-invitations")
+}
+
+// setWorkspaceCookie remembers the active workspace, RequireWorkspace falls back to
+// the personal workspace when it points somewhere the user no longer belongs
+func setWorkspaceCookie(w http.ResponseWriter, r *http.Request, organizationID string) {
+	cfg := ctxkeys.Config(r.Context())
+	isProduction := cfg != nil && cfg.IsProduction()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.WorkspaceCookieName,
+		Value:    organizationID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   isProduction, // Secure flag based on APP_ENV (safer than r.TLS behind load balancers)
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   86400 * 365, // 1 year
+	})
+}
+
+func clearWorkspaceCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   middleware.WorkspaceCookieName,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
@@ -0,0 +1,15 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogTransport only logs emails, nothing is sent (development default)
+// The sender logs the email's link, the full body is logged at debug level.
+type LogTransport struct{}
+
+func (t *LogTransport) Send(ctx context.Context, msg *Message) error {
+	slog.Debug("email not sent (log driver)", "to", msg.To, "subject", msg.Subject, "body", msg.Text)
+	return nil
+}
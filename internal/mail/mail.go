@@ -0,0 +1,171 @@
+// Package mail delivers rendered emails through a configurable transport
+// (Resend API, SMTP, a local outbox directory, or the log).
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	cfg "github.com/templui/goilerplate/internal/config"
+)
+
+const (
+	DriverResend = "resend"
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+	DriverLog    = "log"
+)
+
+// Message is a rendered email ready to send
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string            // Optional, sent as multipart/alternative with Text as fallback
+	Headers map[string]string // Extra headers, e.g. List-Unsubscribe
+}
+
+// Transport sends emails
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the transport selected by EMAIL_DRIVER
+// resend: Resend API (default in production)
+// smtp: any SMTP server (STARTTLS, implicit TLS or plain)
+// outbox: .eml files on disk, browsable at /dev/mailbox in development
+// log: logs emails (default in development)
+func New(c *cfg.Config) (Transport, error) {
+	switch c.EmailDriver {
+	case DriverResend:
+		slog.Info("initializing resend email transport")
+		return NewResendTransport(c.ResendAPIKey)
+
+	case DriverSMTP:
+		slog.Info("initializing smtp email transport", "host", c.SMTPHost, "port", c.SMTPPort, "tls", c.SMTPTLS)
+		return NewSMTPTransport(SMTPConfig{
+			Host:     c.SMTPHost,
+			Port:     c.SMTPPort,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+			TLS:      c.SMTPTLS,
+		})
+
+	case DriverOutbox:
+		slog.Info("initializing outbox email transport", "path", c.EmailOutboxPath)
+		return NewOutboxTransport(c.EmailOutboxPath)
+
+	case DriverLog:
+		return &LogTransport{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown email driver: %s (supported: resend, smtp, outbox, log)", c.EmailDriver)
+	}
+}
+
+// Bytes renders the message in RFC 5322 format (used by SMTP and the outbox)
+func (m *Message) Bytes() ([]byte, error) {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		// Strip line breaks to prevent header injection
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	writeHeader("From", from.String())
+	writeHeader("To", m.To)
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID(from.Address))
+	writeHeader("MIME-Version", "1.0")
+
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		writeHeader(key, m.Headers[key])
+	}
+
+	if m.HTML == "" {
+		writeHeader("Content-Type", "text/plain; charset=utf-8")
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		err = writeQuotedPrintable(&buf, m.Text)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// Text first, clients show the last part they support
+	writer := multipart.NewWriter(&buf)
+	writeHeader("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = writeQuotedPrintable(partWriter, part.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	_, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	if err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID on the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 {
+		domain = from[at+1:]
+	}
+
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
@@ -0,0 +1,215 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMessageNotFound = errors.New("message not found")
+)
+
+// Outbox file names sort chronologically: 20060102-150405.000000-<random>.eml
+var outboxIDPattern = regexp.MustCompile(`^\d{8}-\d{6}\.\d{6}-[0-9a-f]{8}$`)
+
+// OutboxTransport writes emails as .eml files to a directory instead of sending them
+// Open them in any mail client or browse them at /dev/mailbox in development.
+type OutboxTransport struct {
+	root *os.Root
+}
+
+// OutboxMessage is a parsed email from the outbox
+type OutboxMessage struct {
+	ID      string
+	From    string
+	To      string
+	Subject string
+	Date    time.Time
+	Headers mail.Header
+	Text    string
+	HTML    string // Empty for plain-text emails
+}
+
+// NewOutboxTransport creates the outbox directory if it doesn't exist
+func NewOutboxTransport(path string) (*OutboxTransport, error) {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	root, err := os.OpenRoot(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox directory: %w", err)
+	}
+
+	return &OutboxTransport{root: root}, nil
+}
+
+func (t *OutboxTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	random := make([]byte, 4)
+	_, err = rand.Read(random)
+	if err != nil {
+		return err
+	}
+
+	id := time.Now().UTC().Format("20060102-150405.000000") + "-" + hex.EncodeToString(random)
+	return t.root.WriteFile(id+".eml", data, 0644)
+}
+
+// Messages returns all emails in the outbox, newest first
+func (t *OutboxTransport) Messages() ([]*OutboxMessage, error) {
+	entries, err := fs.ReadDir(t.root.FS(), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".eml")
+		if ok && outboxIDPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	slices.Reverse(ids)
+
+	messages := make([]*OutboxMessage, 0, len(ids))
+	for _, id := range ids {
+		msg, err := t.Message(id)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// Message reads and parses a single email
+func (t *OutboxTransport) Message(id string) (*OutboxMessage, error) {
+	if !outboxIDPattern.MatchString(id) {
+		return nil, ErrMessageNotFound
+	}
+
+	file, err := t.root.Open(id + ".eml")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	parsed, err := mail.ReadMessage(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message %s: %w", id, err)
+	}
+
+	text, html, err := readBody(textproto.MIMEHeader(parsed.Header), parsed.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message %s: %w", id, err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		subject = parsed.Header.Get("Subject")
+	}
+
+	date, _ := parsed.Header.Date()
+
+	return &OutboxMessage{
+		ID:      id,
+		From:    parsed.Header.Get("From"),
+		To:      parsed.Header.Get("To"),
+		Subject: subject,
+		Date:    date,
+		Headers: parsed.Header,
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// readBody returns the text and HTML parts of a plain or multipart/alternative body
+func readBody(header textproto.MIMEHeader, body io.Reader) (text, html string, err error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return text, html, nil
+			}
+			if err != nil {
+				return "", "", err
+			}
+
+			partText, partHTML, err := readBody(part.Header, part)
+			if err != nil {
+				return "", "", err
+			}
+			text += partText
+			html += partHTML
+		}
+	}
+
+	if strings.EqualFold(header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", err
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	if mediaType == "text/html" {
+		return "", content, nil
+	}
+	return content, "", nil
+}
+
+// Clear deletes all emails from the outbox
+func (t *OutboxTransport) Clear() error {
+	entries, err := fs.ReadDir(t.root.FS(), ".")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".eml") {
+			err := t.root.Remove(entry.Name())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *OutboxTransport) Close() error {
+	return t.root.Close()
+}
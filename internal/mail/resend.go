@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"errors"
+
+	"github.com/resend/resend-go/v2"
+)
+
+// ResendTransport sends emails through the Resend API
+type ResendTransport struct {
+	client *resend.Client
+}
+
+func NewResendTransport(apiKey string) (*ResendTransport, error) {
+	if apiKey == "" {
+		return nil, errors.New("resend transport requires RESEND_API_KEY")
+	}
+	return &ResendTransport{client: resend.NewClient(apiKey)}, nil
+}
+
+func (t *ResendTransport) Send(ctx context.Context, msg *Message) error {
+	params := &resend.SendEmailRequest{
+		From:    msg.From,
+		To:      []string{msg.To},
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		Html:    msg.HTML,
+		Headers: msg.Headers,
+	}
+
+	_, err := t.client.Emails.SendWithContext(ctx, params)
+	return err
+}
@@ -0,0 +1,161 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection (port 587)
+	SMTPTLSImplicit = "implicit" // TLS from the start (port 465)
+	SMTPTLSNone     = "none"     // Plain text, only for local mail catchers
+
+	smtpTimeout = 30 * time.Second
+)
+
+// SMTPTransport sends emails through an SMTP server
+type SMTPTransport struct {
+	host     string
+	port     string
+	username string
+	password string
+	tls      string
+}
+
+// SMTPConfig holds configuration for the SMTP transport
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // Optional, no AUTH when empty
+	Password string
+	TLS      string // starttls (default), implicit or none
+}
+
+func NewSMTPTransport(cfg SMTPConfig) (*SMTPTransport, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp transport requires SMTP_HOST")
+	}
+
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = SMTPTLSStartTLS
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP_TLS mode: %s (supported: starttls, implicit, none)", cfg.TLS)
+	}
+
+	return &SMTPTransport{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		username: cfg.Username,
+		password: cfg.Password,
+		tls:      cfg.TLS,
+	}, nil
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	client, err := t.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if t.tls == SMTPTLSStartTLS {
+		err = client.StartTLS(&tls.Config{ServerName: t.host})
+		if err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if t.username != "" {
+		err = client.Auth(smtp.PlainAuth("", t.username, t.password, t.host))
+		if err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	err = client.Mail(from.Address)
+	if err != nil {
+		return err
+	}
+
+	err = client.Rcpt(to.Address)
+	if err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the server, with TLS from the start for implicit mode
+// The whole conversation is bound to the context deadline (or smtpTimeout)
+func (t *SMTPTransport) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(t.host, t.port)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if t.tls == SMTPTLSImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: t.host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
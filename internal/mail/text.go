@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaceRun   = regexp.MustCompile(`[ \t\r\n]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText converts an HTML email into its plain-text alternative
+// Paragraphs become blank-line separated, list items get dashes and
+// links are written as "text (url)" so every link stays usable.
+func HTMLToText(source string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	writeText(&buf, doc)
+
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text := blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text) + "\n", nil
+}
+
+func writeText(buf *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(spaceRun.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+		if skipElement(n) {
+			return
+		}
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		buf.WriteString("\n")
+		return
+	case atom.Li:
+		buf.WriteString("\n- ")
+	case atom.Tr, atom.Div:
+		buf.WriteString("\n")
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.Ul, atom.Ol, atom.Table:
+		buf.WriteString("\n\n")
+	case atom.Td:
+		buf.WriteString(" ")
+	case atom.A:
+		writeLink(buf, n)
+		return
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeText(buf, child)
+	}
+
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.Ul, atom.Ol, atom.Table:
+		buf.WriteString("\n\n")
+	case atom.Tr, atom.Div:
+		buf.WriteString("\n")
+	}
+}
+
+// writeLink writes "text (url)", or just the text when it already is the url
+func writeLink(buf *strings.Builder, n *html.Node) {
+	var inner strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeText(&inner, child)
+	}
+	label := strings.TrimSpace(inner.String())
+	href := attr(n, "href")
+
+	switch {
+	case href == "" || href == label || strings.HasPrefix(href, "mailto:"):
+		buf.WriteString(label)
+	case label == "":
+		buf.WriteString(href)
+	default:
+		buf.WriteString(label + " (" + href + ")")
+	}
+}
+
+// skipElement drops invisible content (head, styles, hidden preheader)
+func skipElement(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Head, atom.Style, atom.Script, atom.Title:
+		return true
+	}
+	return strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/service"
+)
+
+// RequireAPIToken creates middleware for /api/v1 endpoints
+// Authenticates with a personal access token (Authorization: Bearer gp_...) and
+// checks its scope. Cookie sessions are ignored so the API can skip CSRF checks.
+// Tokens belong to users, so the API works on the user's personal workspace.
+func RequireAPIToken(scope string, apiTokenService *service.APITokenService, userService *service.UserService, profileService *service.ProfileService, organizationService *service.OrganizationService, subscriptionService *service.SubscriptionService) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			plain, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || plain == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+				return
+			}
+
+			token, err := apiTokenService.Authenticate(strings.TrimSpace(plain))
+			if err != nil {
+				if !errors.Is(err, service.ErrInvalidAPIToken) {
+					slog.Error("failed to authenticate api token", "error", err)
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+				writeAPIError(w, http.StatusUnauthorized, "invalid token")
+				return
+			}
+
+			if !token.Allows(scope) {
+				writeAPIError(w, http.StatusForbidden, "token does not have the "+scope+" scope")
+				return
+			}
+
+			user, err := userService.ByID(token.UserID)
+			if err != nil {
+				slog.Error("failed to load api token user", "error", err, "token_id", token.ID)
+				writeAPIError(w, http.StatusUnauthorized, "invalid token")
+				return
+			}
+
+			// Security: Remove password hash and TOTP secret from context
+			user.PasswordHash = nil
+			user.TOTPSecret = nil
+
+			profile, err := profileService.ByUserID(user.ID)
+			if err != nil {
+				slog.Error("failed to load api token profile", "error", err, "user_id", user.ID)
+				writeAPIError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			memberships, err := organizationService.Memberships(user.ID)
+			if err != nil || len(memberships) == 0 || !memberships[0].Personal {
+				slog.Error("failed to load api token workspace", "error", err, "user_id", user.ID)
+				writeAPIError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			membership := memberships[0] // Personal workspace sorts first
+
+			subscription, err := subscriptionService.Subscription(membership.OrganizationID)
+			if err != nil {
+				slog.Error("failed to load api token subscription", "error", err, "user_id", user.ID)
+				writeAPIError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			// Replace anything AuthMiddleware set from a cookie
+			ctx := ctxkeys.WithUser(r.Context(), user)
+			ctx = ctxkeys.WithSession(ctx, nil)
+			ctx = ctxkeys.WithProfile(ctx, profile)
+			ctx = ctxkeys.WithMembership(ctx, membership)
+			ctx = ctxkeys.WithSubscription(ctx, subscription)
+			ctx = ctxkeys.WithAPIToken(ctx, token)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// writeAPIError writes the same {"error": "..."} body as the API handlers
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(map[string]string{"error": message})
+	if err != nil {
+		slog.Error("failed to write api error", "error", err)
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"net/http"
 
 	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/model"
 	"github.com/templui/goilerplate/internal/service"
+	"github.com/templui/goilerplate/internal/ui"
+	"github.com/templui/goilerplate/internal/ui/components/toast"
 )
 
 // AuthMiddleware checks for JWT token and adds user + profile + subscription to context if valid
+// The JWT's session must still exist server-side (sessions can be revoked from settings)
+// While an admin impersonates a user, the session is the admin's and the admin is added as impersonator
 func AuthMiddleware(authService *service.AuthService, userService *service.UserService, profileService *service.ProfileService, subscriptionService *service.SubscriptionService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -36,6 +41,40 @@ func AuthMiddleware(authService *service.AuthService, userService *service.UserS
 				return
 			}
 
+			// Check the session hasn't been revoked
+			sessionID, ok := claims["session_id"].(string)
+			if !ok {
+				authService.ClearJWTCookie(w)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Impersonation runs on the admin's session, see AuthService.StartImpersonation
+			sessionUserID := userID
+			impersonatorID, _ := claims["impersonator_id"].(string)
+			if impersonatorID != "" {
+				sessionUserID = impersonatorID
+			}
+
+			session, err := authService.ValidateSession(sessionID, sessionUserID)
+			if err != nil {
+				authService.ClearJWTCookie(w)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var impersonator *model.User
+			if impersonatorID != "" {
+				impersonator, err = userService.ByID(impersonatorID)
+				if err != nil || !impersonator.IsAdmin {
+					authService.ClearJWTCookie(w)
+					next.ServeHTTP(w, r)
+					return
+				}
+				impersonator.PasswordHash = nil
+				impersonator.TOTPSecret = nil
+			}
+
 			// Fetch user from database
 			user, err := userService.ByID(userID)
 			if err != nil {
@@ -44,8 +83,9 @@ func AuthMiddleware(authService *service.AuthService, userService *service.UserS
 				return
 			}
 
-			// Security: Remove password hash from context
+			// Security: Remove password hash and TOTP secret from context
 			user.PasswordHash = nil
+			user.TOTPSecret = nil
 
 			profile, err := profileService.ByUserID(userID)
 			if err != nil {
@@ -55,7 +95,8 @@ func AuthMiddleware(authService *service.AuthService, userService *service.UserS
 				return
 			}
 
-			subscription, err := subscriptionService.Subscription(userID)
+			// Personal workspace, RequireWorkspace replaces it on /app routes
+			subscription, err := subscriptionService.PersonalSubscription(userID)
 			if err != nil {
 				// Subscription not found - something wrong, clear cookie
 				authService.ClearJWTCookie(w)
@@ -63,10 +104,14 @@ func AuthMiddleware(authService *service.AuthService, userService *service.UserS
 				return
 			}
 
-			// Add user + profile + subscription to context
+			// Add user + profile + subscription + session to context
 			ctx := ctxkeys.WithUser(r.Context(), user)
+			ctx = ctxkeys.WithSession(ctx, session)
 			ctx = ctxkeys.WithProfile(ctx, profile)
 			ctx = ctxkeys.WithSubscription(ctx, subscription)
+			if impersonator != nil {
+				ctx = ctxkeys.WithImpersonator(ctx, impersonator)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -124,3 +169,56 @@ func RequireGuest(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r)
 	}
 }
+
+// RequireAdmin limits a route to admins, use inside RequireAuth
+// Everyone else gets a 404 so the console isn't advertised, that includes
+// admins while they impersonate someone
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ctxkeys.User(r.Context())
+		if user == nil || !user.IsAdmin || ctxkeys.Impersonator(r.Context()) != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// BlockImpersonation refuses sensitive actions (credentials, billing, deletion)
+// while an admin impersonates the user
+func BlockImpersonation(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ctxkeys.Impersonator(r.Context()) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "true" {
+			ui.RenderOOB(w, r, toast.Toast(toast.Props{
+				Title:       "Not available",
+				Description: "This action is disabled while impersonating",
+				Variant:     toast.VariantWarning,
+				Icon:        true,
+				Dismissible: true,
+			}), "beforeend:#toast-container")
+			return
+		}
+		http.Error(w, "This action is disabled while impersonating", http.StatusForbidden)
+	}
+}
+
+// AuditContext describes who is making the request for audit events
+// The actor is the impersonating admin if there is one, empty for guests.
+func AuditContext(r *http.Request) model.AuditContext {
+	audit := model.AuditContext{
+		IPAddress: ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if impersonator := ctxkeys.Impersonator(r.Context()); impersonator != nil {
+		audit.ActorID = impersonator.ID
+	} else if user := ctxkeys.User(r.Context()); user != nil {
+		audit.ActorID = user.ID
+	}
+	return audit
+}
@@ -35,6 +35,18 @@ func CSRFProtection(next http.Handler) http.Handler {
 			return
 		}
 
+		// Skip CSRF check for one-click unsubscribe (mail clients, authorized by the signed token)
+		if strings.HasPrefix(r.URL.Path, "/unsubscribe/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Skip CSRF check for the JSON API (bearer tokens only, RequireAPIToken ignores cookies)
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Validate CSRF token for state-changing methods (POST, PUT, PATCH, DELETE)
 		token := getOrGenerateCSRFToken(w, r)
 		ctx := ctxkeys.WithCSRFToken(r.Context(), token)
@@ -53,7 +65,7 @@ func CSRFProtection(next http.Handler) http.Handler {
 			slog.Warn("csrf validation failed",
 				"path", r.URL.Path,
 				"method", r.Method,
-				"ip", getClientIP(r),
+				"ip", ClientIP(r),
 			)
 			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
 			return
@@ -105,7 +105,7 @@ func RateLimitAuth() func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			// Get real IP (handle proxies)
-			ip := getClientIP(r)
+			ip := ClientIP(r)
 
 			// Check rate limit
 			if !limiter.Allow(ip) {
@@ -122,8 +122,9 @@ func RateLimitAuth() func(http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// getClientIP extracts real client IP from request
-func getClientIP(r *http.Request) string {
+// ClientIP extracts real client IP from request
+// Also used by handlers to record the IP of sessions and audit events
+func ClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header (proxy/load balancer)
 	xff := r.Header.Get("X-Forwarded-For")
 	if xff != "" {
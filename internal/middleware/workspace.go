@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/ctxkeys"
+	"github.com/templui/goilerplate/internal/service"
+)
+
+// WorkspaceCookieName holds the id of the workspace the user switched to
+const WorkspaceCookieName = "workspace"
+
+// RequireWorkspace loads the active workspace into the context, wrap it in RequireAuth
+// The workspace comes from the workspace cookie and falls back to the personal workspace
+// when the cookie is missing or the user is no longer a member.
+// Adds the membership and all of the user's memberships (for the switcher), and
+// replaces the subscription with the workspace's subscription.
+func RequireWorkspace(organizationService *service.OrganizationService, subscriptionService *service.SubscriptionService) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user := ctxkeys.User(r.Context())
+
+			memberships, err := organizationService.Memberships(user.ID)
+			if err != nil || len(memberships) == 0 {
+				slog.Error("failed to load workspaces", "error", err, "user_id", user.ID)
+				http.Error(w, "Failed to load workspace", http.StatusInternalServerError)
+				return
+			}
+
+			// ByUserID sorts the personal workspace first
+			active := memberships[0]
+			cookie, err := r.Cookie(WorkspaceCookieName)
+			if err == nil {
+				for _, membership := range memberships {
+					if membership.OrganizationID == cookie.Value {
+						active = membership
+						break
+					}
+				}
+			}
+
+			subscription, err := subscriptionService.Subscription(active.OrganizationID)
+			if err != nil {
+				slog.Error("failed to load workspace subscription", "error", err, "organization_id", active.OrganizationID)
+				http.Error(w, "Failed to load workspace", http.StatusInternalServerError)
+				return
+			}
+
+			ctx := ctxkeys.WithMembership(r.Context(), active)
+			ctx = ctxkeys.WithMemberships(ctx, memberships)
+			ctx = ctxkeys.WithSubscription(ctx, subscription)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
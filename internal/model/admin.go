@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+const (
+	AdminActionPlanOverride       = "plan_override"
+	AdminActionForceLogout        = "force_logout"
+	AdminActionSendMagicLink      = "send_magic_link"
+	AdminActionResendEmailChange  = "resend_email_change"
+	AdminActionGrantAdmin         = "grant_admin"
+	AdminActionRevokeAdmin        = "revoke_admin"
+	AdminActionDeleteUser         = "user_delete"
+	AdminActionImpersonationStart = "impersonation_start"
+	AdminActionImpersonationStop  = "impersonation_stop"
+	AdminActionUnlockLogin        = "unlock_login"
+)
+
+const (
+	AdminTargetUser         = "user"
+	AdminTargetOrganization = "organization"
+)
+
+// AdminAuditLog records an action taken in the admin console
+// Admin email and target details are copied so entries stay readable after deletion
+type AdminAuditLog struct {
+	ID         string    `db:"id"`
+	AdminID    string    `db:"admin_id"`
+	AdminEmail string    `db:"admin_email"`
+	Action     string    `db:"action"`
+	TargetType string    `db:"target_type"`
+	TargetID   string    `db:"target_id"`
+	Details    string    `db:"details"`
+	IPAddress  string    `db:"ip_address"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (l *AdminAuditLog) ActionLabel() string {
+	switch l.Action {
+	case AdminActionPlanOverride:
+		return "Plan override"
+	case AdminActionForceLogout:
+		return "Forced logout"
+	case AdminActionSendMagicLink:
+		return "Sent magic link"
+	case AdminActionResendEmailChange:
+		return "Resent email change"
+	case AdminActionGrantAdmin:
+		return "Granted admin"
+	case AdminActionRevokeAdmin:
+		return "Revoked admin"
+	case AdminActionDeleteUser:
+		return "Deleted user"
+	case AdminActionImpersonationStart:
+		return "Started impersonation"
+	case AdminActionImpersonationStop:
+		return "Stopped impersonation"
+	case AdminActionUnlockLogin:
+		return "Unlocked sign-in"
+	default:
+		return l.Action
+	}
+}
+
+// AdminUser is a row in the admin user list
+type AdminUser struct {
+	User
+	Name string `db:"name"`
+}
+
+// AdminWorkspace is one of a user's workspaces with its subscription
+type AdminWorkspace struct {
+	Membership   *Membership
+	Subscription *Subscription
+}
+
+// AdminUserDetail is everything the admin console shows about one user
+type AdminUserDetail struct {
+	User       *User
+	Profile    *Profile
+	Workspaces []*AdminWorkspace
+	GoalsCount int
+	Files      []*File
+	Sessions   []*Session
+	// LoginAttempt is nil without recent failed password sign-ins
+	LoginAttempt *LoginAttempt
+	AuditLogs    []*AdminAuditLog
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	APITokenScopeRead  = "read"
+	APITokenScopeWrite = "write"
+)
+
+// APIToken is a personal access token for the JSON API
+type APIToken struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	Name        string     `db:"name"`
+	TokenHash   string     `db:"token_hash"`   // SHA-256 of the plain token
+	TokenPrefix string     `db:"token_prefix"` // e.g. "gp_a1b2c3d4", shown in settings
+	Scope       string     `db:"scope"`        // read or write
+	LastUsedAt  *time.Time `db:"last_used_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// Allows reports whether the token may be used for a request needing scope
+// Write tokens can also read
+func (t *APIToken) Allows(scope string) bool {
+	if scope == APITokenScopeRead {
+		return t.Scope == APITokenScopeRead || t.Scope == APITokenScopeWrite
+	}
+	return t.Scope == scope
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEventLogin                    = "login"
+	AuditEventIdentityLinked           = "identity_linked"
+	AuditEventIdentityUnlinked         = "identity_unlinked"
+	AuditEventPasswordChanged          = "password_changed"
+	AuditEventPasswordSet              = "password_set"
+	AuditEventPasswordRemoved          = "password_removed"
+	AuditEventEmailChangeRequested     = "email_change_requested"
+	AuditEventEmailChanged             = "email_changed"
+	AuditEventTwoFactorEnabled         = "two_factor_enabled"
+	AuditEventTwoFactorDisabled        = "two_factor_disabled"
+	AuditEventRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	AuditEventSessionRevoked           = "session_revoked"
+	AuditEventAccountSecured           = "account_secured"
+	AuditEventAccountLocked            = "account_locked"
+	AuditEventAccountDeleted           = "account_deleted"
+)
+
+// AuditEvents lists every event type, in the order filters show them
+var AuditEvents = []string{
+	AuditEventLogin,
+	AuditEventIdentityLinked,
+	AuditEventIdentityUnlinked,
+	AuditEventPasswordChanged,
+	AuditEventPasswordSet,
+	AuditEventPasswordRemoved,
+	AuditEventEmailChangeRequested,
+	AuditEventEmailChanged,
+	AuditEventTwoFactorEnabled,
+	AuditEventTwoFactorDisabled,
+	AuditEventRecoveryCodesRegenerated,
+	AuditEventSessionRevoked,
+	AuditEventAccountSecured,
+	AuditEventAccountLocked,
+	AuditEventAccountDeleted,
+}
+
+// AuditEvent is a security-relevant change to an account
+// Metadata is a JSON object, e.g. {"method": "passkey"} for a login
+type AuditEvent struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Email     string    `db:"email"`
+	ActorID   string    `db:"actor_id"`
+	Event     string    `db:"event"`
+	IPAddress string    `db:"ip_address"`
+	UserAgent string    `db:"user_agent"`
+	Metadata  string    `db:"metadata"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditContext says who caused an event and from where, built by handlers per request
+// ActorID is empty when the user acted themselves, it is the admin for admin actions.
+type AuditContext struct {
+	ActorID   string
+	IPAddress string
+	UserAgent string
+}
+
+// AuditEventFilter narrows the admin event search, zero values match everything
+// User matches a user id exactly or part of an email.
+type AuditEventFilter struct {
+	User  string
+	Event string
+	From  time.Time
+	To    time.Time
+}
+
+func (e *AuditEvent) Label() string {
+	return AuditEventLabel(e.Event)
+}
+
+// Meta decodes Metadata, broken or empty metadata gives an empty map
+func (e *AuditEvent) Meta() map[string]string {
+	meta := map[string]string{}
+	_ = json.Unmarshal([]byte(e.Metadata), &meta)
+	return meta
+}
+
+// ByAdmin reports whether someone other than the user caused the event
+func (e *AuditEvent) ByAdmin() bool {
+	return e.ActorID != "" && e.ActorID != e.UserID
+}
+
+func (e *AuditEvent) Device() string {
+	if e.UserAgent == "" {
+		return ""
+	}
+	return DeviceName(e.UserAgent)
+}
+
+func AuditEventLabel(event string) string {
+	switch event {
+	case AuditEventLogin:
+		return "Signed in"
+	case AuditEventIdentityLinked:
+		return "Connected account linked"
+	case AuditEventIdentityUnlinked:
+		return "Connected account removed"
+	case AuditEventPasswordChanged:
+		return "Password changed"
+	case AuditEventPasswordSet:
+		return "Password set"
+	case AuditEventPasswordRemoved:
+		return "Password removed"
+	case AuditEventEmailChangeRequested:
+		return "Email change requested"
+	case AuditEventEmailChanged:
+		return "Email changed"
+	case AuditEventTwoFactorEnabled:
+		return "Two-factor enabled"
+	case AuditEventTwoFactorDisabled:
+		return "Two-factor disabled"
+	case AuditEventRecoveryCodesRegenerated:
+		return "Recovery codes regenerated"
+	case AuditEventSessionRevoked:
+		return "Signed out a session"
+	case AuditEventAccountSecured:
+		return "Signed out everywhere from a new-device alert"
+	case AuditEventAccountLocked:
+		return "Sign-in locked after failed attempts"
+	case AuditEventAccountDeleted:
+		return "Account deleted"
+	default:
+		return event
+	}
+}
@@ -1,13 +1,21 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
 const (
-	FileTypeAvatar = "avatar"
+	FileTypeAvatar     = "avatar"
+	FileTypeAttachment = "attachment"
 )
 
+// FileOwnerGoalEntry is the owner type of attachments, OwnerID is the entry's ID
+const FileOwnerGoalEntry = "goal_entry"
+
+// MaxEntryAttachments caps the files attached to a single goal entry
+const MaxEntryAttachments = 10
+
 type File struct {
 	ID           string    `db:"id"`
 	UserID       string    `db:"user_id"`    // Who owns/created this file
@@ -22,3 +30,24 @@ type File struct {
 	Public       bool      `db:"public"` // true = public files (7d expiry), false = private files (1h expiry)
 	CreatedAt    time.Time `db:"created_at"`
 }
+
+func (f *File) IsImage() bool {
+	return strings.HasPrefix(f.MimeType, "image/")
+}
+
+// Attachment is a file attached to a goal entry with a URL to show it
+// The URL of private files is presigned and expires, so it's built per request.
+type Attachment struct {
+	*File
+	URL string
+}
+
+// StorageUsage is how much of its plan's storage a workspace uses, in bytes
+type StorageUsage struct {
+	Used  int64
+	Limit int64 // -1 for unlimited
+}
+
+func (u StorageUsage) Exceeds(size int64) bool {
+	return u.Limit != -1 && u.Used+size > u.Limit
+}
@@ -9,13 +9,117 @@ const (
 	GoalStatusCompleted = "completed"
 )
 
+const (
+	GoalCadenceNone   = "none"
+	GoalCadenceDaily  = "daily"
+	GoalCadenceWeekly = "weekly"
+)
+
+const (
+	DefaultGoalSteps = 100
+	MaxGoalSteps     = 1000
+)
+
 type Goal struct {
-	ID          string    `db:"id"`
-	UserID      string    `db:"user_id"`
-	Title       string    `db:"title"`
-	Description string    `db:"description"`
-	Status      string    `db:"status"`
-	CurrentStep int       `db:"current_step"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID             string    `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	UserID         string    `db:"user_id"` // Member who created the goal
+	Title          string    `db:"title"`
+	Description    string    `db:"description"`
+	Status         string    `db:"status"`
+	CurrentStep    int       `db:"current_step"`
+	TargetSteps    int       `db:"target_steps"`
+	Cadence        string    `db:"cadence"`    // none, daily or weekly
+	ShareSlug      *string   `db:"share_slug"` // Set while the goal is shared at /g/{slug}
+	ShareIndexable bool      `db:"share_indexable"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// IsShared reports whether the goal has a public share page
+func (g *Goal) IsShared() bool {
+	return g.ShareSlug != nil
+}
+
+// SharePath returns the path of the public share page, empty while not shared
+func (g *Goal) SharePath() string {
+	if g.ShareSlug == nil {
+		return ""
+	}
+	return "/g/" + *g.ShareSlug
+}
+
+// HasCadence reports whether steps have due dates
+func (g *Goal) HasCadence() bool {
+	return g.cadenceDays() > 0
+}
+
+// StepDueDate returns the day a step is due (midnight in loc)
+// Step 1 is due on the day the goal was created, zero time without a cadence
+func (g *Goal) StepDueDate(step int, loc *time.Location) time.Time {
+	days := g.cadenceDays()
+	if days == 0 {
+		return time.Time{}
+	}
+	return startOfDay(g.CreatedAt, loc).AddDate(0, 0, (step-1)*days)
+}
+
+// StepsDue returns how many steps should be completed by the end of today
+func (g *Goal) StepsDue(now time.Time, loc *time.Location) int {
+	days := g.cadenceDays()
+	if days == 0 {
+		return 0
+	}
+
+	// Rounding absorbs 23h/25h days around DST changes
+	elapsed := int(startOfDay(now, loc).Sub(startOfDay(g.CreatedAt, loc)).Round(24*time.Hour).Hours() / 24)
+	if elapsed < 0 {
+		return 0
+	}
+
+	return min(elapsed/days+1, g.TargetSteps)
+}
+
+// StepsOverdue returns how many steps were due before today but are not completed
+func (g *Goal) StepsOverdue(now time.Time, loc *time.Location) int {
+	if !g.HasCadence() || g.Status == GoalStatusCompleted {
+		return 0
+	}
+
+	// Steps due before today = steps due by today, minus today's step if one is due today
+	due := g.StepsDue(now, loc)
+	if due > 0 && g.StepDueDate(due, loc).Equal(startOfDay(now, loc)) {
+		due--
+	}
+
+	return max(due-g.CurrentStep, 0)
+}
+
+// IsStepOverdue reports whether an uncompleted step's due date has passed
+func (g *Goal) IsStepOverdue(step int, now time.Time, loc *time.Location) bool {
+	if !g.HasCadence() || step <= g.CurrentStep {
+		return false
+	}
+	return g.StepDueDate(step, loc).Before(startOfDay(now, loc))
+}
+
+// IsStepDueToday reports whether a step's due date is today
+func (g *Goal) IsStepDueToday(step int, now time.Time, loc *time.Location) bool {
+	return g.HasCadence() && g.StepDueDate(step, loc).Equal(startOfDay(now, loc))
+}
+
+func (g *Goal) cadenceDays() int {
+	switch g.Cadence {
+	case GoalCadenceDaily:
+		return 1
+	case GoalCadenceWeekly:
+		return 7
+	default:
+		return 0
+	}
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
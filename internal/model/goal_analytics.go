@@ -0,0 +1,153 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	StreakUnitDay  = "day"
+	StreakUnitWeek = "week"
+)
+
+// GoalStats is the progress of one goal, computed from its completed entries
+type GoalStats struct {
+	CurrentStreak int     `json:"current_streak"`
+	LongestStreak int     `json:"longest_streak"`
+	StreakUnit    string  `json:"streak_unit"` // week for weekly goals, day otherwise
+	StepsPerWeek  float64 `json:"steps_per_week"`
+	// ProjectedFinish is the day the last step is done at the current pace
+	// Nil for completed goals and goals without progress yet.
+	ProjectedFinish *time.Time `json:"projected_finish,omitempty"`
+}
+
+// GoalAnalyticsGoal is one goal in the workspace analytics
+type GoalAnalyticsGoal struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	CurrentStep int    `json:"current_step"`
+	TargetSteps int    `json:"target_steps"`
+	GoalStats
+}
+
+// GoalAnalytics summarizes completed steps across all goals of a workspace
+// Streaks count days with at least one completed step of any goal.
+type GoalAnalytics struct {
+	GeneratedAt    time.Time            `json:"generated_at"`
+	Timezone       string               `json:"timezone"`
+	CompletedSteps int                  `json:"completed_steps"`
+	CurrentStreak  int                  `json:"current_streak"`
+	LongestStreak  int                  `json:"longest_streak"`
+	StepsPerWeek   float64              `json:"steps_per_week"` // Average over Weeks
+	Weeks          []*WeekCount         `json:"weeks"`
+	Heatmap        []*HeatmapDay        `json:"heatmap"`
+	Goals          []*GoalAnalyticsGoal `json:"goals"`
+}
+
+// WeekCount is the number of steps completed in the week starting on Monday Start
+type WeekCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// HeatmapDay is the number of steps completed on one calendar day
+type HeatmapDay struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+}
+
+// Level buckets the count into 0-4 for the heatmap colors
+func (d *HeatmapDay) Level() int {
+	switch {
+	case d.Count == 0:
+		return 0
+	case d.Count == 1:
+		return 1
+	case d.Count <= 3:
+		return 2
+	case d.Count <= 5:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Stats computes streaks, pace and projected finish from the goal's entries
+// Weekly goals count streaks in weeks, all others in calendar days in loc.
+func (g *Goal) Stats(entries []*GoalEntry, now time.Time, loc *time.Location) GoalStats {
+	stats := GoalStats{StreakUnit: StreakUnitDay}
+	weekly := g.Cadence == GoalCadenceWeekly
+	if weekly {
+		stats.StreakUnit = StreakUnitWeek
+	}
+
+	var periods []int
+	completed := 0
+	first := g.CreatedAt
+	for _, entry := range entries {
+		if !entry.Completed || entry.CompletedAt == nil {
+			continue
+		}
+		completed++
+		periods = append(periods, PeriodIndex(*entry.CompletedAt, loc, weekly))
+		if entry.CompletedAt.Before(first) {
+			first = *entry.CompletedAt
+		}
+	}
+	stats.CurrentStreak, stats.LongestStreak = Streaks(periods, PeriodIndex(now, loc, weekly))
+
+	// Pace since the goal started, at least one week so a fast first day doesn't skew it
+	weeks := max(now.Sub(first).Hours()/(24*7), 1)
+	stats.StepsPerWeek = float64(completed) / weeks
+
+	remaining := g.TargetSteps - g.CurrentStep
+	if g.Status != GoalStatusCompleted && remaining > 0 && stats.StepsPerWeek > 0 {
+		days := int(float64(remaining) / stats.StepsPerWeek * 7)
+		finish := startOfDay(now, loc).AddDate(0, 0, days)
+		stats.ProjectedFinish = &finish
+	}
+
+	return stats
+}
+
+// PeriodIndex numbers calendar days, or weeks starting on Monday, so that
+// consecutive periods differ by one
+func PeriodIndex(t time.Time, loc *time.Location, weekly bool) int {
+	epoch := time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+	// Rounding absorbs 23h/25h days around DST changes
+	days := int(startOfDay(t, loc).Sub(epoch).Round(24*time.Hour).Hours() / 24)
+	if !weekly {
+		return days
+	}
+	// 1970-01-01 was a Thursday, shift by 3 days so weeks start on Monday
+	return (days + 3) / 7
+}
+
+// Streaks returns the current and longest run of consecutive periods
+// The current streak is still alive if the last period was the one before now.
+func Streaks(periods []int, now int) (current, longest int) {
+	if len(periods) == 0 {
+		return 0, 0
+	}
+
+	periods = slices.Clone(periods)
+	slices.Sort(periods)
+	periods = slices.Compact(periods)
+
+	run := 0
+	for i, period := range periods {
+		if i > 0 && period == periods[i-1]+1 {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	last := periods[len(periods)-1]
+	if last == now || last == now-1 {
+		current = run
+	}
+	return current, longest
+}
@@ -10,6 +10,7 @@ type GoalEntry struct {
 	Step        int        `db:"step"`
 	Completed   bool       `db:"completed"`
 	Note        string     `db:"note"`
+	NoteShared  bool       `db:"note_shared"` // Shown on the goal's share page
 	CompletedAt *time.Time `db:"completed_at"`
 	CreatedAt   time.Time  `db:"created_at"`
 }
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// GoalExportVersion is bumped whenever the export format changes incompatibly
+const GoalExportVersion = 1
+
+const (
+	GoalImportImported = "imported"
+	GoalImportSkipped  = "skipped"
+	GoalImportInvalid  = "invalid"
+)
+
+// GoalExport is the file format of goal exports and imports
+type GoalExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Goals      []*ExportedGoal `json:"goals"`
+}
+
+// ExportedGoal is a goal with all its entries
+// ID is informational, imported goals always get a new one.
+type ExportedGoal struct {
+	ID          string               `json:"id,omitempty"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Status      string               `json:"status"`
+	Cadence     string               `json:"cadence"`
+	CurrentStep int                  `json:"current_step"`
+	TargetSteps int                  `json:"target_steps"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Entries     []*ExportedGoalEntry `json:"entries"`
+}
+
+type ExportedGoalEntry struct {
+	Step        int        `json:"step"`
+	Completed   bool       `json:"completed"`
+	Note        string     `json:"note,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// GoalImportResult reports what happened to one goal of an imported file
+type GoalImportResult struct {
+	Title  string
+	Status string // imported, skipped or invalid
+	Reason string
+	GoalID string // Set when imported
+}
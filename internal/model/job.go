@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusDead      = "dead" // Gave up after MaxAttempts, kept for inspection
+)
+
+type Job struct {
+	ID          string     `db:"id"`
+	Type        string     `db:"type"`
+	Payload     string     `db:"payload"` // JSON, decoded by the job's handler
+	Status      string     `db:"status"`
+	Attempts    int        `db:"attempts"`
+	MaxAttempts int        `db:"max_attempts"`
+	LastError   string     `db:"last_error"`
+	RunAt       time.Time  `db:"run_at"`
+	LockedAt    *time.Time `db:"locked_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
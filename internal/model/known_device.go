@@ -0,0 +1,38 @@
+package model
+
+import (
+	"net/netip"
+	"time"
+)
+
+// KnownDevice is a browser and network the user signed in from before
+type KnownDevice struct {
+	ID          string    `db:"id"`
+	UserID      string    `db:"user_id"`
+	Fingerprint string    `db:"fingerprint"`
+	UserAgent   string    `db:"user_agent"`
+	IPAddress   string    `db:"ip_address"`
+	LastSeenAt  time.Time `db:"last_seen_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// DeviceFingerprint identifies a device loosely enough to survive browser updates
+// and address changes within a network: the browser family and OS plus the
+// IP prefix (/24 for IPv4, /48 for IPv6), e.g. "Chrome on macOS|203.0.113.0/24".
+func DeviceFingerprint(userAgent, ipAddress string) string {
+	network := ipAddress
+	addr, err := netip.ParseAddr(ipAddress)
+	if err == nil {
+		bits := 48
+		if addr.Unmap().Is4() {
+			addr = addr.Unmap()
+			bits = 24
+		}
+		prefix, err := addr.Prefix(bits)
+		if err == nil {
+			network = prefix.String()
+		}
+	}
+
+	return DeviceName(userAgent) + "|" + network
+}
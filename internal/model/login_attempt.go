@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// LoginAttempt tracks failed password sign-ins for one email
+type LoginAttempt struct {
+	Email        string     `db:"email"`
+	FailedCount  int        `db:"failed_count"`
+	LastFailedAt time.Time  `db:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until"`
+}
+
+func (a *LoginAttempt) IsLocked() bool {
+	return a.LockedUntil != nil && time.Now().Before(*a.LockedUntil)
+}
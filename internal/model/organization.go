@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+const PersonalOrganizationName = "Personal"
+
+// Organization is a workspace that owns goals and a subscription
+type Organization struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	Personal  bool      `db:"personal"` // Created at signup, never shared
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Membership is a user's role in an organization
+// The joined fields are only filled by the queries that select them
+type Membership struct {
+	ID             string    `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	UserID         string    `db:"user_id"`
+	Role           string    `db:"role"`
+	CreatedAt      time.Time `db:"created_at"`
+
+	OrganizationName string `db:"organization_name"` // Joined from organizations
+	Personal         bool   `db:"personal"`          // Joined from organizations
+	Email            string `db:"email"`             // Joined from users
+	Name             string `db:"name"`              // Joined from profiles
+}
+
+func (m *Membership) IsOwner() bool {
+	return m.Role == RoleOwner
+}
+
+// CanManageMembers reports whether the member may invite, remove and change roles
+func (m *Membership) CanManageMembers() bool {
+	return m.Role == RoleOwner || m.Role == RoleAdmin
+}
+
+// CanManageBilling reports whether the member may change the workspace plan
+func (m *Membership) CanManageBilling() bool {
+	return m.Role == RoleOwner
+}
+
+// Invitation asks someone by email to join an organization
+type Invitation struct {
+	ID             string     `db:"id"`
+	OrganizationID string     `db:"organization_id"`
+	Email          string     `db:"email"`
+	Role           string     `db:"role"`
+	TokenID        string     `db:"token_id"`
+	InvitedBy      string     `db:"invited_by"`
+	AcceptedAt     *time.Time `db:"accepted_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+
+	OrganizationName string    `db:"organization_name"` // Joined from organizations
+	InviterName      string    `db:"inviter_name"`      // Joined from profiles
+	Token            string    `db:"token"`             // Joined from tokens
+	ExpiresAt        time.Time `db:"expires_at"`        // Joined from tokens
+}
+
+// ValidRole reports whether role can be given to a member
+func ValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleAdmin, RoleMember:
+		return true
+	default:
+		return false
+	}
+}
+
+// RoleName returns the display name of a role
+func RoleName(role string) string {
+	switch role {
+	case RoleOwner:
+		return "Owner"
+	case RoleAdmin:
+		return "Admin"
+	default:
+		return "Member"
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Authenticator flag bits (WebAuthn §6.1 authenticator data)
+const (
+	passkeyFlagBackupEligible = 0x08
+	passkeyFlagBackupState    = 0x10
+)
+
+// Passkey is a WebAuthn credential registered by a user
+type Passkey struct {
+	ID              string     `db:"id"`
+	UserID          string     `db:"user_id"`
+	Name            string     `db:"name"`
+	CredentialID    string     `db:"credential_id"` // base64url
+	PublicKey       string     `db:"public_key"`    // base64url, COSE encoded
+	AttestationType string     `db:"attestation_type"`
+	AAGUID          string     `db:"aaguid"` // base64url, identifies the authenticator model
+	SignCount       int64      `db:"sign_count"`
+	Flags           int        `db:"flags"`
+	Transports      string     `db:"transports"` // Comma-separated
+	LastUsedAt      *time.Time `db:"last_used_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+}
+
+// TransportList returns the transports hinted by the authenticator at registration
+func (p *Passkey) TransportList() []string {
+	if p.Transports == "" {
+		return nil
+	}
+	return strings.Split(p.Transports, ",")
+}
+
+// IsSynced reports whether the credential is backed up to a passkey provider
+// (iCloud Keychain, Google Password Manager, ...) rather than bound to one device
+func (p *Passkey) IsSynced() bool {
+	return p.Flags&passkeyFlagBackupEligible != 0 && p.Flags&passkeyFlagBackupState != 0
+}
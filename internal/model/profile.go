@@ -8,4 +8,23 @@ type Profile struct {
 	Name      string    `db:"name"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+
+	// Notification preferences (goal reminders, weekly digest)
+	Timezone             string     `db:"timezone"` // IANA name, e.g. "Europe/Berlin"
+	RemindersEnabled     bool       `db:"reminders_enabled"`
+	ReminderHour         int        `db:"reminder_hour"`          // Local hour (0-23) reminders and digests are sent
+	ReminderInactiveDays int        `db:"reminder_inactive_days"` // Remind after this many days without progress
+	DigestEnabled        bool       `db:"digest_enabled"`
+	DigestWeekday        int        `db:"digest_weekday"` // time.Weekday: 0 = Sunday
+	LastReminderAt       *time.Time `db:"last_reminder_at"`
+	LastDigestAt         *time.Time `db:"last_digest_at"`
+}
+
+// Location returns the profile's timezone, falling back to UTC if it is invalid
+func (p *Profile) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+type RecoveryCode struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	CodeHash  string     `db:"code_hash"` // SHA-256 of the normalized code
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -7,7 +7,7 @@ import (
 
 type Subscription struct {
 	ID                     string     `db:"id"`
-	UserID                 string     `db:"user_id"`
+	OrganizationID         string     `db:"organization_id"`
 	PlanID                 string     `db:"plan_id"`
 	Status                 string     `db:"status"`
 	Provider               string     `db:"provider"`
@@ -17,6 +17,7 @@ type Subscription struct {
 	Amount                 *int       `db:"amount"`
 	Currency               string     `db:"currency"`
 	Interval               *string    `db:"interval"`
+	Seats                  int        `db:"seats"`
 	CreatedAt              time.Time  `db:"created_at"`
 	UpdatedAt              time.Time  `db:"updated_at"`
 }
@@ -39,14 +40,25 @@ const (
 	SubscriptionPlanConnoisseur = "connoisseur"
 )
 
+// SubscriptionPlans are the plans currently offered, pro and enterprise are legacy
+var SubscriptionPlans = []string{
+	SubscriptionPlanFree,
+	SubscriptionPlanNerd,
+	SubscriptionPlanConnoisseur,
+}
+
 const (
 	SubscriptionIntervalMonthly = "monthly"
 	SubscriptionIntervalYearly  = "yearly"
 )
 
+// FreePlanSeats is how many people a workspace on the free plan can have
+const FreePlanSeats = 3
+
 const (
 	FeatureExport          = "export"
 	FeaturePrioritySupport = "priority_support"
+	FeatureWebhooks        = "webhooks"
 )
 
 func (s *Subscription) IsActive() bool {
@@ -57,6 +69,14 @@ func (s *Subscription) IsPaid() bool {
 	return s.PlanID != SubscriptionPlanFree && s.IsActive()
 }
 
+// HasRunningPaidPlan reports whether a paid plan is active or its paid period hasn't ended yet
+// Accounts and workspaces can't be deleted while it has
+func (s *Subscription) HasRunningPaidPlan() bool {
+	return s.PlanID != SubscriptionPlanFree &&
+		(s.Status == SubscriptionStatusActive ||
+			(s.CurrentPeriodEnd != nil && s.CurrentPeriodEnd.After(time.Now())))
+}
+
 func (s *Subscription) FormatPrice() string {
 	if s.Amount == nil || *s.Amount == 0 {
 		return ""
@@ -82,6 +102,15 @@ func (s *Subscription) FormatPrice() string {
 	return fmt.Sprintf("%s%.0f/%s", symbol, amount, interval)
 }
 
+// SeatLimit returns how many members and pending invitations the workspace can have
+// Paid plans are billed per seat, the free plan includes FreePlanSeats
+func (s *Subscription) SeatLimit() int {
+	if !s.IsPaid() {
+		return FreePlanSeats
+	}
+	return max(s.Seats, 1)
+}
+
 // GetGoalLimit returns the maximum number of goals allowed for this plan
 // Returns -1 for unlimited
 func (s *Subscription) GetGoalLimit() int {
@@ -101,6 +130,25 @@ func (s *Subscription) GetGoalLimit() int {
 	}
 }
 
+// GetStorageLimit returns the bytes of attachments allowed for this plan
+// Returns -1 for unlimited
+func (s *Subscription) GetStorageLimit() int64 {
+	if !s.IsActive() {
+		return 50 << 20 // Free tier default
+	}
+
+	switch s.PlanID {
+	case SubscriptionPlanFree:
+		return 50 << 20
+	case SubscriptionPlanPro, SubscriptionPlanNerd:
+		return 2 << 30
+	case SubscriptionPlanEnterprise, SubscriptionPlanConnoisseur:
+		return 20 << 30
+	default:
+		return 50 << 20
+	}
+}
+
 // HasFeature checks if the subscription has access to a specific feature
 func (s *Subscription) HasFeature(feature string) bool {
 	if !s.IsActive() {
@@ -112,17 +160,21 @@ func (s *Subscription) HasFeature(feature string) bool {
 		SubscriptionPlanFree: {},
 		SubscriptionPlanPro: {
 			FeatureExport,
+			FeatureWebhooks,
 		},
 		SubscriptionPlanEnterprise: {
 			FeatureExport,
 			FeaturePrioritySupport,
+			FeatureWebhooks,
 		},
 		SubscriptionPlanNerd: {
 			FeatureExport,
+			FeatureWebhooks,
 		},
 		SubscriptionPlanConnoisseur: {
 			FeatureExport,
 			FeaturePrioritySupport,
+			FeatureWebhooks,
 		},
 	}
 
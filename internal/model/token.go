@@ -19,6 +19,8 @@ const (
 	TokenTypePasswordReset = "password_reset"
 	TokenTypeEmailChange   = "email_change"
 	TokenTypeMagicLink     = "magic_link"
+	TokenTypeInvitation    = "org_invitation"
+	TokenTypeSecureAccount = "secure_account"
 )
 
 func (t *Token) IsExpired() bool {
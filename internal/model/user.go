@@ -10,6 +10,10 @@ type User struct {
 	PasswordHash    *string    `db:"password_hash"` // Nullable for passwordless users
 	PendingEmail    *string    `db:"pending_email"`
 	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	TOTPSecret      *string    `db:"totp_secret"`       // Set during 2FA enrollment
+	TOTPEnabledAt   *time.Time `db:"totp_enabled_at"`   // Set once enrollment is confirmed
+	TOTPLastCounter *int64     `db:"totp_last_counter"` // Time step of the last accepted code, see totp.Match
+	IsAdmin         bool       `db:"is_admin"`          // Access to the /admin console
 	CreatedAt       time.Time  `db:"created_at"`
 
 	// Computed fields (not in database)
@@ -19,3 +23,7 @@ type User struct {
 func (u *User) HasPassword() bool {
 	return u.PasswordHash != nil && *u.PasswordHash != ""
 }
+
+func (u *User) HasTwoFactor() bool {
+	return u.TOTPEnabledAt != nil
+}
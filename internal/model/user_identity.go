@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	IdentityProviderGoogle = "google"
+	IdentityProviderGitHub = "github"
+)
+
+// UserIdentity links an OAuth provider account to a user
+type UserIdentity struct {
+	ID             string     `db:"id"`
+	UserID         string     `db:"user_id"`
+	Provider       string     `db:"provider"`
+	ProviderUserID string     `db:"provider_user_id"` // Stable subject at the provider, never the email
+	Email          string     `db:"email"`            // Last email reported by the provider
+	LastLoginAt    *time.Time `db:"last_login_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// IdentityProviderName returns the display name for a provider id
+func IdentityProviderName(provider string) string {
+	switch provider {
+	case IdentityProviderGoogle:
+		return "Google"
+	case IdentityProviderGitHub:
+		return "GitHub"
+	default:
+		return provider
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookEventGoalCreated         = "goal.created"
+	WebhookEventGoalEntryCompleted  = "goal.entry.completed"
+	WebhookEventGoalCompleted       = "goal.completed"
+	WebhookEventSubscriptionUpdated = "subscription.updated"
+	WebhookEventTest                = "webhook.test" // Sent by the "send test event" button only
+)
+
+// WebhookEvents are the event types endpoints can subscribe to
+var WebhookEvents = []string{
+	WebhookEventGoalCreated,
+	WebhookEventGoalEntryCompleted,
+	WebhookEventGoalCompleted,
+	WebhookEventSubscriptionUpdated,
+}
+
+const (
+	WebhookDeliveryStatusPending   = "pending" // Queued or waiting for a retry
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed" // Gave up after the last retry
+)
+
+type WebhookEndpoint struct {
+	ID          string    `db:"id"`
+	UserID      string    `db:"user_id"`
+	URL         string    `db:"url"`
+	Description string    `db:"description"`
+	Secret      string    `db:"secret"` // whsec_ + base64 key, shared with the receiver
+	Events      string    `db:"events"` // Comma-separated event types
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (e *WebhookEndpoint) EventList() []string {
+	if e.Events == "" {
+		return nil
+	}
+	return strings.Split(e.Events, ",")
+}
+
+// Subscribes reports whether the endpoint receives an event type
+// Test events go to every endpoint
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	return eventType == WebhookEventTest || slices.Contains(e.EventList(), eventType)
+}
+
+type WebhookDelivery struct {
+	ID             string     `db:"id"`
+	EndpointID     string     `db:"endpoint_id"`
+	EventType      string     `db:"event_type"`
+	Payload        string     `db:"payload"` // JSON body as sent
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	ResponseStatus *int       `db:"response_status"`
+	ResponseBody   string     `db:"response_body"` // Truncated
+	LastError      string     `db:"last_error"`
+	LastAttemptAt  *time.Time `db:"last_attempt_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
@@ -0,0 +1,230 @@
+// Package oidc signs users in with any OpenID Connect provider configured
+// through OIDC_PROVIDERS (Microsoft, GitLab, Keycloak, a customer's own IdP, ...).
+//
+// Each provider is discovered lazily on first use, the authorization code flow
+// always uses PKCE (S256) and a nonce, and ID tokens are verified against the
+// issuer's JWKS before any claim is read.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/templui/goilerplate/internal/config"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrMissingIDToken = errors.New("token response has no id_token")
+	ErrNonceMismatch  = errors.New("id token nonce does not match")
+	ErrMissingSubject = errors.New("id token has no subject")
+)
+
+// Claims is the identity mapped from the ID token (and userinfo, when the token lacks the email)
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Registry holds the configured providers in configuration order
+type Registry struct {
+	providers []*Provider
+}
+
+func New(cfg *config.Config) *Registry {
+	registry := &Registry{}
+	for _, providerConfig := range cfg.OIDCProviders {
+		registry.providers = append(registry.providers, NewProvider(providerConfig, cfg.AppURL+"/auth/oidc/"+providerConfig.ID+"/callback"))
+	}
+	return registry
+}
+
+// Get returns the provider with the given id
+func (r *Registry) Get(id string) (*Provider, bool) {
+	for _, provider := range r.providers {
+		if provider.ID() == id {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+type Provider struct {
+	cfg         config.OIDCProvider
+	redirectURL string
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+}
+
+func NewProvider(cfg config.OIDCProvider, redirectURL string) *Provider {
+	return &Provider{
+		cfg:         cfg,
+		redirectURL: redirectURL,
+	}
+}
+
+func (p *Provider) ID() string {
+	return p.cfg.ID
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the authorization URL for a new sign-in
+// state, nonce and verifier must be stored by the caller for the callback
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauthConfig, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems the authorization code, verifies the ID token and maps its claims
+func (p *Provider) Exchange(ctx context.Context, code, nonce, verifier string) (*Claims, error) {
+	oauthConfig, idTokenVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	// Checks signature (JWKS), issuer, audience and expiry
+	idToken, err := idTokenVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	if nonce == "" || idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var raw map[string]any
+	err = idToken.Claims(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode id token claims: %w", err)
+	}
+
+	// Some providers only put the email on the userinfo endpoint
+	if _, ok := raw[p.cfg.EmailClaim]; !ok && p.provider.UserInfoEndpoint() != "" {
+		userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get userinfo: %w", err)
+		}
+
+		var extra map[string]any
+		err = userInfo.Claims(&extra)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode userinfo claims: %w", err)
+		}
+
+		// The userinfo response is only trusted for the same subject as the ID token
+		if userInfo.Subject == idToken.Subject {
+			for key, value := range extra {
+				if _, exists := raw[key]; !exists {
+					raw[key] = value
+				}
+			}
+		}
+	}
+
+	return p.mapClaims(raw)
+}
+
+// discover fetches the provider metadata once and caches it
+// A failed discovery is retried on the next sign-in
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	// go-oidc only keeps the HTTP client from ctx, so the cached JWKS outlives the request
+	provider, err := gooidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover oidc provider %s: %w", p.cfg.ID, err)
+	}
+
+	p.provider = provider
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.cfg.Scopes,
+	}
+
+	return p.oauth, p.verifier, nil
+}
+
+func (p *Provider) mapClaims(raw map[string]any) (*Claims, error) {
+	claims := &Claims{
+		Subject: claimString(raw, p.cfg.SubjectClaim),
+		Email:   claimString(raw, p.cfg.EmailClaim),
+		Name:    claimString(raw, p.cfg.NameClaim),
+	}
+	if claims.Subject == "" {
+		return nil, ErrMissingSubject
+	}
+
+	claims.EmailVerified = p.cfg.TrustEmail || claimBool(raw, p.cfg.EmailVerifiedClaim)
+	return claims, nil
+}
+
+func claimString(raw map[string]any, name string) string {
+	switch v := raw[name].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// claimBool accepts true and "true", some IdPs send booleans as strings
+func claimBool(raw map[string]any, name string) bool {
+	switch v := raw[name].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+// GenerateNonce returns a random value for the nonce parameter
+func GenerateNonce() string {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		panic("failed to generate oidc nonce: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
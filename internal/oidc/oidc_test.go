@@ -0,0 +1,272 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/templui/goilerplate/internal/config"
+)
+
+const (
+	testClientID     = "goilerplate"
+	testClientSecret = "secret"
+	testKeyID        = "test-key"
+	testNonce        = "nonce-123"
+	testVerifier     = "verifier-0123456789-0123456789-0123456789-abc"
+)
+
+// fakeIssuer is an in-process OpenID provider serving discovery, JWKS and the token endpoint
+// The next ID token is built from claims, so each test can tamper with one field
+type fakeIssuer struct {
+	url string
+	key *rsa.PrivateKey
+
+	mu         sync.Mutex
+	claims     jwt.MapClaims
+	signingKey *rsa.PrivateKey
+	tokenForm  url.Values
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	issuer := &fakeIssuer{key: key, signingKey: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("GET /jwks", issuer.handleJWKS)
+	mux.HandleFunc("POST /token", issuer.handleToken)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	issuer.url = server.URL
+	issuer.claims = jwt.MapClaims{
+		"iss":            issuer.url,
+		"sub":            "user-42",
+		"aud":            testClientID,
+		"nonce":          testNonce,
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"name":           "Ada Lovelace",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	return issuer
+}
+
+func (i *fakeIssuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                i.url,
+		"authorization_endpoint":                i.url + "/authorize",
+		"token_endpoint":                        i.url + "/token",
+		"jwks_uri":                              i.url + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *fakeIssuer) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *fakeIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.tokenForm = r.PostForm
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, i.claims)
+	token.Header["kid"] = testKeyID
+	idToken, err := token.SignedString(i.signingKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (i *fakeIssuer) provider() *Provider {
+	return NewProvider(config.OIDCProvider{
+		ID:                 "fake",
+		Name:               "Fake",
+		Issuer:             i.url,
+		ClientID:           testClientID,
+		ClientSecret:       testClientSecret,
+		Scopes:             []string{"openid", "email", "profile"},
+		SubjectClaim:       "sub",
+		EmailClaim:         "email",
+		EmailVerifiedClaim: "email_verified",
+		NameClaim:          "name",
+	}, "https://app.example.com/auth/oidc/fake/callback")
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func TestExchange(t *testing.T) {
+	issuer := newFakeIssuer(t)
+
+	claims, err := issuer.provider().Exchange(context.Background(), "code-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+
+	want := Claims{Subject: "user-42", Email: "ada@example.com", EmailVerified: true, Name: "Ada Lovelace"}
+	if *claims != want {
+		t.Fatalf("claims = %+v, want %+v", *claims, want)
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := issuer.provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatalf("auth code url: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := parsed.Query()
+
+	sum := sha256.Sum256([]byte(testVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if query.Get("code_challenge") != challenge || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("missing S256 challenge in %s", authURL)
+	}
+	if query.Get("nonce") != testNonce || query.Get("state") != "state-1" {
+		t.Fatalf("missing nonce or state in %s", authURL)
+	}
+
+	_, err = provider.Exchange(context.Background(), "code-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+
+	issuer.mu.Lock()
+	form := issuer.tokenForm
+	issuer.mu.Unlock()
+
+	if form.Get("code_verifier") != testVerifier {
+		t.Fatalf("code_verifier = %q, want %q", form.Get("code_verifier"), testVerifier)
+	}
+	if form.Get("code") != "code-1" || form.Get("grant_type") != "authorization_code" {
+		t.Fatalf("unexpected token request: %v", form)
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(issuer *fakeIssuer)
+		wantErr string
+	}{
+		{
+			name:    "bad signature",
+			tamper:  func(issuer *fakeIssuer) { issuer.signingKey = otherKey },
+			wantErr: "failed to verify signature",
+		},
+		{
+			name:    "wrong audience",
+			tamper:  func(issuer *fakeIssuer) { issuer.claims["aud"] = "someone-else" },
+			wantErr: "expected audience",
+		},
+		{
+			name: "expired",
+			tamper: func(issuer *fakeIssuer) {
+				issuer.claims["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+				issuer.claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			wantErr: "token is expired",
+		},
+		{
+			name:    "wrong issuer",
+			tamper:  func(issuer *fakeIssuer) { issuer.claims["iss"] = "https://evil.example.com" },
+			wantErr: "id token issued by a different provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newFakeIssuer(t)
+			tt.tamper(issuer)
+
+			claims, err := issuer.provider().Exchange(context.Background(), "code-1", testNonce, testVerifier)
+			if err == nil {
+				t.Fatalf("exchange accepted the token: %+v", claims)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.claims["nonce"] = "replayed-nonce"
+
+	_, err := issuer.provider().Exchange(context.Background(), "code-1", testNonce, testVerifier)
+	if !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("err = %v, want ErrNonceMismatch", err)
+	}
+
+	// An empty expected nonce never matches, even a token without one
+	delete(issuer.claims, "nonce")
+	_, err = issuer.provider().Exchange(context.Background(), "code-1", "", testVerifier)
+	if !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("err = %v, want ErrNonceMismatch", err)
+	}
+}
@@ -0,0 +1,220 @@
+// Package queue is a persistent background job queue backed by the jobs table.
+// Jobs survive restarts and are retried with exponential backoff until
+// they succeed or run out of attempts (dead letter).
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	DefaultMaxAttempts = 8
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 1 * time.Hour
+	jobTimeout  = 1 * time.Minute
+	staleAfter  = 10 * time.Minute // Running jobs older than this belong to a crashed worker
+	retention   = 7 * 24 * time.Hour
+)
+
+// Handler processes a job's JSON payload
+// Returning an error schedules a retry, wrap with Permanent to give up right away.
+type Handler func(ctx context.Context, payload []byte) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error as not worth retrying (e.g. malformed payload)
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type Queue struct {
+	repo         repository.JobRepository
+	handlers     map[string]Handler
+	workers      int
+	pollInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a queue processed by the given number of workers
+func New(repo repository.JobRepository, workers int, pollInterval time.Duration) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		repo:         repo,
+		handlers:     make(map[string]Handler),
+		workers:      max(workers, 1),
+		pollInterval: pollInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Register sets the handler for a job type, must be called before Start
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+// Enqueue stores a job to run as soon as a worker is free
+func (q *Queue) Enqueue(jobType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := time.Now()
+	job := &model.Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		Payload:     string(data),
+		Status:      model.JobStatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = q.repo.Create(job)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return nil
+}
+
+// Start launches the workers
+func (q *Queue) Start() {
+	for range q.workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	slog.Info("job queue started", "workers", q.workers, "poll_interval", q.pollInterval)
+}
+
+// Stop lets running jobs finish and stops the workers
+func (q *Queue) Stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Cleanup deletes completed jobs past the retention period
+// Has the scheduler job signature
+func (q *Queue) Cleanup(now time.Time) error {
+	deleted, err := q.repo.DeleteCompletedBefore(now.Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to delete completed jobs: %w", err)
+	}
+	if deleted > 0 {
+		slog.Info("completed jobs deleted", "count", deleted)
+	}
+	return nil
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		// Drain all due jobs before sleeping
+		processed := q.processNext()
+		if processed {
+			if q.ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+// processNext claims and runs one job, reports whether a job was found
+func (q *Queue) processNext() bool {
+	now := time.Now()
+	job, err := q.repo.Claim(now, now.Add(-staleAfter))
+	if err != nil {
+		if !errors.Is(err, repository.ErrNoJobAvailable) {
+			slog.Error("failed to claim job", "error", err)
+		}
+		return false
+	}
+
+	err = q.run(job)
+	if err == nil {
+		err = q.repo.Complete(job.ID)
+		if err != nil {
+			slog.Error("failed to complete job", "error", err, "job_id", job.ID, "type", job.Type)
+		}
+		return true
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		slog.Error("job failed permanently", "error", err, "job_id", job.ID, "type", job.Type, "attempts", job.Attempts)
+		err = q.repo.Dead(job.ID, err.Error())
+		if err != nil {
+			slog.Error("failed to mark job as dead", "error", err, "job_id", job.ID)
+		}
+		return true
+	}
+
+	runAt := time.Now().Add(backoff(job.Attempts))
+	slog.Warn("job failed, retrying", "error", err, "job_id", job.ID, "type", job.Type, "attempts", job.Attempts, "retry_at", runAt)
+	err = q.repo.Retry(job.ID, runAt, err.Error())
+	if err != nil {
+		slog.Error("failed to reschedule job", "error", err, "job_id", job.ID)
+	}
+	return true
+}
+
+// run calls the job's handler, a panicking handler counts as a failed attempt
+func (q *Queue) run(job *model.Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	// Not derived from q.ctx: Stop waits for running jobs instead of aborting them
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	return handler(ctx, []byte(job.Payload))
+}
+
+// backoff returns the delay before the next attempt: 30s, 1m, 2m, 4m, ... capped at 1h
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
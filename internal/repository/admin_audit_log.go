@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+type AdminAuditLogRepository interface {
+	Create(log *model.AdminAuditLog) error
+	Recent(limit, offset int) ([]*model.AdminAuditLog, error)
+	Count() (int, error)
+	ByTargetID(targetID string, limit int) ([]*model.AdminAuditLog, error)
+}
+
+type adminAuditLogRepository struct {
+	db Querier
+}
+
+func NewAdminAuditLogRepository(db Querier) AdminAuditLogRepository {
+	return &adminAuditLogRepository{db: db}
+}
+
+func (r *adminAuditLogRepository) Create(log *model.AdminAuditLog) error {
+	if log.ID == "" {
+		log.ID = uuid.New().String()
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO admin_audit_logs (id, admin_id, admin_email, action, target_type, target_id, details, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(query,
+		log.ID,
+		log.AdminID,
+		log.AdminEmail,
+		log.Action,
+		log.TargetType,
+		log.TargetID,
+		log.Details,
+		log.IPAddress,
+		log.CreatedAt,
+	)
+	return err
+}
+
+// Recent returns entries newest first
+func (r *adminAuditLogRepository) Recent(limit, offset int) ([]*model.AdminAuditLog, error) {
+	var logs []*model.AdminAuditLog
+	query := `SELECT * FROM admin_audit_logs ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	err := r.db.Select(&logs, query, limit, offset)
+	return logs, err
+}
+
+func (r *adminAuditLogRepository) Count() (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM admin_audit_logs`
+
+	err := r.db.Get(&count, query)
+	return count, err
+}
+
+// ByTargetID returns the entries about one user or workspace, newest first
+func (r *adminAuditLogRepository) ByTargetID(targetID string, limit int) ([]*model.AdminAuditLog, error) {
+	var logs []*model.AdminAuditLog
+	query := `SELECT * FROM admin_audit_logs WHERE target_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	err := r.db.Select(&logs, query, targetID, limit)
+	return logs, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+type APITokenRepository interface {
+	Create(token *model.APIToken) error
+	ByHash(tokenHash string) (*model.APIToken, error)
+	ByUserID(userID string) ([]*model.APIToken, error)
+	Touch(id string, lastUsedAt time.Time) error
+	Delete(userID, id string) error
+}
+
+type apiTokenRepository struct {
+	db Querier
+}
+
+func NewAPITokenRepository(db Querier) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+func (r *apiTokenRepository) Create(token *model.APIToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO api_tokens (id, user_id, name, token_hash, token_prefix, scope, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		token.ID,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.TokenPrefix,
+		token.Scope,
+		token.CreatedAt,
+	)
+	return err
+}
+
+func (r *apiTokenRepository) ByHash(tokenHash string) (*model.APIToken, error) {
+	token := &model.APIToken{}
+	query := `SELECT * FROM api_tokens WHERE token_hash = $1`
+
+	err := r.db.Get(token, query, tokenHash)
+	if err == sql.ErrNoRows {
+		return nil, ErrAPITokenNotFound
+	}
+
+	return token, err
+}
+
+// ByUserID returns the user's tokens, newest first
+func (r *apiTokenRepository) ByUserID(userID string) ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	query := `SELECT * FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+
+	err := r.db.Select(&tokens, query, userID)
+	return tokens, err
+}
+
+func (r *apiTokenRepository) Touch(id string, lastUsedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, lastUsedAt, id)
+	return err
+}
+
+// Delete removes a token, scoped to the user so one user can't revoke another's token
+func (r *apiTokenRepository) Delete(userID, id string) error {
+	query := `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
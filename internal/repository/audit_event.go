@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+type AuditEventRepository interface {
+	Create(event *model.AuditEvent) error
+	ByUserID(userID string, limit int) ([]*model.AuditEvent, error)
+	Search(filter model.AuditEventFilter, limit, offset int) ([]*model.AuditEvent, error)
+	Count(filter model.AuditEventFilter) (int, error)
+}
+
+type auditEventRepository struct {
+	db Querier
+}
+
+func NewAuditEventRepository(db Querier) AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(event *model.AuditEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if event.Metadata == "" {
+		event.Metadata = "{}"
+	}
+
+	query := `
+		INSERT INTO audit_events (id, user_id, email, actor_id, event, ip_address, user_agent, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(query,
+		event.ID,
+		event.UserID,
+		event.Email,
+		event.ActorID,
+		event.Event,
+		event.IPAddress,
+		event.UserAgent,
+		event.Metadata,
+		event.CreatedAt,
+	)
+	return err
+}
+
+// ByUserID returns the user's events newest first
+func (r *auditEventRepository) ByUserID(userID string, limit int) ([]*model.AuditEvent, error) {
+	var events []*model.AuditEvent
+	query := `SELECT * FROM audit_events WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	err := r.db.Select(&events, query, userID, limit)
+	return events, err
+}
+
+// Search returns the events matching filter newest first
+func (r *auditEventRepository) Search(filter model.AuditEventFilter, limit, offset int) ([]*model.AuditEvent, error) {
+	var events []*model.AuditEvent
+	where, args := auditEventWhere(filter)
+	query := `SELECT * FROM audit_events` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	err := r.db.Select(&events, query, args...)
+	return events, err
+}
+
+func (r *auditEventRepository) Count(filter model.AuditEventFilter) (int, error) {
+	var count int
+	where, args := auditEventWhere(filter)
+	query := `SELECT COUNT(*) FROM audit_events` + where
+
+	err := r.db.Get(&count, query, args...)
+	return count, err
+}
+
+// auditEventWhere builds the WHERE clause for the set fields of filter
+func auditEventWhere(filter model.AuditEventFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if strings.TrimSpace(filter.User) != "" {
+		conditions = append(conditions, fmt.Sprintf("(user_id = %s OR LOWER(email) LIKE %s)",
+			arg(strings.TrimSpace(filter.User)), arg(searchPattern(filter.User))))
+	}
+	if filter.Event != "" {
+		conditions = append(conditions, "event = "+arg(filter.Event))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.To))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
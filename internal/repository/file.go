@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"errors"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -18,14 +17,18 @@ type FileRepository interface {
 	FileByType(ownerType, ownerID, fileType string) (*model.File, error)
 	Files(ownerType, ownerID string) ([]*model.File, error)
 	AllUserFiles(userID string) ([]*model.File, error)
+	GoalAttachments(goalID string) ([]*model.File, error)
+	OrganizationAttachments(organizationID string) ([]*model.File, error)
+	OrganizationAttachmentsSize(organizationID string) (int64, error)
+	ReassignAttachments(organizationID, fromUserID, toUserID string) error
 	Delete(id string) error
 }
 
 type fileRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewFileRepository(db *sqlx.DB) *fileRepository {
+func NewFileRepository(db Querier) *fileRepository {
 	return &fileRepository{db: db}
 }
 
@@ -99,6 +102,63 @@ func (r *fileRepository) AllUserFiles(userID string) ([]*model.File, error) {
 	return files, nil
 }
 
+// GoalAttachments returns the files attached to any entry of a goal
+func (r *fileRepository) GoalAttachments(goalID string) ([]*model.File, error) {
+	var files []*model.File
+	query := `SELECT f.* FROM files f
+	          JOIN goal_entries e ON e.id = f.owner_id
+	          WHERE f.owner_type = $1 AND e.goal_id = $2`
+
+	err := r.db.Select(&files, query, model.FileOwnerGoalEntry, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// OrganizationAttachments returns the files attached to goal entries of a workspace
+func (r *fileRepository) OrganizationAttachments(organizationID string) ([]*model.File, error) {
+	var files []*model.File
+	query := `SELECT f.* FROM files f
+	          JOIN goal_entries e ON e.id = f.owner_id
+	          JOIN goals g ON g.id = e.goal_id
+	          WHERE f.owner_type = $1 AND g.organization_id = $2`
+
+	err := r.db.Select(&files, query, model.FileOwnerGoalEntry, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// OrganizationAttachmentsSize returns the bytes used by attachments of a workspace
+func (r *fileRepository) OrganizationAttachmentsSize(organizationID string) (int64, error) {
+	var size int64
+	query := `SELECT COALESCE(SUM(f.size), 0) FROM files f
+	          JOIN goal_entries e ON e.id = f.owner_id
+	          JOIN goals g ON g.id = e.goal_id
+	          WHERE f.owner_type = $1 AND g.organization_id = $2`
+
+	err := r.db.Get(&size, query, model.FileOwnerGoalEntry, organizationID)
+	return size, err
+}
+
+// ReassignAttachments hands the attachments a member uploaded in a workspace to another member
+// Used before deleting an account, files cascade with their uploader otherwise
+func (r *fileRepository) ReassignAttachments(organizationID, fromUserID, toUserID string) error {
+	query := `UPDATE files SET user_id = $1
+	          WHERE owner_type = $2 AND user_id = $3 AND owner_id IN (
+	              SELECT e.id FROM goal_entries e
+	              JOIN goals g ON g.id = e.goal_id
+	              WHERE g.organization_id = $4
+	          )`
+
+	_, err := r.db.Exec(query, toUserID, model.FileOwnerGoalEntry, fromUserID, organizationID)
+	return err
+}
+
 func (r *fileRepository) Delete(id string) error {
 	query := `DELETE FROM files WHERE id = $1`
 	_, err := r.db.Exec(query, id)
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"time"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -17,36 +16,46 @@ const (
 
 var (
 	ErrGoalNotFound = errors.New("goal not found")
+	// ErrGoalStepConflict means another request moved the goal's current step first
+	ErrGoalStepConflict = errors.New("goal step changed concurrently")
 )
 
 type GoalRepository interface {
 	Create(goal *model.Goal) error
-	ByID(userID, goalID string) (*model.Goal, error)
-	Goals(userID, sortBy string) ([]*model.Goal, error)
-	CountUserGoals(userID string) (int, error)
+	ByID(organizationID, goalID string) (*model.Goal, error)
+	BySlug(slug string) (*model.Goal, error)
+	Goals(organizationID, sortBy string) ([]*model.Goal, error)
+	CreatedBy(userID, sortBy string) ([]*model.Goal, error)
+	CountActiveGoals(organizationID string) (int, error)
 	Update(goal *model.Goal) error
-	Delete(userID, goalID string) error
+	UpdateProgress(goal *model.Goal, previousStep int) error
+	UpdateShare(goal *model.Goal) error
+	ReassignCreator(organizationID, fromUserID, toUserID string) error
+	Delete(organizationID, goalID string) error
 }
 
 type goalRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewGoalRepository(db *sqlx.DB) GoalRepository {
+func NewGoalRepository(db Querier) GoalRepository {
 	return &goalRepository{db: db}
 }
 
 func (r *goalRepository) Create(goal *model.Goal) error {
-	query := `INSERT INTO goals (id, user_id, title, description, status, current_step, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	query := `INSERT INTO goals (id, organization_id, user_id, title, description, status, current_step, target_steps, cadence, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	_, err := r.db.Exec(query,
 		goal.ID,
+		goal.OrganizationID,
 		goal.UserID,
 		goal.Title,
 		goal.Description,
 		goal.Status,
 		goal.CurrentStep,
+		goal.TargetSteps,
+		goal.Cadence,
 		goal.CreatedAt,
 		goal.UpdatedAt,
 	)
@@ -54,11 +63,11 @@ func (r *goalRepository) Create(goal *model.Goal) error {
 	return err
 }
 
-func (r *goalRepository) ByID(userID, goalID string) (*model.Goal, error) {
+func (r *goalRepository) ByID(organizationID, goalID string) (*model.Goal, error) {
 	goal := &model.Goal{}
-	query := `SELECT * FROM goals WHERE id = $1 AND user_id = $2`
+	query := `SELECT * FROM goals WHERE id = $1 AND organization_id = $2`
 
-	err := r.db.Get(goal, query, goalID, userID)
+	err := r.db.Get(goal, query, goalID, organizationID)
 	if err == sql.ErrNoRows {
 		return nil, ErrGoalNotFound
 	}
@@ -66,21 +75,37 @@ func (r *goalRepository) ByID(userID, goalID string) (*model.Goal, error) {
 	return goal, err
 }
 
-func (r *goalRepository) Goals(userID, sortBy string) ([]*model.Goal, error) {
+// BySlug returns a shared goal by its share slug, across all workspaces
+func (r *goalRepository) BySlug(slug string) (*model.Goal, error) {
+	goal := &model.Goal{}
+	query := `SELECT * FROM goals WHERE share_slug = $1`
+
+	err := r.db.Get(goal, query, slug)
+	if err == sql.ErrNoRows {
+		return nil, ErrGoalNotFound
+	}
+
+	return goal, err
+}
+
+func (r *goalRepository) Goals(organizationID, sortBy string) ([]*model.Goal, error) {
 	var goals []*model.Goal
 
-	// Validate and build ORDER BY clause
-	var orderBy string
-	switch sortBy {
-	case GoalSortProgress:
-		orderBy = "ORDER BY current_step DESC, updated_at DESC"
-	case GoalSortTitle:
-		orderBy = "ORDER BY LOWER(title) ASC"
-	default: // GoalSortRecent or empty
-		orderBy = "ORDER BY updated_at DESC"
+	query := `SELECT * FROM goals WHERE organization_id = $1 ` + goalOrderBy(sortBy)
+
+	err := r.db.Select(&goals, query, organizationID)
+	if err != nil {
+		return nil, err
 	}
 
-	query := `SELECT * FROM goals WHERE user_id = $1 ` + orderBy
+	return goals, nil
+}
+
+// CreatedBy returns the goals a user created, across all their workspaces
+func (r *goalRepository) CreatedBy(userID, sortBy string) ([]*model.Goal, error) {
+	var goals []*model.Goal
+
+	query := `SELECT * FROM goals WHERE user_id = $1 ` + goalOrderBy(sortBy)
 
 	err := r.db.Select(&goals, query, userID)
 	if err != nil {
@@ -90,26 +115,57 @@ func (r *goalRepository) Goals(userID, sortBy string) ([]*model.Goal, error) {
 	return goals, nil
 }
 
-func (r *goalRepository) CountUserGoals(userID string) (int, error) {
+func (r *goalRepository) CountActiveGoals(organizationID string) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM goals WHERE user_id = $1 AND status = $2`
-	err := r.db.QueryRow(query, userID, model.GoalStatusActive).Scan(&count)
+	query := `SELECT COUNT(*) FROM goals WHERE organization_id = $1 AND status = $2`
+	err := r.db.QueryRow(query, organizationID, model.GoalStatusActive).Scan(&count)
 	return count, err
 }
 
+// UpdateProgress saves current step and status only if the step is still previousStep
+func (r *goalRepository) UpdateProgress(goal *model.Goal, previousStep int) error {
+	query := `UPDATE goals
+	          SET status = $1, current_step = $2, updated_at = $3
+	          WHERE id = $4 AND organization_id = $5 AND current_step = $6`
+
+	result, err := r.db.Exec(query,
+		goal.Status,
+		goal.CurrentStep,
+		goal.UpdatedAt,
+		goal.ID,
+		goal.OrganizationID,
+		previousStep,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrGoalStepConflict
+	}
+
+	return nil
+}
+
 func (r *goalRepository) Update(goal *model.Goal) error {
 	query := `UPDATE goals
-	          SET title = $1, description = $2, status = $3, current_step = $4, updated_at = $5
-	          WHERE id = $6 AND user_id = $7`
+	          SET title = $1, description = $2, status = $3, current_step = $4, cadence = $5, updated_at = $6
+	          WHERE id = $7 AND organization_id = $8`
 
 	result, err := r.db.Exec(query,
 		goal.Title,
 		goal.Description,
 		goal.Status,
 		goal.CurrentStep,
+		goal.Cadence,
 		time.Now(),
 		goal.ID,
-		goal.UserID,
+		goal.OrganizationID,
 	)
 
 	if err != nil {
@@ -128,9 +184,38 @@ func (r *goalRepository) Update(goal *model.Goal) error {
 	return nil
 }
 
-func (r *goalRepository) Delete(userID, goalID string) error {
-	query := `DELETE FROM goals WHERE id = $1 AND user_id = $2`
-	result, err := r.db.Exec(query, goalID, userID)
+// UpdateShare stores the share slug and indexing setting of a goal
+func (r *goalRepository) UpdateShare(goal *model.Goal) error {
+	query := `UPDATE goals SET share_slug = $1, share_indexable = $2 WHERE id = $3 AND organization_id = $4`
+
+	result, err := r.db.Exec(query, goal.ShareSlug, goal.ShareIndexable, goal.ID, goal.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrGoalNotFound
+	}
+
+	return nil
+}
+
+// ReassignCreator hands the goals a member created in a workspace to another member
+// Used before deleting an account, goals cascade with their creator otherwise
+func (r *goalRepository) ReassignCreator(organizationID, fromUserID, toUserID string) error {
+	query := `UPDATE goals SET user_id = $1 WHERE organization_id = $2 AND user_id = $3`
+	_, err := r.db.Exec(query, toUserID, organizationID, fromUserID)
+	return err
+}
+
+func (r *goalRepository) Delete(organizationID, goalID string) error {
+	query := `DELETE FROM goals WHERE id = $1 AND organization_id = $2`
+	result, err := r.db.Exec(query, goalID, organizationID)
 
 	if err != nil {
 		return err
@@ -147,3 +232,15 @@ func (r *goalRepository) Delete(userID, goalID string) error {
 
 	return nil
 }
+
+// goalOrderBy returns the ORDER BY clause for a sort option, unknown options sort by recent
+func goalOrderBy(sortBy string) string {
+	switch sortBy {
+	case GoalSortProgress:
+		return "ORDER BY CAST(current_step AS REAL) / target_steps DESC, updated_at DESC"
+	case GoalSortTitle:
+		return "ORDER BY LOWER(title) ASC"
+	default: // GoalSortRecent or empty
+		return "ORDER BY updated_at DESC"
+	}
+}
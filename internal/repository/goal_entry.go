@@ -4,10 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -17,46 +17,76 @@ var (
 
 type GoalEntryRepository interface {
 	CreateEntries(goalID string, count int) error
+	InsertEntries(entries []*model.GoalEntry) error
 	Entries(goalID string) ([]*model.GoalEntry, error)
+	CompletedEntries(organizationID string) ([]*model.GoalEntry, error)
 	Entry(goalID string, step int) (*model.GoalEntry, error)
 	CompleteEntry(goalID string, step int) error
-	UpdateEntry(goalID string, step int, note string, completedAt *time.Time) error
+	UpdateEntry(goalID string, step int, note string, noteShared bool, completedAt *time.Time) error
 	UncompleteEntry(goalID string, step int) error
+	LastCompletedAt(goalID string) (*time.Time, error)
+	CountCompletedSince(goalID string, since time.Time) (int, error)
 }
 
 type goalEntryRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewGoalEntryRepository(db *sqlx.DB) GoalEntryRepository {
+func NewGoalEntryRepository(db Querier) GoalEntryRepository {
 	return &goalEntryRepository{db: db}
 }
 
-// CreateEntries creates bulk entries for a goal (typically 100)
+// entryInsertBatch is how many entries one INSERT stores, 7 parameters each
+// stays far below the parameter limits of SQLite and Postgres
+const entryInsertBatch = 250
+
+// CreateEntries creates bulk entries for a goal, one per step
 func (r *goalEntryRepository) CreateEntries(goalID string, count int) error {
-	if count <= 0 || count > 100 {
+	if count <= 0 || count > model.MaxGoalSteps {
 		return fmt.Errorf("invalid entry count: %d", count)
 	}
 
-	// Begin transaction for bulk insert
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	query := `INSERT INTO goal_entries (id, goal_id, step, completed, note, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6)`
-
 	now := time.Now()
-	for i := 1; i <= count; i++ {
-		_, err := tx.Exec(query, uuid.New().String(), goalID, i, false, "", now)
-		if err != nil {
-			return fmt.Errorf("failed to create entry %d: %w", i, err)
+	entries := make([]*model.GoalEntry, count)
+	for i := range entries {
+		entries[i] = &model.GoalEntry{
+			ID:        uuid.New().String(),
+			GoalID:    goalID,
+			Step:      i + 1,
+			CreatedAt: now,
 		}
 	}
 
-	return tx.Commit()
+	return r.InsertEntries(entries)
+}
+
+// InsertEntries stores entries as given, used to import goals with their progress
+// Rows are inserted in batches, one statement per entryInsertBatch entries,
+// so a goal with MaxGoalSteps steps holds the SQLite write lock only briefly.
+func (r *goalEntryRepository) InsertEntries(entries []*model.GoalEntry) error {
+	return inTx(r.db, func(tx Querier) error {
+		for start := 0; start < len(entries); start += entryInsertBatch {
+			batch := entries[start:min(start+entryInsertBatch, len(entries))]
+
+			var query strings.Builder
+			query.WriteString(`INSERT INTO goal_entries (id, goal_id, step, completed, note, completed_at, created_at) VALUES `)
+			args := make([]any, 0, len(batch)*7)
+			for i, entry := range batch {
+				if i > 0 {
+					query.WriteString(", ")
+				}
+				n := i * 7
+				fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+				args = append(args, entry.ID, entry.GoalID, entry.Step, entry.Completed, entry.Note, entry.CompletedAt, entry.CreatedAt)
+			}
+
+			_, err := tx.Exec(query.String(), args...)
+			if err != nil {
+				return fmt.Errorf("failed to create entries %d-%d: %w", batch[0].Step, batch[len(batch)-1].Step, err)
+			}
+		}
+		return nil
+	})
 }
 
 func (r *goalEntryRepository) Entries(goalID string) ([]*model.GoalEntry, error) {
@@ -71,6 +101,22 @@ func (r *goalEntryRepository) Entries(goalID string) ([]*model.GoalEntry, error)
 	return entries, nil
 }
 
+// CompletedEntries returns the completed entries of all goals in the workspace, oldest first
+func (r *goalEntryRepository) CompletedEntries(organizationID string) ([]*model.GoalEntry, error) {
+	var entries []*model.GoalEntry
+	query := `SELECT e.* FROM goal_entries e
+	          JOIN goals g ON g.id = e.goal_id
+	          WHERE g.organization_id = $1 AND e.completed = true AND e.completed_at IS NOT NULL
+	          ORDER BY e.completed_at ASC`
+
+	err := r.db.Select(&entries, query, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (r *goalEntryRepository) Entry(goalID string, step int) (*model.GoalEntry, error) {
 	entry := &model.GoalEntry{}
 	query := `SELECT * FROM goal_entries WHERE goal_id = $1 AND step = $2`
@@ -86,11 +132,12 @@ func (r *goalEntryRepository) Entry(goalID string, step int) (*model.GoalEntry,
 	return entry, nil
 }
 
+// CompleteEntry completes an open entry, ErrGoalEntryNotFound if it is already completed
 func (r *goalEntryRepository) CompleteEntry(goalID string, step int) error {
 	now := time.Now()
 	query := `UPDATE goal_entries
 	          SET completed = true, completed_at = $1
-	          WHERE goal_id = $2 AND step = $3`
+	          WHERE goal_id = $2 AND step = $3 AND completed = false`
 
 	result, err := r.db.Exec(query, now, goalID, step)
 	if err != nil {
@@ -109,12 +156,12 @@ func (r *goalEntryRepository) CompleteEntry(goalID string, step int) error {
 	return nil
 }
 
-func (r *goalEntryRepository) UpdateEntry(goalID string, step int, note string, completedAt *time.Time) error {
+func (r *goalEntryRepository) UpdateEntry(goalID string, step int, note string, noteShared bool, completedAt *time.Time) error {
 	query := `UPDATE goal_entries
-	          SET note = $1, completed_at = $2
-	          WHERE goal_id = $3 AND step = $4`
+	          SET note = $1, note_shared = $2, completed_at = $3
+	          WHERE goal_id = $4 AND step = $5`
 
-	result, err := r.db.Exec(query, note, completedAt, goalID, step)
+	result, err := r.db.Exec(query, note, noteShared, completedAt, goalID, step)
 	if err != nil {
 		return err
 	}
@@ -131,10 +178,11 @@ func (r *goalEntryRepository) UpdateEntry(goalID string, step int, note string,
 	return nil
 }
 
+// UncompleteEntry reopens a completed entry, ErrGoalEntryNotFound if it is still open
 func (r *goalEntryRepository) UncompleteEntry(goalID string, step int) error {
 	query := `UPDATE goal_entries
-	          SET completed = false, note = '', completed_at = NULL
-	          WHERE goal_id = $1 AND step = $2`
+	          SET completed = false, note = '', note_shared = false, completed_at = NULL
+	          WHERE goal_id = $1 AND step = $2 AND completed = true`
 
 	result, err := r.db.Exec(query, goalID, step)
 	if err != nil {
@@ -152,3 +200,29 @@ func (r *goalEntryRepository) UncompleteEntry(goalID string, step int) error {
 
 	return nil
 }
+
+// LastCompletedAt returns when the most recent step was completed, nil if none
+func (r *goalEntryRepository) LastCompletedAt(goalID string) (*time.Time, error) {
+	var completedAt time.Time
+	query := `SELECT completed_at FROM goal_entries
+	          WHERE goal_id = $1 AND completed = true AND completed_at IS NOT NULL
+	          ORDER BY completed_at DESC LIMIT 1`
+
+	err := r.db.Get(&completedAt, query, goalID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &completedAt, nil
+}
+
+func (r *goalEntryRepository) CountCompletedSince(goalID string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM goal_entries
+	          WHERE goal_id = $1 AND completed = true AND completed_at >= $2`
+	err := r.db.QueryRow(query, goalID, since).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+)
+
+// invitationSelect loads invitations with their organization, inviter and token
+// Only pending invitations are returned: not accepted, token unused and not expired
+const invitationSelect = `
+	SELECT i.*, o.name AS organization_name, COALESCE(p.name, '') AS inviter_name, t.token, t.expires_at
+	FROM invitations i
+	JOIN organizations o ON o.id = i.organization_id
+	JOIN tokens t ON t.id = i.token_id
+	LEFT JOIN profiles p ON p.user_id = i.invited_by
+	WHERE i.accepted_at IS NULL AND t.used_at IS NULL AND t.expires_at > $1
+`
+
+type InvitationRepository interface {
+	Create(invitation *model.Invitation) error
+	ByToken(token string) (*model.Invitation, error)
+	Pending(organizationID string) ([]*model.Invitation, error)
+	PendingByEmail(email string) ([]*model.Invitation, error)
+	CountPending(organizationID string) (int, error)
+	MarkAccepted(id string, acceptedAt time.Time) error
+	Delete(organizationID, id string) error
+}
+
+type invitationRepository struct {
+	db Querier
+}
+
+func NewInvitationRepository(db Querier) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(invitation *model.Invitation) error {
+	if invitation.ID == "" {
+		invitation.ID = uuid.New().String()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO invitations (id, organization_id, email, role, token_id, invited_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		invitation.ID,
+		invitation.OrganizationID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenID,
+		invitation.InvitedBy,
+		invitation.CreatedAt,
+	)
+	return err
+}
+
+// ByToken returns the pending invitation for a link token
+func (r *invitationRepository) ByToken(token string) (*model.Invitation, error) {
+	invitation := &model.Invitation{}
+	query := invitationSelect + ` AND t.token = $2`
+
+	err := r.db.Get(invitation, query, time.Now(), token)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationNotFound
+	}
+
+	return invitation, err
+}
+
+// Pending returns the organization's open invitations, newest first
+func (r *invitationRepository) Pending(organizationID string) ([]*model.Invitation, error) {
+	var invitations []*model.Invitation
+	query := invitationSelect + ` AND i.organization_id = $2 ORDER BY i.created_at DESC`
+
+	err := r.db.Select(&invitations, query, time.Now(), organizationID)
+	return invitations, err
+}
+
+// PendingByEmail returns the open invitations sent to an email, newest first
+func (r *invitationRepository) PendingByEmail(email string) ([]*model.Invitation, error) {
+	var invitations []*model.Invitation
+	query := invitationSelect + ` AND i.email = $2 ORDER BY i.created_at DESC`
+
+	err := r.db.Select(&invitations, query, time.Now(), email)
+	return invitations, err
+}
+
+// CountPending counts the organization's open invitations, each one holds a seat
+func (r *invitationRepository) CountPending(organizationID string) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM invitations i
+		JOIN tokens t ON t.id = i.token_id
+		WHERE i.organization_id = $1 AND i.accepted_at IS NULL AND t.used_at IS NULL AND t.expires_at > $2
+	`
+	err := r.db.QueryRow(query, organizationID, time.Now()).Scan(&count)
+	return count, err
+}
+
+// MarkAccepted records the acceptance, an invitation can only be accepted once
+func (r *invitationRepository) MarkAccepted(id string, acceptedAt time.Time) error {
+	query := `UPDATE invitations SET accepted_at = $1 WHERE id = $2 AND accepted_at IS NULL`
+	result, err := r.db.Exec(query, acceptedAt, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// Delete revokes an invitation, scoped to the organization
+func (r *invitationRepository) Delete(organizationID, id string) error {
+	query := `DELETE FROM invitations WHERE id = $1 AND organization_id = $2`
+	result, err := r.db.Exec(query, id, organizationID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
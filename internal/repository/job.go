@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrNoJobAvailable = errors.New("no job available")
+)
+
+type JobRepository interface {
+	Create(job *model.Job) error
+	Claim(now, staleBefore time.Time) (*model.Job, error)
+	Complete(id string) error
+	Retry(id string, runAt time.Time, lastError string) error
+	Dead(id string, lastError string) error
+	DeleteCompletedBefore(before time.Time) (int64, error)
+}
+
+type jobRepository struct {
+	db Querier
+}
+
+func NewJobRepository(db Querier) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(job *model.Job) error {
+	query := `INSERT INTO jobs (id, type, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.Exec(query,
+		job.ID,
+		job.Type,
+		job.Payload,
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.LastError,
+		job.RunAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+
+	return err
+}
+
+// Claim locks the oldest due job and increments its attempts
+// Jobs stuck in running since before staleBefore (crashed worker) are claimed again.
+// Uses a conditional UPDATE instead of SELECT ... FOR UPDATE so it works on sqlite and postgres,
+// when another worker wins the race the next candidate is tried.
+func (r *jobRepository) Claim(now, staleBefore time.Time) (*model.Job, error) {
+	for range 5 {
+		var id string
+		query := `SELECT id FROM jobs
+		          WHERE (status = $1 AND run_at <= $2) OR (status = $3 AND locked_at < $4)
+		          ORDER BY run_at ASC LIMIT 1`
+
+		err := r.db.Get(&id, query, model.JobStatusPending, now, model.JobStatusRunning, staleBefore)
+		if err == sql.ErrNoRows {
+			return nil, ErrNoJobAvailable
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		update := `UPDATE jobs
+		           SET status = $1, attempts = attempts + 1, locked_at = $2, updated_at = $2
+		           WHERE id = $3 AND ((status = $4 AND run_at <= $5) OR (status = $1 AND locked_at < $6))`
+
+		result, err := r.db.Exec(update, model.JobStatusRunning, now, id, model.JobStatusPending, now, staleBefore)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		if rows == 0 {
+			continue // Claimed by another worker
+		}
+
+		job := &model.Job{}
+		err = r.db.Get(job, `SELECT * FROM jobs WHERE id = $1`, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return job, nil
+	}
+
+	return nil, ErrNoJobAvailable
+}
+
+func (r *jobRepository) Complete(id string) error {
+	query := `UPDATE jobs
+	          SET status = $1, last_error = '', locked_at = NULL, updated_at = $2
+	          WHERE id = $3`
+	_, err := r.db.Exec(query, model.JobStatusCompleted, time.Now(), id)
+	return err
+}
+
+func (r *jobRepository) Retry(id string, runAt time.Time, lastError string) error {
+	query := `UPDATE jobs
+	          SET status = $1, run_at = $2, last_error = $3, locked_at = NULL, updated_at = $4
+	          WHERE id = $5`
+	_, err := r.db.Exec(query, model.JobStatusPending, runAt, lastError, time.Now(), id)
+	return err
+}
+
+func (r *jobRepository) Dead(id string, lastError string) error {
+	query := `UPDATE jobs
+	          SET status = $1, last_error = $2, locked_at = NULL, updated_at = $3
+	          WHERE id = $4`
+	_, err := r.db.Exec(query, model.JobStatusDead, lastError, time.Now(), id)
+	return err
+}
+
+// DeleteCompletedBefore removes finished jobs, dead jobs are kept
+func (r *jobRepository) DeleteCompletedBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM jobs WHERE status = $1 AND updated_at < $2`
+	result, err := r.db.Exec(query, model.JobStatusCompleted, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+type KnownDeviceRepository interface {
+	Create(device *model.KnownDevice) error
+	Touch(userID, fingerprint, userAgent, ipAddress string, lastSeenAt time.Time) (bool, error)
+	CountByUser(userID string) (int, error)
+	DeleteByUser(userID string) error
+}
+
+type knownDeviceRepository struct {
+	db Querier
+}
+
+func NewKnownDeviceRepository(db Querier) KnownDeviceRepository {
+	return &knownDeviceRepository{db: db}
+}
+
+func (r *knownDeviceRepository) Create(device *model.KnownDevice) error {
+	if device.ID == "" {
+		device.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	if device.LastSeenAt.IsZero() {
+		device.LastSeenAt = now
+	}
+
+	query := `
+		INSERT INTO known_devices (id, user_id, fingerprint, user_agent, ip_address, last_seen_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		device.ID,
+		device.UserID,
+		device.Fingerprint,
+		device.UserAgent,
+		device.IPAddress,
+		device.LastSeenAt,
+		device.CreatedAt,
+	)
+	return err
+}
+
+// Touch updates a known device with the latest sign-in, false if the device is unknown
+func (r *knownDeviceRepository) Touch(userID, fingerprint, userAgent, ipAddress string, lastSeenAt time.Time) (bool, error) {
+	query := `
+		UPDATE known_devices
+		SET user_agent = $1, ip_address = $2, last_seen_at = $3
+		WHERE user_id = $4 AND fingerprint = $5
+	`
+	result, err := r.db.Exec(query, userAgent, ipAddress, lastSeenAt, userID, fingerprint)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *knownDeviceRepository) CountByUser(userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM known_devices WHERE user_id = $1`
+
+	err := r.db.Get(&count, query, userID)
+	return count, err
+}
+
+func (r *knownDeviceRepository) DeleteByUser(userID string) error {
+	query := `DELETE FROM known_devices WHERE user_id = $1`
+
+	_, err := r.db.Exec(query, userID)
+	return err
+}
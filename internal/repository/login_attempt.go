@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrLoginAttemptNotFound = errors.New("login attempt not found")
+)
+
+type LoginAttemptRepository interface {
+	ByEmail(email string) (*model.LoginAttempt, error)
+	RecordFailure(email string, failedAt, windowStart time.Time) (*model.LoginAttempt, error)
+	Lock(email string, until, now time.Time) (bool, error)
+	Reset(email string) error
+}
+
+type loginAttemptRepository struct {
+	db Querier
+}
+
+func NewLoginAttemptRepository(db Querier) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) ByEmail(email string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	query := `SELECT * FROM login_attempts WHERE email = $1`
+
+	err := r.db.Get(&attempt, query, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoginAttemptNotFound
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordFailure counts a failed sign-in in a single statement, so concurrent
+// attempts on different instances can't lose updates
+// Failures before windowStart are forgotten and counting starts over.
+func (r *loginAttemptRepository) RecordFailure(email string, failedAt, windowStart time.Time) (*model.LoginAttempt, error) {
+	query := `
+		INSERT INTO login_attempts (email, failed_count, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (email) DO UPDATE SET
+			failed_count = CASE WHEN login_attempts.last_failed_at < $3 THEN 1 ELSE login_attempts.failed_count + 1 END,
+			last_failed_at = $2
+	`
+	_, err := r.db.Exec(query, email, failedAt, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ByEmail(email)
+}
+
+// Lock locks the email until the given time unless it is already locked
+// Returns false if another request locked it first, so only one lockout email goes out.
+func (r *loginAttemptRepository) Lock(email string, until, now time.Time) (bool, error) {
+	query := `
+		UPDATE login_attempts SET locked_until = $1
+		WHERE email = $2 AND (locked_until IS NULL OR locked_until < $3)
+	`
+	result, err := r.db.Exec(query, until, email, now)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *loginAttemptRepository) Reset(email string) error {
+	query := `DELETE FROM login_attempts WHERE email = $1`
+
+	_, err := r.db.Exec(query, email)
+	return err
+}
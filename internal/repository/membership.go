@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrMembershipNotFound = errors.New("membership not found")
+)
+
+type MembershipRepository interface {
+	Create(membership *model.Membership) error
+	ByID(organizationID, id string) (*model.Membership, error)
+	ByOrganizationAndUser(organizationID, userID string) (*model.Membership, error)
+	ByUserID(userID string) ([]*model.Membership, error)
+	Members(organizationID string) ([]*model.Membership, error)
+	Count(organizationID string) (int, error)
+	CountOwners(organizationID string) (int, error)
+	UpdateRole(organizationID, id, role string) error
+	Delete(organizationID, id string) error
+}
+
+type membershipRepository struct {
+	db Querier
+}
+
+func NewMembershipRepository(db Querier) MembershipRepository {
+	return &membershipRepository{db: db}
+}
+
+func (r *membershipRepository) Create(membership *model.Membership) error {
+	if membership.ID == "" {
+		membership.ID = uuid.New().String()
+	}
+	if membership.CreatedAt.IsZero() {
+		membership.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO memberships (id, organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(query,
+		membership.ID,
+		membership.OrganizationID,
+		membership.UserID,
+		membership.Role,
+		membership.CreatedAt,
+	)
+	return err
+}
+
+// ByID returns a membership of the organization with the member's email and name
+func (r *membershipRepository) ByID(organizationID, id string) (*model.Membership, error) {
+	membership := &model.Membership{}
+	query := `
+		SELECT m.*, u.email, COALESCE(p.name, '') AS name
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN profiles p ON p.user_id = m.user_id
+		WHERE m.id = $1 AND m.organization_id = $2
+	`
+
+	err := r.db.Get(membership, query, id, organizationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrMembershipNotFound
+	}
+
+	return membership, err
+}
+
+// ByOrganizationAndUser returns the user's membership with the organization's name
+func (r *membershipRepository) ByOrganizationAndUser(organizationID, userID string) (*model.Membership, error) {
+	membership := &model.Membership{}
+	query := `
+		SELECT m.*, o.name AS organization_name, o.personal
+		FROM memberships m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.organization_id = $1 AND m.user_id = $2
+	`
+
+	err := r.db.Get(membership, query, organizationID, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrMembershipNotFound
+	}
+
+	return membership, err
+}
+
+// ByUserID returns all workspaces of the user, personal first, then by name
+func (r *membershipRepository) ByUserID(userID string) ([]*model.Membership, error) {
+	var memberships []*model.Membership
+	query := `
+		SELECT m.*, o.name AS organization_name, o.personal
+		FROM memberships m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = $1
+		ORDER BY o.personal DESC, LOWER(o.name) ASC
+	`
+
+	err := r.db.Select(&memberships, query, userID)
+	return memberships, err
+}
+
+// Members returns the organization's members with their email and name, oldest first
+func (r *membershipRepository) Members(organizationID string) ([]*model.Membership, error) {
+	var memberships []*model.Membership
+	query := `
+		SELECT m.*, u.email, COALESCE(p.name, '') AS name
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN profiles p ON p.user_id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at ASC
+	`
+
+	err := r.db.Select(&memberships, query, organizationID)
+	return memberships, err
+}
+
+func (r *membershipRepository) Count(organizationID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM memberships WHERE organization_id = $1`
+	err := r.db.QueryRow(query, organizationID).Scan(&count)
+	return count, err
+}
+
+func (r *membershipRepository) CountOwners(organizationID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM memberships WHERE organization_id = $1 AND role = $2`
+	err := r.db.QueryRow(query, organizationID, model.RoleOwner).Scan(&count)
+	return count, err
+}
+
+func (r *membershipRepository) UpdateRole(organizationID, id, role string) error {
+	query := `UPDATE memberships SET role = $1 WHERE id = $2 AND organization_id = $3`
+	result, err := r.db.Exec(query, role, id, organizationID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrMembershipNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a member, scoped to the organization so one workspace can't remove another's members
+func (r *membershipRepository) Delete(organizationID, id string) error {
+	query := `DELETE FROM memberships WHERE id = $1 AND organization_id = $2`
+	result, err := r.db.Exec(query, id, organizationID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrMembershipNotFound
+	}
+
+	return nil
+}
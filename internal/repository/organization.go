@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+)
+
+type OrganizationRepository interface {
+	Create(organization *model.Organization) error
+	ByID(id string) (*model.Organization, error)
+	UpdateName(id, name string) error
+	Lock(id string) error
+	Delete(id string) error
+}
+
+type organizationRepository struct {
+	db Querier
+}
+
+func NewOrganizationRepository(db Querier) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(organization *model.Organization) error {
+	if organization.ID == "" {
+		organization.ID = uuid.New().String()
+	}
+	if organization.CreatedAt.IsZero() {
+		organization.CreatedAt = time.Now()
+	}
+	if organization.UpdatedAt.IsZero() {
+		organization.UpdatedAt = organization.CreatedAt
+	}
+
+	query := `
+		INSERT INTO organizations (id, name, personal, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(query,
+		organization.ID,
+		organization.Name,
+		organization.Personal,
+		organization.CreatedAt,
+		organization.UpdatedAt,
+	)
+	return err
+}
+
+func (r *organizationRepository) ByID(id string) (*model.Organization, error) {
+	organization := &model.Organization{}
+	query := `SELECT * FROM organizations WHERE id = $1`
+
+	err := r.db.Get(organization, query, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrganizationNotFound
+	}
+
+	return organization, err
+}
+
+func (r *organizationRepository) UpdateName(id, name string) error {
+	query := `UPDATE organizations SET name = $1, updated_at = $2 WHERE id = $3`
+	result, err := r.db.Exec(query, name, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+// Lock takes the organization's row lock until the transaction ends
+// A no-op write instead of SELECT ... FOR UPDATE, which SQLite doesn't have:
+// it takes the database write lock on SQLite and the row lock on Postgres.
+func (r *organizationRepository) Lock(id string) error {
+	query := `UPDATE organizations SET updated_at = updated_at WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the organization
+// Foreign key CASCADE removes its memberships, invitations, goals and subscription
+func (r *organizationRepository) Delete(id string) error {
+	query := `DELETE FROM organizations WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrPasskeyNotFound = errors.New("passkey not found")
+)
+
+type PasskeyRepository interface {
+	Create(passkey *model.Passkey) error
+	ByCredentialID(credentialID string) (*model.Passkey, error)
+	ByUserID(userID string) ([]*model.Passkey, error)
+	RecordUse(id string, signCount int64, flags int, usedAt time.Time) error
+	Rename(userID, id, name string) error
+	Delete(userID, id string) error
+}
+
+type passkeyRepository struct {
+	db Querier
+}
+
+func NewPasskeyRepository(db Querier) PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+func (r *passkeyRepository) Create(passkey *model.Passkey) error {
+	if passkey.ID == "" {
+		passkey.ID = uuid.New().String()
+	}
+	if passkey.CreatedAt.IsZero() {
+		passkey.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO passkeys (id, user_id, name, credential_id, public_key, attestation_type, aaguid, sign_count, flags, transports, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.Exec(query,
+		passkey.ID,
+		passkey.UserID,
+		passkey.Name,
+		passkey.CredentialID,
+		passkey.PublicKey,
+		passkey.AttestationType,
+		passkey.AAGUID,
+		passkey.SignCount,
+		passkey.Flags,
+		passkey.Transports,
+		passkey.CreatedAt,
+	)
+	return err
+}
+
+func (r *passkeyRepository) ByCredentialID(credentialID string) (*model.Passkey, error) {
+	passkey := &model.Passkey{}
+	query := `SELECT * FROM passkeys WHERE credential_id = $1`
+
+	err := r.db.Get(passkey, query, credentialID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPasskeyNotFound
+	}
+
+	return passkey, err
+}
+
+// ByUserID returns the user's passkeys, oldest first
+func (r *passkeyRepository) ByUserID(userID string) ([]*model.Passkey, error) {
+	var passkeys []*model.Passkey
+	query := `SELECT * FROM passkeys WHERE user_id = $1 ORDER BY created_at ASC`
+
+	err := r.db.Select(&passkeys, query, userID)
+	return passkeys, err
+}
+
+// RecordUse stores the new signature counter and flags after a successful sign-in
+func (r *passkeyRepository) RecordUse(id string, signCount int64, flags int, usedAt time.Time) error {
+	query := `UPDATE passkeys SET sign_count = $1, flags = $2, last_used_at = $3 WHERE id = $4`
+	_, err := r.db.Exec(query, signCount, flags, usedAt, id)
+	return err
+}
+
+func (r *passkeyRepository) Rename(userID, id, name string) error {
+	query := `UPDATE passkeys SET name = $1 WHERE id = $2 AND user_id = $3`
+	result, err := r.db.Exec(query, name, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a passkey, scoped to the user so one user can't remove another's passkey
+func (r *passkeyRepository) Delete(userID, id string) error {
+	query := `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPasskeyNotFound
+	}
+
+	return nil
+}
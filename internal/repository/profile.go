@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -14,13 +13,17 @@ type ProfileRepository interface {
 	ByUserID(userID string) (*model.Profile, error)
 	Create(profile *model.Profile) error
 	UpdateName(userID, name string) error
+	UpdateNotificationSettings(profile *model.Profile) error
+	NotificationProfiles() ([]*model.Profile, error)
+	ClaimReminder(userID string, sentAt, sentBefore time.Time) (bool, error)
+	ClaimDigest(userID string, sentAt, sentBefore time.Time) (bool, error)
 }
 
 type profileRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewProfileRepository(db *sqlx.DB) ProfileRepository {
+func NewProfileRepository(db Querier) ProfileRepository {
 	return &profileRepository{db: db}
 }
 
@@ -78,3 +81,52 @@ func (r *profileRepository) UpdateName(userID, name string) error {
 
 	return nil
 }
+
+func (r *profileRepository) UpdateNotificationSettings(profile *model.Profile) error {
+	profile.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		UPDATE profiles
+		SET timezone = $1, reminders_enabled = $2, reminder_hour = $3, reminder_inactive_days = $4,
+		    digest_enabled = $5, digest_weekday = $6, updated_at = $7
+		WHERE user_id = $8
+	`, profile.Timezone, profile.RemindersEnabled, profile.ReminderHour, profile.ReminderInactiveDays,
+		profile.DigestEnabled, profile.DigestWeekday, profile.UpdatedAt, profile.UserID)
+
+	return err
+}
+
+// NotificationProfiles returns all profiles with reminders or the weekly digest enabled
+func (r *profileRepository) NotificationProfiles() ([]*model.Profile, error) {
+	var profiles []*model.Profile
+	err := r.db.Select(&profiles, `SELECT * FROM profiles WHERE reminders_enabled OR digest_enabled`)
+	return profiles, err
+}
+
+// ClaimReminder records a reminder as sent unless one was already sent at or after sentBefore
+// Returns false if another scheduler instance claimed it first. The check and the update
+// are one statement, so only one instance sends the email.
+func (r *profileRepository) ClaimReminder(userID string, sentAt, sentBefore time.Time) (bool, error) {
+	query := `UPDATE profiles SET last_reminder_at = $1 WHERE user_id = $2 AND (last_reminder_at IS NULL OR last_reminder_at < $3)`
+	return r.claim(query, userID, sentAt, sentBefore)
+}
+
+// ClaimDigest is ClaimReminder for the weekly digest
+func (r *profileRepository) ClaimDigest(userID string, sentAt, sentBefore time.Time) (bool, error) {
+	query := `UPDATE profiles SET last_digest_at = $1 WHERE user_id = $2 AND (last_digest_at IS NULL OR last_digest_at < $3)`
+	return r.claim(query, userID, sentAt, sentBefore)
+}
+
+func (r *profileRepository) claim(query, userID string, sentAt, sentBefore time.Time) (bool, error) {
+	result, err := r.db.Exec(query, sentAt, userID, sentBefore)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
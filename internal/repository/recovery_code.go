@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceAll(userID string, codeHashes []string) error
+	Consume(userID, codeHash string) error
+	CountUnused(userID string) (int, error)
+	DeleteByUser(userID string) error
+}
+
+type recoveryCodeRepository struct {
+	db Querier
+}
+
+func NewRecoveryCodeRepository(db Querier) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// ReplaceAll deletes existing codes and stores a new set in one transaction
+// Old codes stop working as soon as new ones are generated
+func (r *recoveryCodeRepository) ReplaceAll(userID string, codeHashes []string) error {
+	query := `INSERT INTO recovery_codes (id, user_id, code_hash, created_at)
+	          VALUES ($1, $2, $3, $4)`
+
+	now := time.Now()
+	return inTx(r.db, func(tx Querier) error {
+		_, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+
+		for i, hash := range codeHashes {
+			_, err := tx.Exec(query, uuid.New().String(), userID, hash, now)
+			if err != nil {
+				return fmt.Errorf("failed to create recovery code %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Consume atomically marks an unused code as used
+// Only the first request succeeds, replays get ErrRecoveryCodeNotFound
+func (r *recoveryCodeRepository) Consume(userID, codeHash string) error {
+	query := `UPDATE recovery_codes
+	          SET used_at = $1
+	          WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+func (r *recoveryCodeRepository) CountUnused(userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	err := r.db.QueryRow(query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(userID string) error {
+	query := `DELETE FROM recovery_codes WHERE user_id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
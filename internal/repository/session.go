@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	ByID(id string) (*model.Session, error)
+	ByUserID(userID string) ([]*model.Session, error)
+	Touch(id string, lastSeenAt time.Time) error
+	Delete(userID, id string) error
+	DeleteByUser(userID string) error
+	DeleteByUserExcept(userID, keepID string) error
+}
+
+type sessionRepository struct {
+	db Querier
+}
+
+func NewSessionRepository(db Querier) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *model.Session) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.LastSeenAt.IsZero() {
+		session.LastSeenAt = now
+	}
+
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at, last_seen_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+		session.LastSeenAt,
+		session.CreatedAt,
+	)
+	return err
+}
+
+func (r *sessionRepository) ByID(id string) (*model.Session, error) {
+	session := &model.Session{}
+	query := `SELECT * FROM sessions WHERE id = $1`
+
+	err := r.db.Get(session, query, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+
+	return session, err
+}
+
+// ByUserID returns the user's unexpired sessions, most recently active first
+func (r *sessionRepository) ByUserID(userID string) ([]*model.Session, error) {
+	var sessions []*model.Session
+	query := `SELECT * FROM sessions WHERE user_id = $1 AND expires_at > $2 ORDER BY last_seen_at DESC`
+
+	err := r.db.Select(&sessions, query, userID, time.Now())
+	return sessions, err
+}
+
+func (r *sessionRepository) Touch(id string, lastSeenAt time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, lastSeenAt, id)
+	return err
+}
+
+// Delete removes a single session, scoped to the user so one user can't revoke another's session
+func (r *sessionRepository) Delete(userID, id string) error {
+	query := `DELETE FROM sessions WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) DeleteByUser(userID string) error {
+	query := `DELETE FROM sessions WHERE user_id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+func (r *sessionRepository) DeleteByUserExcept(userID, keepID string) error {
+	query := `DELETE FROM sessions WHERE user_id = $1 AND id != $2`
+	_, err := r.db.Exec(query, userID, keepID)
+	return err
+}
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"errors"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -14,34 +13,35 @@ var (
 
 type SubscriptionRepository interface {
 	Create(sub *model.Subscription) error
-	ByUserID(userID string) (*model.Subscription, error)
+	ByOrganizationID(organizationID string) (*model.Subscription, error)
+	PersonalByUserID(userID string) (*model.Subscription, error)
 	ByProviderSubscriptionID(providerSubID string) (*model.Subscription, error)
 	ByProviderCustomerID(providerCustomerID string) (*model.Subscription, error)
 	Update(sub *model.Subscription) error
 }
 
 type subscriptionRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewSubscriptionRepository(db *sqlx.DB) SubscriptionRepository {
+func NewSubscriptionRepository(db Querier) SubscriptionRepository {
 	return &subscriptionRepository{db: db}
 }
 
 func (r *subscriptionRepository) Create(sub *model.Subscription) error {
 	query := `
 		INSERT INTO subscriptions (
-			id, user_id, plan_id, status, provider,
+			id, organization_id, plan_id, status, provider,
 			provider_customer_id, provider_subscription_id,
-			current_period_end, amount, currency, interval,
+			current_period_end, amount, currency, interval, seats,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	_, err := r.db.Exec(
 		query,
 		sub.ID,
-		sub.UserID,
+		sub.OrganizationID,
 		sub.PlanID,
 		sub.Status,
 		sub.Provider,
@@ -51,6 +51,7 @@ func (r *subscriptionRepository) Create(sub *model.Subscription) error {
 		sub.Amount,
 		sub.Currency,
 		sub.Interval,
+		sub.Seats,
 		sub.CreatedAt,
 		sub.UpdatedAt,
 	)
@@ -58,9 +59,31 @@ func (r *subscriptionRepository) Create(sub *model.Subscription) error {
 	return err
 }
 
-func (r *subscriptionRepository) ByUserID(userID string) (*model.Subscription, error) {
+func (r *subscriptionRepository) ByOrganizationID(organizationID string) (*model.Subscription, error) {
 	sub := &model.Subscription{}
-	query := `SELECT * FROM subscriptions WHERE user_id = $1`
+	query := `SELECT * FROM subscriptions WHERE organization_id = $1`
+
+	err := r.db.Get(sub, query, organizationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// PersonalByUserID returns the subscription of the user's personal workspace
+func (r *subscriptionRepository) PersonalByUserID(userID string) (*model.Subscription, error) {
+	sub := &model.Subscription{}
+	query := `
+		SELECT s.*
+		FROM subscriptions s
+		JOIN organizations o ON o.id = s.organization_id AND o.personal = TRUE
+		JOIN memberships m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+	`
 
 	err := r.db.Get(sub, query, userID)
 	if err == sql.ErrNoRows {
@@ -115,8 +138,9 @@ func (r *subscriptionRepository) Update(sub *model.Subscription) error {
 		    amount = $7,
 		    currency = $8,
 		    interval = $9,
-		    updated_at = $10
-		WHERE id = $11
+		    seats = $10,
+		    updated_at = $11
+		WHERE id = $12
 	`
 
 	result, err := r.db.Exec(
@@ -130,6 +154,7 @@ func (r *subscriptionRepository) Update(sub *model.Subscription) error {
 		sub.Amount,
 		sub.Currency,
 		sub.Interval,
+		sub.Seats,
 		sub.UpdatedAt,
 		sub.ID,
 	)
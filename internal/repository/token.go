@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -20,13 +19,14 @@ type TokenRepository interface {
 	Create(token *model.Token) error
 	ConsumeToken(token string) (*model.Token, error)
 	DeleteByUserAndType(userID, tokenType string) error
+	Delete(id string) error
 }
 
 type tokenRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewTokenRepository(db *sqlx.DB) TokenRepository {
+func NewTokenRepository(db Querier) TokenRepository {
 	return &tokenRepository{db: db}
 }
 
@@ -88,6 +88,13 @@ func (r *tokenRepository) DeleteByUserAndType(userID, tokenType string) error {
 	return err
 }
 
+// Delete removes a single token, e.g. one whose email could not be sent
+func (r *tokenRepository) Delete(id string) error {
+	query := `DELETE FROM tokens WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
 // CleanupExpired removes used and expired tokens older than the given duration.
 // This is an optional maintenance operation for production environments.
 //
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	txMaxAttempts = 5
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// Querier is what repositories run their queries on, a *sqlx.DB or a *sqlx.Tx
+type Querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Get(dest any, query string, args ...any) error
+	Select(dest any, query string, args ...any) error
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Repositories are all repositories bound to one transaction
+type Repositories struct {
+	AdminAuditLogs    AdminAuditLogRepository
+	APITokens         APITokenRepository
+	AuditEvents       AuditEventRepository
+	Files             FileRepository
+	Goals             GoalRepository
+	GoalEntries       GoalEntryRepository
+	Invitations       InvitationRepository
+	Jobs              JobRepository
+	KnownDevices      KnownDeviceRepository
+	LoginAttempts     LoginAttemptRepository
+	Memberships       MembershipRepository
+	Organizations     OrganizationRepository
+	Passkeys          PasskeyRepository
+	Profiles          ProfileRepository
+	RecoveryCodes     RecoveryCodeRepository
+	Sessions          SessionRepository
+	Subscriptions     SubscriptionRepository
+	Tokens            TokenRepository
+	Users             UserRepository
+	UserIdentities    UserIdentityRepository
+	WebhookDeliveries WebhookDeliveryRepository
+	WebhookEndpoints  WebhookEndpointRepository
+}
+
+func newRepositories(db Querier) *Repositories {
+	return &Repositories{
+		AdminAuditLogs:    NewAdminAuditLogRepository(db),
+		APITokens:         NewAPITokenRepository(db),
+		AuditEvents:       NewAuditEventRepository(db),
+		Files:             NewFileRepository(db),
+		Goals:             NewGoalRepository(db),
+		GoalEntries:       NewGoalEntryRepository(db),
+		Invitations:       NewInvitationRepository(db),
+		Jobs:              NewJobRepository(db),
+		KnownDevices:      NewKnownDeviceRepository(db),
+		LoginAttempts:     NewLoginAttemptRepository(db),
+		Memberships:       NewMembershipRepository(db),
+		Organizations:     NewOrganizationRepository(db),
+		Passkeys:          NewPasskeyRepository(db),
+		Profiles:          NewProfileRepository(db),
+		RecoveryCodes:     NewRecoveryCodeRepository(db),
+		Sessions:          NewSessionRepository(db),
+		Subscriptions:     NewSubscriptionRepository(db),
+		Tokens:            NewTokenRepository(db),
+		Users:             NewUserRepository(db),
+		UserIdentities:    NewUserIdentityRepository(db),
+		WebhookDeliveries: NewWebhookDeliveryRepository(db),
+		WebhookEndpoints:  NewWebhookEndpointRepository(db),
+	}
+}
+
+// TxManager runs multi-row operations atomically
+type TxManager interface {
+	// WithTx commits when fn returns nil and rolls back otherwise
+	// fn may run more than once when SQLite is busy, so it must not have side
+	// effects outside the database (emails, webhooks) and must not call WithTx.
+	WithTx(fn func(tx *Repositories) error) error
+}
+
+type txManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sqlx.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithTx(fn func(tx *Repositories) error) error {
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err = m.run(fn)
+		if !isBusy(err) {
+			return err
+		}
+
+		slog.Warn("database busy, retrying transaction", "error", err, "attempt", attempt)
+		time.Sleep(time.Duration(attempt) * txRetryDelay)
+	}
+	return fmt.Errorf("transaction failed after %d attempts: %w", txMaxAttempts, err)
+}
+
+func (m *txManager) run(fn func(tx *Repositories) error) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = fn(newRepositories(tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inTx runs fn in its own transaction, or directly when the repository is already bound to one
+func inTx(db Querier, fn func(q Querier) error) error {
+	sqlDB, ok := db.(*sqlx.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := sqlDB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isBusy reports SQLITE_BUSY and SQLITE_LOCKED, including their extended codes
+// Postgres waits for row locks instead, so it never gets here.
+func isBusy(err error) bool {
+	var sqliteErr interface{ Code() int }
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == 5 || code == 6
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/templui/goilerplate/internal/model"
 )
 
@@ -20,14 +19,18 @@ type UserRepository interface {
 	ByID(id string) (*model.User, error)
 	ByEmail(email string) (*model.User, error)
 	Update(user *model.User) error
+	AcceptTOTPCounter(id string, counter int64) (bool, error)
+	SetAdmin(id string, isAdmin bool) error
+	Search(search string, limit, offset int) ([]*model.AdminUser, error)
+	CountSearch(search string) (int, error)
 	Delete(id string) error
 }
 
 type userRepository struct {
-	db *sqlx.DB
+	db Querier
 }
 
-func NewUserRepository(db *sqlx.DB) UserRepository {
+func NewUserRepository(db Querier) UserRepository {
 	return &userRepository{db: db}
 }
 
@@ -71,13 +74,97 @@ func (r *userRepository) ByEmail(email string) (*model.User, error) {
 	return user, err
 }
 
+// Update saves the user's editable fields
+// totp_last_counter is owned by AcceptTOTPCounter, it is only reset here when the
+// TOTP secret is cleared, so a later enrollment starts without the old secret's time step.
 func (r *userRepository) Update(user *model.User) error {
-	query := `UPDATE users SET email = $1, password_hash = $2, pending_email = $3, email_verified_at = $4 WHERE id = $5`
-
-	_, err := r.db.Exec(query, user.Email, user.PasswordHash, user.PendingEmail, user.EmailVerifiedAt, user.ID)
+	query := `
+		UPDATE users
+		SET email = $1, password_hash = $2, pending_email = $3, email_verified_at = $4, totp_secret = $5, totp_enabled_at = $6,
+			totp_last_counter = CASE WHEN $5 IS NULL THEN NULL ELSE totp_last_counter END
+		WHERE id = $7
+	`
+
+	_, err := r.db.Exec(query, user.Email, user.PasswordHash, user.PendingEmail, user.EmailVerifiedAt, user.TOTPSecret, user.TOTPEnabledAt, user.ID)
 	return err
 }
 
+// AcceptTOTPCounter stores the time step of an accepted TOTP code
+// Returns false if a code of this step or a later one was already accepted, a replay.
+// The check and the update are one statement, so two requests can't both use a code.
+func (r *userRepository) AcceptTOTPCounter(id string, counter int64) (bool, error) {
+	query := `UPDATE users SET totp_last_counter = $1 WHERE id = $2 AND (totp_last_counter IS NULL OR totp_last_counter < $1)`
+
+	result, err := r.db.Exec(query, counter, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (r *userRepository) SetAdmin(id string, isAdmin bool) error {
+	query := `UPDATE users SET is_admin = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(query, isAdmin, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Search lists users whose email or name contains search, newest first
+// An empty search lists everyone
+func (r *userRepository) Search(search string, limit, offset int) ([]*model.AdminUser, error) {
+	var users []*model.AdminUser
+	query := `
+		SELECT u.*, COALESCE(p.name, '') AS name
+		FROM users u
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE LOWER(u.email) LIKE $1 OR LOWER(COALESCE(p.name, '')) LIKE $1
+		ORDER BY u.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.Select(&users, query, searchPattern(search), limit, offset)
+	return users, err
+}
+
+func (r *userRepository) CountSearch(search string) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM users u
+		LEFT JOIN profiles p ON p.user_id = u.id
+		WHERE LOWER(u.email) LIKE $1 OR LOWER(COALESCE(p.name, '')) LIKE $1
+	`
+
+	err := r.db.Get(&count, query, searchPattern(search))
+	return count, err
+}
+
+// searchPattern builds a case-insensitive LIKE pattern, wildcards typed by the user are dropped
+func searchPattern(search string) string {
+	search = strings.ToLower(strings.TrimSpace(search))
+	search = strings.NewReplacer("%", "", "_", "").Replace(search)
+	return "%" + search + "%"
+}
+
 func (r *userRepository) Delete(id string) error {
 	query := `DELETE FROM users WHERE id = $1`
 
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrUserIdentityNotFound = errors.New("user identity not found")
+)
+
+type UserIdentityRepository interface {
+	Create(identity *model.UserIdentity) error
+	ByProviderUserID(provider, providerUserID string) (*model.UserIdentity, error)
+	ByUserID(userID string) ([]*model.UserIdentity, error)
+	RecordLogin(id, email string, loginAt time.Time) error
+	Delete(userID, provider string) error
+}
+
+type userIdentityRepository struct {
+	db Querier
+}
+
+func NewUserIdentityRepository(db Querier) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(identity *model.UserIdentity) error {
+	if identity.ID == "" {
+		identity.ID = uuid.New().String()
+	}
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, provider_user_id, email, last_login_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.ProviderUserID,
+		identity.Email,
+		identity.LastLoginAt,
+		identity.CreatedAt,
+	)
+	return err
+}
+
+func (r *userIdentityRepository) ByProviderUserID(provider, providerUserID string) (*model.UserIdentity, error) {
+	identity := &model.UserIdentity{}
+	query := `SELECT * FROM user_identities WHERE provider = $1 AND provider_user_id = $2`
+
+	err := r.db.Get(identity, query, provider, providerUserID)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserIdentityNotFound
+	}
+
+	return identity, err
+}
+
+// ByUserID returns the user's linked identities, oldest first
+func (r *userIdentityRepository) ByUserID(userID string) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	query := `SELECT * FROM user_identities WHERE user_id = $1 ORDER BY created_at ASC`
+
+	err := r.db.Select(&identities, query, userID)
+	return identities, err
+}
+
+// RecordLogin stores the login time and the email the provider reported this time
+func (r *userIdentityRepository) RecordLogin(id, email string, loginAt time.Time) error {
+	query := `UPDATE user_identities SET email = $1, last_login_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, email, loginAt, id)
+	return err
+}
+
+// Delete unlinks a provider, scoped to the user so one user can't unlink another's identity
+func (r *userIdentityRepository) Delete(userID, provider string) error {
+	query := `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`
+	result, err := r.db.Exec(query, userID, provider)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrUserIdentityNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookDeliveryRepository interface {
+	Create(delivery *model.WebhookDelivery) error
+	ByID(id string) (*model.WebhookDelivery, error)
+	ByEndpointID(endpointID string, limit int) ([]*model.WebhookDelivery, error)
+	RecordAttempt(delivery *model.WebhookDelivery) error
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type webhookDeliveryRepository struct {
+	db Querier
+}
+
+func NewWebhookDeliveryRepository(db Querier) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+func (r *webhookDeliveryRepository) Create(delivery *model.WebhookDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	if delivery.Status == "" {
+		delivery.Status = model.WebhookDeliveryStatusPending
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_type, payload, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Exec(query,
+		delivery.ID,
+		delivery.EndpointID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Status,
+		delivery.CreatedAt,
+	)
+	return err
+}
+
+func (r *webhookDeliveryRepository) ByID(id string) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	query := `SELECT * FROM webhook_deliveries WHERE id = $1`
+
+	err := r.db.Get(delivery, query, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	return delivery, err
+}
+
+// ByEndpointID returns the most recent deliveries for an endpoint, newest first
+func (r *webhookDeliveryRepository) ByEndpointID(endpointID string, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	query := `SELECT * FROM webhook_deliveries WHERE endpoint_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	err := r.db.Select(&deliveries, query, endpointID, limit)
+	return deliveries, err
+}
+
+// RecordAttempt stores the outcome of a delivery attempt
+func (r *webhookDeliveryRepository) RecordAttempt(delivery *model.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+	          SET status = $1, attempts = $2, response_status = $3, response_body = $4, last_error = $5, last_attempt_at = $6
+	          WHERE id = $7`
+
+	_, err := r.db.Exec(query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.ResponseBody,
+		delivery.LastError,
+		delivery.LastAttemptAt,
+		delivery.ID,
+	)
+	return err
+}
+
+func (r *webhookDeliveryRepository) DeleteBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM webhook_deliveries WHERE created_at < $1`
+	result, err := r.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+)
+
+var (
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+)
+
+type WebhookEndpointRepository interface {
+	Create(endpoint *model.WebhookEndpoint) error
+	ByID(userID, id string) (*model.WebhookEndpoint, error)
+	ByIDUnscoped(id string) (*model.WebhookEndpoint, error)
+	ByUserID(userID string) ([]*model.WebhookEndpoint, error)
+	Delete(userID, id string) error
+}
+
+type webhookEndpointRepository struct {
+	db Querier
+}
+
+func NewWebhookEndpointRepository(db Querier) WebhookEndpointRepository {
+	return &webhookEndpointRepository{db: db}
+}
+
+func (r *webhookEndpointRepository) Create(endpoint *model.WebhookEndpoint) error {
+	if endpoint.ID == "" {
+		endpoint.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if endpoint.CreatedAt.IsZero() {
+		endpoint.CreatedAt = now
+	}
+	endpoint.UpdatedAt = now
+
+	query := `
+		INSERT INTO webhook_endpoints (id, user_id, url, description, secret, events, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(query,
+		endpoint.ID,
+		endpoint.UserID,
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.Secret,
+		endpoint.Events,
+		endpoint.CreatedAt,
+		endpoint.UpdatedAt,
+	)
+	return err
+}
+
+func (r *webhookEndpointRepository) ByID(userID, id string) (*model.WebhookEndpoint, error) {
+	endpoint := &model.WebhookEndpoint{}
+	query := `SELECT * FROM webhook_endpoints WHERE id = $1 AND user_id = $2`
+
+	err := r.db.Get(endpoint, query, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookEndpointNotFound
+	}
+
+	return endpoint, err
+}
+
+// ByIDUnscoped loads an endpoint without an ownership check, for background delivery only
+func (r *webhookEndpointRepository) ByIDUnscoped(id string) (*model.WebhookEndpoint, error) {
+	endpoint := &model.WebhookEndpoint{}
+	query := `SELECT * FROM webhook_endpoints WHERE id = $1`
+
+	err := r.db.Get(endpoint, query, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookEndpointNotFound
+	}
+
+	return endpoint, err
+}
+
+func (r *webhookEndpointRepository) ByUserID(userID string) ([]*model.WebhookEndpoint, error) {
+	var endpoints []*model.WebhookEndpoint
+	query := `SELECT * FROM webhook_endpoints WHERE user_id = $1 ORDER BY created_at ASC`
+
+	err := r.db.Select(&endpoints, query, userID)
+	return endpoints, err
+}
+
+// Delete removes an endpoint and its delivery log, scoped to the user
+func (r *webhookEndpointRepository) Delete(userID, id string) error {
+	query := `DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+
+	return nil
+}
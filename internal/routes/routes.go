@@ -11,7 +11,10 @@ import (
 	"github.com/templui/goilerplate/assets"
 	"github.com/templui/goilerplate/internal/app"
 	"github.com/templui/goilerplate/internal/handler"
+	"github.com/templui/goilerplate/internal/mail"
 	"github.com/templui/goilerplate/internal/middleware"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/storage"
 )
 
 func SetupRoutes(app *app.App) http.Handler {
@@ -22,13 +25,20 @@ func SetupRoutes(app *app.App) http.Handler {
 	docs := handler.NewDocsHandler(app.DocsService)
 	legal := handler.NewLegalHandler(app.LegalService)
 	newsletter := handler.NewNewsletterHandler(app.EmailService)
-	auth := handler.NewAuthHandler(app.AuthService, app.UserService, app.SubscriptionService, app.Cfg)
-	account := handler.NewAccountHandler(app.AuthService, app.UserService, app.FileService)
+	unsubscribe := handler.NewUnsubscribeHandler(app.NotificationService)
+	auth := handler.NewAuthHandler(app.AuthService, app.UserService, app.SubscriptionService, app.OIDCProviders, app.Cfg)
+	account := handler.NewAccountHandler(app.AuthService, app.UserService, app.FileService, app.AuditService)
 	profile := handler.NewProfileHandler(app.ProfileService)
-	dashboard := handler.NewDashboardHandler()
-	settings := handler.NewSettingsHandler()
+	dashboard := handler.NewDashboardHandler(app.GoalAnalyticsService)
+	settings := handler.NewSettingsHandler(app.AuthService, app.PasskeyService, app.APITokenService, app.WebhookService, app.AuditService)
+	passkey := handler.NewPasskeyHandler(app.PasskeyService, app.AuthService)
+	apiToken := handler.NewAPITokenHandler(app.APITokenService)
+	webhook := handler.NewWebhookHandler(app.WebhookService)
+	workspace := handler.NewWorkspaceHandler(app.OrganizationService)
 	goal := handler.NewGoalHandler(app.GoalService)
-	billing := handler.NewBillingHandler(app.SubscriptionService, app.PaymentService)
+	billing := handler.NewBillingHandler(app.SubscriptionService, app.PaymentService, app.OrganizationService)
+	api := handler.NewAPIHandler(app.GoalService, app.ProfileService, app.SubscriptionService)
+	admin := handler.NewAdminHandler(app.AdminService, app.AuditService)
 
 	mux := http.NewServeMux()
 
@@ -40,6 +50,27 @@ func SetupRoutes(app *app.App) http.Handler {
 	sub, _ := fs.Sub(assets.AssetsFS, ".")
 	mux.Handle("GET /assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(sub))))
 
+	// Uploaded files (local storage driver only, S3 serves files directly)
+	if localStorage, ok := app.FileStorage.(*storage.LocalStorage); ok {
+		file := handler.NewFileHandler(localStorage)
+		mux.HandleFunc("GET /uploads/{path...}", file.Serve)
+	}
+
+	// Mailbox for the outbox email driver (development only)
+	if outbox, ok := app.MailTransport.(*mail.OutboxTransport); ok && app.Cfg.IsDevelopment() {
+		mailbox := handler.NewMailboxHandler(outbox)
+		mux.HandleFunc("GET /dev/mailbox", mailbox.List)
+		mux.HandleFunc("GET /dev/mailbox/{id}", mailbox.Show)
+		mux.HandleFunc("POST /dev/mailbox/clear", mailbox.Clear)
+	}
+
+	// Email template previews (development only)
+	if app.Cfg.IsDevelopment() {
+		emailPreview := handler.NewEmailPreviewHandler(app.EmailService)
+		mux.HandleFunc("GET /dev/emails", emailPreview.Preview)
+		mux.HandleFunc("GET /dev/emails/{name}", emailPreview.Preview)
+	}
+
 	// JukeLab SvelteKit app (served at /jukebox)
 	jukeboxSub, _ := fs.Sub(goilerplate.JukeboxFS, "jukelab/build")
 	mux.Handle("GET /jukebox/", http.StripPrefix("/jukebox/", spaFileServer(http.FS(jukeboxSub))))
@@ -62,8 +93,14 @@ func SetupRoutes(app *app.App) http.Handler {
 	mux.HandleFunc("GET /docs/", docs.ShowDocs)
 	mux.HandleFunc("GET /legal/{page}", legal.ShowPage)
 
+	// Shared goals (public, noindex by default)
+	mux.HandleFunc("GET /g/{slug}", goal.SharedGoalPage)
+	mux.HandleFunc("GET /g/{slug}/og.png", goal.SharedGoalImage)
+
 	// Newsletter
 	mux.HandleFunc("POST /newsletter/subscribe", newsletter.Subscribe)
+	mux.HandleFunc("GET /unsubscribe/{token}", unsubscribe.Unsubscribe)
+	mux.HandleFunc("POST /unsubscribe/{token}", unsubscribe.Unsubscribe)
 
 	// Auth - Authentication flow (rate limited)
 	rateLimiter := middleware.RateLimitAuth()
@@ -73,22 +110,35 @@ func SetupRoutes(app *app.App) http.Handler {
 	mux.HandleFunc("GET /auth/password", middleware.RequireGuest(auth.PasswordPage))
 	mux.HandleFunc("GET /auth/forgot-password", middleware.RequireGuest(auth.ForgotPasswordPage))
 	mux.HandleFunc("GET /auth/onboarding", middleware.RequireAuth(auth.OnboardingPage))
+	mux.HandleFunc("GET /auth/2fa", middleware.RequireGuest(auth.TwoFactorPage))
 
 	// OAuth
 	mux.HandleFunc("GET /auth/google", rateLimiter(middleware.RequireGuest(auth.GoogleAuth)))
 	mux.HandleFunc("GET /auth/google/callback", rateLimiter(auth.GoogleCallback))
 	mux.HandleFunc("GET /auth/github", rateLimiter(middleware.RequireGuest(auth.GitHubAuth)))
 	mux.HandleFunc("GET /auth/github/callback", rateLimiter(auth.GitHubCallback))
+	mux.HandleFunc("GET /auth/oidc/{provider}", rateLimiter(middleware.RequireGuest(auth.OIDCAuth)))
+	mux.HandleFunc("GET /auth/oidc/{provider}/callback", rateLimiter(auth.OIDCCallback))
 
 	// Token Verifications
 	mux.HandleFunc("GET /auth/magic-link/{token}", auth.VerifyMagicLink)
 	mux.HandleFunc("GET /auth/forgot-password/{token}", auth.VerifyForgotPassword)
 	mux.HandleFunc("GET /auth/verify-email-change/{token}", auth.VerifyEmailChange)
+	mux.HandleFunc("GET /auth/secure-account/{token}", auth.SecureAccountPage)
+	mux.HandleFunc("POST /auth/secure-account/{token}", rateLimiter(auth.SecureAccount))
+
+	// Workspace Invitations (link from the invitation email)
+	mux.HandleFunc("GET /invitations/{token}", workspace.InvitationPage)
+	mux.HandleFunc("POST /invitations/{token}/accept", middleware.RequireAuth(workspace.AcceptInvitation))
+	mux.HandleFunc("POST /invitations/{token}/decline", middleware.RequireAuth(workspace.DeclineInvitation))
 
 	// Auth Actions
 	mux.HandleFunc("POST /auth/magic-link", rateLimiter(middleware.RequireGuest(auth.SendMagicLink)))
 	mux.HandleFunc("POST /auth/password", rateLimiter(middleware.RequireGuest(auth.PasswordAuth)))
 	mux.HandleFunc("POST /auth/forgot-password", rateLimiter(middleware.RequireGuest(auth.ForgotPassword)))
+	mux.HandleFunc("POST /auth/2fa", rateLimiter(middleware.RequireGuest(auth.TwoFactorVerify)))
+	mux.HandleFunc("POST /auth/passkey/options", middleware.RequireGuest(passkey.LoginOptions))
+	mux.HandleFunc("POST /auth/passkey", rateLimiter(middleware.RequireGuest(passkey.Login)))
 	mux.HandleFunc("POST /auth/onboarding", middleware.RequireAuth(auth.CompleteOnboarding))
 	mux.HandleFunc("POST /auth/logout", auth.Logout)
 
@@ -96,40 +146,140 @@ func SetupRoutes(app *app.App) http.Handler {
 	// PROTECTED ROUTES (/app/*)
 	// ============================================================================
 
+	// Every app page runs in the active workspace
+	// Routes touching credentials, account deletion or billing are wrapped in BlockImpersonation
+	requireWorkspace := middleware.RequireWorkspace(app.OrganizationService, app.SubscriptionService)
+
 	// App Pages
-	mux.HandleFunc("GET /app/dashboard", middleware.RequireAuth(dashboard.DashboardPage))
-	mux.HandleFunc("GET /app/settings", middleware.RequireAuth(settings.SettingsPage))
+	mux.HandleFunc("GET /app/dashboard", middleware.RequireAuth(requireWorkspace(dashboard.DashboardPage)))
+	mux.HandleFunc("GET /app/dashboard/analytics", middleware.RequireAuth(requireWorkspace(dashboard.ExportAnalytics)))
+	mux.HandleFunc("GET /app/settings", middleware.RequireAuth(requireWorkspace(settings.SettingsPage)))
 
 	// Profile
-	mux.HandleFunc("PATCH /app/profile/name", middleware.RequireAuth(profile.UpdateName))
+	mux.HandleFunc("PATCH /app/profile/name", middleware.RequireAuth(requireWorkspace(profile.UpdateName)))
+	mux.HandleFunc("PATCH /app/profile/notifications", middleware.RequireAuth(requireWorkspace(profile.UpdateNotifications)))
 
 	// Account (Security & Identity)
-	mux.HandleFunc("PATCH /app/account/email", middleware.RequireAuth(account.ChangeEmail))
-	mux.HandleFunc("POST /app/account/password", middleware.RequireAuth(account.ChangePassword))
-	mux.HandleFunc("POST /app/account/avatar", middleware.RequireAuth(account.UploadAvatar))
-	mux.HandleFunc("DELETE /app/account/avatar", middleware.RequireAuth(account.DeleteAvatar))
-	mux.HandleFunc("POST /app/account/password/set", middleware.RequireAuth(account.SetPassword))
-	mux.HandleFunc("DELETE /app/account/password", middleware.RequireAuth(account.RemovePassword))
-	mux.HandleFunc("DELETE /app/account", middleware.RequireAuth(account.DeleteAccount))
+	mux.HandleFunc("PATCH /app/account/email", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.ChangeEmail))))
+	mux.HandleFunc("POST /app/account/password", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.ChangePassword))))
+	mux.HandleFunc("POST /app/account/avatar", middleware.RequireAuth(requireWorkspace(account.UploadAvatar)))
+	mux.HandleFunc("DELETE /app/account/avatar", middleware.RequireAuth(requireWorkspace(account.DeleteAvatar)))
+	mux.HandleFunc("POST /app/account/password/set", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.SetPassword))))
+	mux.HandleFunc("DELETE /app/account/password", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.RemovePassword))))
+	mux.HandleFunc("POST /app/account/2fa/setup", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.BeginTwoFactorSetup))))
+	mux.HandleFunc("POST /app/account/2fa/enable", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.EnableTwoFactor))))
+	mux.HandleFunc("POST /app/account/2fa/recovery-codes", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.RegenerateRecoveryCodes))))
+	mux.HandleFunc("DELETE /app/account/2fa", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.DisableTwoFactor))))
+	mux.HandleFunc("GET /app/account/identities/{provider}/link", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(auth.LinkIdentity))))
+	mux.HandleFunc("DELETE /app/account/identities/{provider}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.UnlinkIdentity))))
+	mux.HandleFunc("DELETE /app/account/sessions", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.RevokeOtherSessions))))
+	mux.HandleFunc("DELETE /app/account/sessions/{id}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.RevokeSession))))
+	mux.HandleFunc("DELETE /app/account", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(account.DeleteAccount))))
+
+	// Passkeys
+	mux.HandleFunc("POST /app/passkeys/options", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(passkey.RegistrationOptions))))
+	mux.HandleFunc("POST /app/passkeys", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(passkey.Register))))
+	mux.HandleFunc("PATCH /app/passkeys/{id}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(passkey.Rename))))
+	mux.HandleFunc("DELETE /app/passkeys/{id}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(passkey.Delete))))
+
+	// Workspaces
+	mux.HandleFunc("GET /app/workspaces", middleware.RequireAuth(requireWorkspace(workspace.WorkspacesPage)))
+	mux.HandleFunc("POST /app/workspaces", middleware.RequireAuth(requireWorkspace(workspace.Create)))
+	mux.HandleFunc("POST /app/workspaces/{id}/switch", middleware.RequireAuth(requireWorkspace(workspace.Switch)))
+	mux.HandleFunc("GET /app/workspace", middleware.RequireAuth(requireWorkspace(workspace.WorkspacePage)))
+	mux.HandleFunc("PATCH /app/workspace/name", middleware.RequireAuth(requireWorkspace(workspace.Rename)))
+	mux.HandleFunc("POST /app/workspace/invitations", middleware.RequireAuth(requireWorkspace(workspace.Invite)))
+	mux.HandleFunc("DELETE /app/workspace/invitations/{id}", middleware.RequireAuth(requireWorkspace(workspace.RevokeInvitation)))
+	mux.HandleFunc("PATCH /app/workspace/members/{id}/role", middleware.RequireAuth(requireWorkspace(workspace.ChangeRole)))
+	mux.HandleFunc("DELETE /app/workspace/members/{id}", middleware.RequireAuth(requireWorkspace(workspace.RemoveMember)))
+	mux.HandleFunc("POST /app/workspace/leave", middleware.RequireAuth(requireWorkspace(workspace.Leave)))
+	mux.HandleFunc("DELETE /app/workspace", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(workspace.Delete))))
+
+	// API Tokens
+	mux.HandleFunc("POST /app/api-tokens", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(apiToken.Create))))
+	mux.HandleFunc("DELETE /app/api-tokens/{id}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(apiToken.Revoke))))
+
+	// Outbound Webhooks
+	mux.HandleFunc("GET /app/webhooks/{id}", middleware.RequireAuth(requireWorkspace(webhook.DetailPage)))
+	mux.HandleFunc("GET /app/webhooks/{id}/deliveries", middleware.RequireAuth(requireWorkspace(webhook.Deliveries)))
+	mux.HandleFunc("POST /app/webhooks", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(webhook.Create))))
+	mux.HandleFunc("POST /app/webhooks/{id}/test", middleware.RequireAuth(requireWorkspace(webhook.SendTest)))
+	mux.HandleFunc("DELETE /app/webhooks/{id}", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(webhook.Delete))))
 
 	// Billing
-	mux.HandleFunc("GET /app/billing", middleware.RequireAuth(billing.BillingPage))
-	mux.HandleFunc("POST /app/billing/checkout", middleware.RequireAuth(billing.CreateCheckout))
-	mux.HandleFunc("GET /app/billing/portal", middleware.RequireAuth(billing.CustomerPortal))
+	mux.HandleFunc("GET /app/billing", middleware.RequireAuth(requireWorkspace(billing.BillingPage)))
+	mux.HandleFunc("POST /app/billing/checkout", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(billing.CreateCheckout))))
+	mux.HandleFunc("GET /app/billing/portal", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(billing.CustomerPortal))))
+	mux.HandleFunc("POST /app/billing/seats", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(billing.UpdateSeats))))
+
+	// Goals
+	mux.HandleFunc("GET /app/goals", middleware.RequireAuth(requireWorkspace(goal.GoalsPage)))
+	mux.HandleFunc("GET /app/goals/{id}", middleware.RequireAuth(requireWorkspace(goal.GoalDetailPage)))
+	mux.HandleFunc("GET /app/goals/{id}/edit-dialog", middleware.RequireAuth(requireWorkspace(goal.EditDialog)))
+	mux.HandleFunc("GET /app/goals/{id}/delete-dialog", middleware.RequireAuth(requireWorkspace(goal.DeleteDialog)))
+	mux.HandleFunc("GET /app/goals/{id}/share-dialog", middleware.RequireAuth(requireWorkspace(goal.ShareDialog)))
+	mux.HandleFunc("GET /app/goals/{id}/entries/{step}/dialog", middleware.RequireAuth(requireWorkspace(goal.EntryDialog)))
+	mux.HandleFunc("GET /app/goals/export", middleware.RequireAuth(requireWorkspace(goal.Export)))
+	mux.HandleFunc("POST /app/goals", middleware.RequireAuth(requireWorkspace(goal.Create)))
+	mux.HandleFunc("POST /app/goals/import", middleware.RequireAuth(requireWorkspace(goal.Import)))
+	mux.HandleFunc("POST /app/goals/{id}/entries/{step}/complete", middleware.RequireAuth(requireWorkspace(goal.CompleteEntry)))
+	mux.HandleFunc("POST /app/goals/{id}/entries/{step}/attachments", middleware.RequireAuth(requireWorkspace(goal.UploadAttachment)))
+	mux.HandleFunc("POST /app/goals/{id}/share", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(goal.Share))))
+	mux.HandleFunc("PUT /app/goals/{id}", middleware.RequireAuth(requireWorkspace(goal.Update)))
+	mux.HandleFunc("PATCH /app/goals/{id}/entries/{step}", middleware.RequireAuth(requireWorkspace(goal.UpdateEntry)))
+	mux.HandleFunc("PATCH /app/goals/{id}/share", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(goal.UpdateShare))))
+	mux.HandleFunc("DELETE /app/goals/{id}", middleware.RequireAuth(requireWorkspace(goal.Delete)))
+	mux.HandleFunc("DELETE /app/goals/{id}/entries/{step}", middleware.RequireAuth(requireWorkspace(goal.UncompleteEntry)))
+	mux.HandleFunc("DELETE /app/goals/{id}/entries/{step}/attachments/{fileID}", middleware.RequireAuth(requireWorkspace(goal.DeleteAttachment)))
+	mux.HandleFunc("DELETE /app/goals/{id}/share", middleware.RequireAuth(requireWorkspace(middleware.BlockImpersonation(goal.Unshare))))
+
+	// ============================================================================
+	// ADMIN CONSOLE (/admin/*, is_admin users only)
+	// ============================================================================
+
+	mux.HandleFunc("GET /admin", middleware.RequireAuth(middleware.RequireAdmin(http.RedirectHandler("/admin/users", http.StatusSeeOther).ServeHTTP)))
+	mux.HandleFunc("GET /admin/users", middleware.RequireAuth(middleware.RequireAdmin(admin.UsersPage)))
+	mux.HandleFunc("GET /admin/users/{id}", middleware.RequireAuth(middleware.RequireAdmin(admin.UserPage)))
+	mux.HandleFunc("GET /admin/audit", middleware.RequireAuth(middleware.RequireAdmin(admin.AuditLogPage)))
+	mux.HandleFunc("GET /admin/security", middleware.RequireAuth(middleware.RequireAdmin(admin.SecurityEventsPage)))
+	mux.HandleFunc("GET /admin/security/export", middleware.RequireAuth(middleware.RequireAdmin(admin.ExportSecurityEvents)))
+	mux.HandleFunc("POST /admin/users/{id}/plan", middleware.RequireAuth(middleware.RequireAdmin(admin.OverridePlan)))
+	mux.HandleFunc("POST /admin/users/{id}/logout", middleware.RequireAuth(middleware.RequireAdmin(admin.ForceLogout)))
+	mux.HandleFunc("POST /admin/users/{id}/unlock", middleware.RequireAuth(middleware.RequireAdmin(admin.UnlockLogin)))
+	mux.HandleFunc("POST /admin/users/{id}/magic-link", middleware.RequireAuth(middleware.RequireAdmin(admin.SendMagicLink)))
+	mux.HandleFunc("POST /admin/users/{id}/email-change", middleware.RequireAuth(middleware.RequireAdmin(admin.ResendEmailChange)))
+	mux.HandleFunc("POST /admin/users/{id}/admin", middleware.RequireAuth(middleware.RequireAdmin(admin.SetAdmin)))
+	mux.HandleFunc("DELETE /admin/users/{id}", middleware.RequireAuth(middleware.RequireAdmin(admin.DeleteUser)))
+	mux.HandleFunc("POST /admin/users/{id}/impersonate", middleware.RequireAuth(middleware.RequireAdmin(admin.Impersonate)))
+
+	// Runs as the impersonated user, the handler checks for the impersonator itself
+	mux.HandleFunc("POST /admin/impersonation/stop", middleware.RequireAuth(admin.StopImpersonation))
+
+	// ============================================================================
+	// JSON API (/api/v1/*, personal access tokens)
+	// ============================================================================
+
+	apiRead := middleware.RequireAPIToken(model.APITokenScopeRead, app.APITokenService, app.UserService, app.ProfileService, app.OrganizationService, app.SubscriptionService)
+	apiWrite := middleware.RequireAPIToken(model.APITokenScopeWrite, app.APITokenService, app.UserService, app.ProfileService, app.OrganizationService, app.SubscriptionService)
+
+	// Account
+	mux.HandleFunc("GET /api/v1/me", apiRead(api.Me))
+	mux.HandleFunc("GET /api/v1/profile", apiRead(api.Profile))
+	mux.HandleFunc("PATCH /api/v1/profile", apiWrite(api.UpdateProfile))
+	mux.HandleFunc("GET /api/v1/subscription", apiRead(api.Subscription))
 
 	// Goals
-	mux.HandleFunc("GET /app/goals", middleware.RequireAuth(goal.GoalsPage))
-	mux.HandleFunc("GET /app/goals/{id}", middleware.RequireAuth(goal.GoalDetailPage))
-	mux.HandleFunc("GET /app/goals/{id}/edit-dialog", middleware.RequireAuth(goal.EditDialog))
-	mux.HandleFunc("GET /app/goals/{id}/delete-dialog", middleware.RequireAuth(goal.DeleteDialog))
-	mux.HandleFunc("GET /app/goals/{id}/entries/{step}/dialog", middleware.RequireAuth(goal.EntryDialog))
-	mux.HandleFunc("GET /app/goals/export", middleware.RequireAuth(goal.Export))
-	mux.HandleFunc("POST /app/goals", middleware.RequireAuth(goal.Create))
-	mux.HandleFunc("POST /app/goals/{id}/entries/{step}/complete", middleware.RequireAuth(goal.CompleteEntry))
-	mux.HandleFunc("PUT /app/goals/{id}", middleware.RequireAuth(goal.Update))
-	mux.HandleFunc("PATCH /app/goals/{id}/entries/{step}", middleware.RequireAuth(goal.UpdateEntry))
-	mux.HandleFunc("DELETE /app/goals/{id}", middleware.RequireAuth(goal.Delete))
-	mux.HandleFunc("DELETE /app/goals/{id}/entries/{step}", middleware.RequireAuth(goal.UncompleteEntry))
+	mux.HandleFunc("GET /api/v1/goals", apiRead(api.ListGoals))
+	mux.HandleFunc("GET /api/v1/goals/{id}", apiRead(api.GetGoal))
+	mux.HandleFunc("POST /api/v1/goals", apiWrite(api.CreateGoal))
+	mux.HandleFunc("PATCH /api/v1/goals/{id}", apiWrite(api.UpdateGoal))
+	mux.HandleFunc("DELETE /api/v1/goals/{id}", apiWrite(api.DeleteGoal))
+	mux.HandleFunc("POST /api/v1/goals/{id}/entries/{step}/complete", apiWrite(api.CompleteEntry))
+	mux.HandleFunc("PATCH /api/v1/goals/{id}/entries/{step}", apiWrite(api.UpdateEntry))
+	mux.HandleFunc("DELETE /api/v1/goals/{id}/entries/{step}", apiWrite(api.UncompleteEntry))
+
+	// JSON 404 for unknown API paths
+	mux.HandleFunc("/api/", api.NotFound)
 
 	// ============================================================================
 	// WEBHOOKS
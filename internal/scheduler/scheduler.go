@@ -0,0 +1,88 @@
+// Package scheduler runs periodic background jobs inside the server process.
+// Jobs run on every tick and decide themselves whether work is due
+// (e.g. per-user timezones), so a missed tick never loses work.
+package scheduler
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name string
+	run  func(now time.Time) error
+}
+
+type Scheduler struct {
+	interval time.Duration
+	jobs     []job
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates a scheduler that runs all jobs every interval
+func New(interval time.Duration) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Add registers a job, must be called before Start
+func (s *Scheduler) Add(name string, run func(now time.Time) error) {
+	s.jobs = append(s.jobs, job{name: name, run: run})
+}
+
+// Start runs jobs in a background goroutine, once immediately and then on every tick
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		slog.Info("scheduler started", "interval", s.interval, "jobs", len(s.jobs))
+		s.runAll()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.runAll()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop waits for the current run to finish and stops the scheduler
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runAll() {
+	now := time.Now()
+	for _, j := range s.jobs {
+		s.runJob(j, now)
+	}
+}
+
+// runJob runs a single job, a failing or panicking job never stops the others
+func (s *Scheduler) runJob(j job, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("scheduled job panicked", "job", j.name, "panic", r)
+		}
+	}()
+
+	start := time.Now()
+	err := j.run(now)
+	if err != nil {
+		slog.Error("scheduled job failed", "job", j.name, "error", err, "duration", time.Since(start))
+		return
+	}
+	slog.Debug("scheduled job finished", "job", j.name, "duration", time.Since(start))
+}
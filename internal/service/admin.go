@@ -0,0 +1,377 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	AdminUsersPerPage     = 50
+	AdminAuditLogsPerPage = 100
+	adminUserAuditLogs    = 20
+)
+
+var (
+	ErrAdminSelf             = errors.New("this action can't be used on your own account")
+	ErrNoPendingEmailChange  = errors.New("user has no pending email change")
+	ErrAdminWorkspaceMissing = errors.New("workspace does not belong to this user")
+	ErrImpersonateAdmin      = errors.New("admins can't be impersonated")
+)
+
+// AdminService backs the /admin console, every change is written to the audit log
+type AdminService struct {
+	userRepository          repository.UserRepository
+	profileRepository       repository.ProfileRepository
+	goalRepository          repository.GoalRepository
+	adminAuditLogRepository repository.AdminAuditLogRepository
+	fileService             *FileService
+	subscriptionService     *SubscriptionService
+	organizationService     *OrganizationService
+	authService             *AuthService
+	userService             *UserService
+}
+
+func NewAdminService(
+	userRepository repository.UserRepository,
+	profileRepository repository.ProfileRepository,
+	goalRepository repository.GoalRepository,
+	adminAuditLogRepository repository.AdminAuditLogRepository,
+	fileService *FileService,
+	subscriptionService *SubscriptionService,
+	organizationService *OrganizationService,
+	authService *AuthService,
+	userService *UserService,
+) *AdminService {
+	return &AdminService{
+		userRepository:          userRepository,
+		profileRepository:       profileRepository,
+		goalRepository:          goalRepository,
+		adminAuditLogRepository: adminAuditLogRepository,
+		fileService:             fileService,
+		subscriptionService:     subscriptionService,
+		organizationService:     organizationService,
+		authService:             authService,
+		userService:             userService,
+	}
+}
+
+// GrantAdmins makes the given existing accounts admins, used for ADMIN_EMAILS on startup
+// Unknown emails are skipped, the account has to sign up first
+func (s *AdminService) GrantAdmins(emails []string) {
+	for _, email := range emails {
+		user, err := s.userRepository.ByEmail(strings.ToLower(email))
+		if err != nil {
+			if !errors.Is(err, repository.ErrUserNotFound) {
+				slog.Error("failed to look up admin", "error", err, "email", email)
+			}
+			continue
+		}
+		if user.IsAdmin {
+			continue
+		}
+
+		err = s.userRepository.SetAdmin(user.ID, true)
+		if err != nil {
+			slog.Error("failed to grant admin", "error", err, "user_id", user.ID)
+			continue
+		}
+		slog.Info("admin granted from config", "user_id", user.ID, "email", user.Email)
+	}
+}
+
+// Users lists accounts matching search by email or name, page starts at 1
+func (s *AdminService) Users(search string, page int) ([]*model.AdminUser, int, error) {
+	page = max(page, 1)
+
+	users, err := s.userRepository.Search(search, AdminUsersPerPage, (page-1)*AdminUsersPerPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	total, err := s.userRepository.CountSearch(search)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (s *AdminService) User(userID string) (*model.User, error) {
+	return s.userRepository.ByID(userID)
+}
+
+func (s *AdminService) UserDetail(userID string) (*model.AdminUserDetail, error) {
+	user, err := s.userService.ByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = nil
+	user.TOTPSecret = nil
+
+	detail := &model.AdminUserDetail{User: user}
+
+	detail.Profile, err = s.profileRepository.ByUserID(userID)
+	if err != nil && !errors.Is(err, repository.ErrProfileNotFound) {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	memberships, err := s.organizationService.Memberships(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		subscription, err := s.subscriptionService.Subscription(membership.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		detail.Workspaces = append(detail.Workspaces, &model.AdminWorkspace{
+			Membership:   membership,
+			Subscription: subscription,
+		})
+	}
+
+	goals, err := s.goalRepository.CreatedBy(userID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+	detail.GoalsCount = len(goals)
+
+	detail.Files, err = s.fileService.AllUserFiles(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get files: %w", err)
+	}
+
+	detail.Sessions, err = s.authService.Sessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	detail.LoginAttempt, err = s.authService.LoginAttempt(user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.AuditLogs, err = s.adminAuditLogRepository.ByTargetID(userID, adminUserAuditLogs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return detail, nil
+}
+
+// OverridePlan changes the plan of one of the user's workspaces by hand
+func (s *AdminService) OverridePlan(admin *model.User, ipAddress, userID, organizationID, planID string, seats int) error {
+	membership, err := s.organizationService.Membership(organizationID, userID)
+	if errors.Is(err, repository.ErrMembershipNotFound) {
+		return ErrAdminWorkspaceMissing
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	subscription, err := s.subscriptionService.Subscription(organizationID)
+	if err != nil {
+		return err
+	}
+	previous := subscription.PlanID
+
+	err = s.subscriptionService.OverridePlan(subscription, planID, seats)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionPlanOverride, model.AdminTargetUser, userID,
+		fmt.Sprintf("%s (%s): %s -> %s, %d seats", membership.OrganizationName, organizationID, previous, subscription.PlanID, subscription.Seats))
+	return nil
+}
+
+// ForceLogout revokes every session of the user
+func (s *AdminService) ForceLogout(admin *model.User, ipAddress, userID string) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.authService.RevokeAllSessions(user.ID)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionForceLogout, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// UnlockLogin clears failed password sign-ins, lifting a lockout right away
+func (s *AdminService) UnlockLogin(admin *model.User, ipAddress, userID string) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.authService.UnlockLogin(user)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionUnlockLogin, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// SendMagicLink emails a sign-in link, it also verifies the address once used
+func (s *AdminService) SendMagicLink(admin *model.User, ipAddress, userID string) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.authService.SendMagicLink(user.Email)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionSendMagicLink, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// ResendEmailChange sends a fresh verification link for the user's pending email change
+func (s *AdminService) ResendEmailChange(admin *model.User, ipAddress, userID string) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.PendingEmail == nil || *user.PendingEmail == "" {
+		return ErrNoPendingEmailChange
+	}
+
+	err = s.authService.RequestEmailChange(user.ID, *user.PendingEmail, model.AuditContext{ActorID: admin.ID, IPAddress: ipAddress})
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionResendEmailChange, model.AdminTargetUser, user.ID, user.Email+" -> "+*user.PendingEmail)
+	return nil
+}
+
+// SetAdmin grants or revokes console access, admins can't demote themselves
+func (s *AdminService) SetAdmin(admin *model.User, ipAddress, userID string, isAdmin bool) error {
+	if userID == admin.ID {
+		return ErrAdminSelf
+	}
+
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepository.SetAdmin(user.ID, isAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to update admin: %w", err)
+	}
+
+	action := model.AdminActionRevokeAdmin
+	if isAdmin {
+		action = model.AdminActionGrantAdmin
+	}
+	s.record(admin, ipAddress, action, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// DeleteUser deletes the account the same way the user would from settings
+func (s *AdminService) DeleteUser(admin *model.User, ipAddress, userID string) error {
+	if userID == admin.ID {
+		return ErrAdminSelf
+	}
+
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.DeleteAccount(user.ID, model.AuditContext{ActorID: admin.ID, IPAddress: ipAddress})
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionDeleteUser, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// StartImpersonation lets an admin see the app as the user, on the admin's own session
+// Other admins can't be impersonated, that would hand out their console access.
+func (s *AdminService) StartImpersonation(w http.ResponseWriter, admin *model.User, session *model.Session, ipAddress, userID string) error {
+	if userID == admin.ID {
+		return ErrAdminSelf
+	}
+
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.IsAdmin {
+		return ErrImpersonateAdmin
+	}
+
+	err = s.authService.StartImpersonation(w, admin, session, user)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionImpersonationStart, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// StopImpersonation switches back to the admin's own session
+func (s *AdminService) StopImpersonation(w http.ResponseWriter, admin *model.User, session *model.Session, ipAddress string, user *model.User) error {
+	err := s.authService.StopImpersonation(w, admin, session)
+	if err != nil {
+		return err
+	}
+
+	s.record(admin, ipAddress, model.AdminActionImpersonationStop, model.AdminTargetUser, user.ID, user.Email)
+	return nil
+}
+
+// AuditLogs returns the audit log newest first, page starts at 1
+func (s *AdminService) AuditLogs(page int) ([]*model.AdminAuditLog, int, error) {
+	page = max(page, 1)
+
+	logs, err := s.adminAuditLogRepository.Recent(AdminAuditLogsPerPage, (page-1)*AdminAuditLogsPerPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	total, err := s.adminAuditLogRepository.Count()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// record writes an audit log entry once an action succeeded
+// The action already happened, so a failed write is logged with all details instead
+func (s *AdminService) record(admin *model.User, ipAddress, action, targetType, targetID, details string) {
+	entry := &model.AdminAuditLog{
+		AdminID:    admin.ID,
+		AdminEmail: admin.Email,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+		IPAddress:  ipAddress,
+	}
+
+	err := s.adminAuditLogRepository.Create(entry)
+	if err != nil {
+		slog.Error("failed to write admin audit log", "error", err, "admin_id", admin.ID, "action", action, "target_id", targetID, "details", details)
+		return
+	}
+
+	slog.Info("admin action", "admin_id", admin.ID, "action", action, "target_type", targetType, "target_id", targetID)
+}
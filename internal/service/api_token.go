@@ -0,0 +1,143 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	// apiTokenPrefix marks our tokens so they are easy to spot in code and secret scanners
+	apiTokenPrefix = "gp_"
+
+	apiTokenDisplayLen    = len(apiTokenPrefix) + 8
+	apiTokenMaxPerUser    = 25
+	apiTokenMaxNameLen    = 100
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIToken      = errors.New("invalid api token")
+	ErrAPITokenNameRequired = fmt.Errorf("token name is required (max %d characters)", apiTokenMaxNameLen)
+	ErrInvalidAPITokenScope = errors.New("invalid token scope")
+	ErrAPITokenLimitReached = fmt.Errorf("you can have at most %d tokens", apiTokenMaxPerUser)
+)
+
+type APITokenService struct {
+	repo repository.APITokenRepository
+}
+
+func NewAPITokenService(repo repository.APITokenRepository) *APITokenService {
+	return &APITokenService{
+		repo: repo,
+	}
+}
+
+// Create issues a new personal access token
+// Returns the plain-text token (shown once, only the hash is stored)
+func (s *APITokenService) Create(userID, name, scope string) (*model.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiTokenMaxNameLen {
+		return nil, "", ErrAPITokenNameRequired
+	}
+
+	if scope != model.APITokenScopeRead && scope != model.APITokenScopeWrite {
+		return nil, "", ErrInvalidAPITokenScope
+	}
+
+	existing, err := s.repo.ByUserID(userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list tokens: %w", err)
+	}
+	if len(existing) >= apiTokenMaxPerUser {
+		return nil, "", ErrAPITokenLimitReached
+	}
+
+	plain, err := generateAPIToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := &model.APIToken{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   hashAPIToken(plain),
+		TokenPrefix: plain[:apiTokenDisplayLen],
+		Scope:       scope,
+	}
+
+	err = s.repo.Create(token)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create token: %w", err)
+	}
+
+	slog.Info("api token created", "user_id", userID, "token_id", token.ID, "scope", scope)
+	return token, plain, nil
+}
+
+func (s *APITokenService) Tokens(userID string) ([]*model.APIToken, error) {
+	return s.repo.ByUserID(userID)
+}
+
+func (s *APITokenService) Revoke(userID, tokenID string) error {
+	err := s.repo.Delete(userID, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	slog.Info("api token revoked", "user_id", userID, "token_id", tokenID)
+	return nil
+}
+
+// Authenticate looks up the token from an Authorization header and records its use
+func (s *APITokenService) Authenticate(plain string) (*model.APIToken, error) {
+	if !strings.HasPrefix(plain, apiTokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	token, err := s.repo.ByHash(hashAPIToken(plain))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPITokenNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Limit last_used_at writes for scripts making many requests
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > apiTokenTouchInterval {
+		now := time.Now()
+		err = s.repo.Touch(token.ID, now)
+		if err != nil {
+			slog.Warn("failed to update api token last used", "error", err, "token_id", token.ID)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+
+	return token, nil
+}
+
+// generateAPIToken returns a random token like "gp_3f9a..." (256 bits of entropy)
+func generateAPIToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+// hashAPIToken hashes a token for storage and lookup
+// Tokens are high-entropy random values, so a fast hash is sufficient
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
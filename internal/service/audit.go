@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	AuditEventsPerPage = 100
+	// auditUserEvents is how many events the security activity in settings shows
+	auditUserEvents = 50
+	// auditExportLimit caps a single CSV export
+	auditExportLimit = 10000
+)
+
+// AuditService persists security-relevant account events
+// Unlike slog lines they survive log rotation and can be shown to the user.
+type AuditService struct {
+	auditEventRepository repository.AuditEventRepository
+}
+
+func NewAuditService(auditEventRepository repository.AuditEventRepository) *AuditService {
+	return &AuditService{
+		auditEventRepository: auditEventRepository,
+	}
+}
+
+// Record writes an event for user once the change succeeded, metadata may be nil
+// An empty actor means the user acted themselves. The change already happened,
+// so a failed write is logged with all details instead of returned.
+func (s *AuditService) Record(user *model.User, event string, audit model.AuditContext, metadata map[string]string) {
+	entry := &model.AuditEvent{
+		UserID:    user.ID,
+		Email:     user.Email,
+		ActorID:   audit.ActorID,
+		Event:     event,
+		IPAddress: audit.IPAddress,
+		UserAgent: audit.UserAgent,
+	}
+	if entry.ActorID == "" {
+		entry.ActorID = user.ID
+	}
+	if len(metadata) > 0 {
+		data, err := json.Marshal(metadata)
+		if err == nil {
+			entry.Metadata = string(data)
+		}
+	}
+
+	err := s.auditEventRepository.Create(entry)
+	if err != nil {
+		slog.Error("failed to write audit event", "error", err, "user_id", user.ID, "event", event, "actor_id", entry.ActorID, "ip_address", audit.IPAddress)
+	}
+}
+
+// UserEvents returns the user's recent events newest first
+func (s *AuditService) UserEvents(userID string) ([]*model.AuditEvent, error) {
+	events, err := s.auditEventRepository.ByUserID(userID, auditUserEvents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+	return events, nil
+}
+
+// Search returns the events matching filter newest first, page starts at 1
+func (s *AuditService) Search(filter model.AuditEventFilter, page int) ([]*model.AuditEvent, int, error) {
+	page = max(page, 1)
+
+	events, err := s.auditEventRepository.Search(filter, AuditEventsPerPage, (page-1)*AuditEventsPerPage)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search audit events: %w", err)
+	}
+
+	total, err := s.auditEventRepository.Count(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// Export returns up to auditExportLimit events matching filter, newest first
+func (s *AuditService) Export(filter model.AuditEventFilter) ([]*model.AuditEvent, error) {
+	events, err := s.auditEventRepository.Search(filter, auditExportLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export audit events: %w", err)
+	}
+	return events, nil
+}
@@ -32,8 +32,17 @@ type AuthService struct {
 	userRepository           repository.UserRepository
 	profileRepository        repository.ProfileRepository
 	tokenRepository          repository.TokenRepository
-	subscriptionService      *SubscriptionService
+	recoveryCodeRepository   repository.RecoveryCodeRepository
+	sessionRepository        repository.SessionRepository
+	userIdentityRepository   repository.UserIdentityRepository
+	passkeyRepository        repository.PasskeyRepository
+	knownDeviceRepository    repository.KnownDeviceRepository
+	loginAttemptRepository   repository.LoginAttemptRepository
+	txManager                repository.TxManager
+	organizationService      *OrganizationService
 	emailService             *EmailService
+	auditService             *AuditService
+	appName                  string
 	jwtSecret                string
 	isProduction             bool
 	jwtExpiry                time.Duration
@@ -47,8 +56,17 @@ func NewAuthService(
 	userRepository repository.UserRepository,
 	profileRepository repository.ProfileRepository,
 	tokenRepository repository.TokenRepository,
-	subscriptionService *SubscriptionService,
+	recoveryCodeRepository repository.RecoveryCodeRepository,
+	sessionRepository repository.SessionRepository,
+	userIdentityRepository repository.UserIdentityRepository,
+	passkeyRepository repository.PasskeyRepository,
+	knownDeviceRepository repository.KnownDeviceRepository,
+	loginAttemptRepository repository.LoginAttemptRepository,
+	txManager repository.TxManager,
+	organizationService *OrganizationService,
 	emailService *EmailService,
+	auditService *AuditService,
+	appName string,
 	jwtSecret string,
 	isProduction bool,
 	jwtExpiry time.Duration,
@@ -61,8 +79,17 @@ func NewAuthService(
 		userRepository:           userRepository,
 		profileRepository:        profileRepository,
 		tokenRepository:          tokenRepository,
-		subscriptionService:      subscriptionService,
+		recoveryCodeRepository:   recoveryCodeRepository,
+		sessionRepository:        sessionRepository,
+		userIdentityRepository:   userIdentityRepository,
+		passkeyRepository:        passkeyRepository,
+		knownDeviceRepository:    knownDeviceRepository,
+		loginAttemptRepository:   loginAttemptRepository,
+		txManager:                txManager,
+		organizationService:      organizationService,
 		emailService:             emailService,
+		auditService:             auditService,
+		appName:                  appName,
 		isProduction:             isProduction,
 		jwtSecret:                jwtSecret,
 		jwtExpiry:                jwtExpiry,
@@ -73,26 +100,39 @@ func NewAuthService(
 	}
 }
 
-func (s *AuthService) Login(email, password string) (*model.User, error) {
+// Login checks a password sign-in, failures are throttled per email (see auth_lockout.go)
+func (s *AuthService) Login(email, password string, audit model.AuditContext) (*model.User, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 
+	err := s.checkLoginAttempts(email)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepository.ByEmail(email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
+			s.recordFailedLogin(email, nil, audit)
 			return nil, fmt.Errorf("invalid credentials: %w", ErrInvalidCredentials)
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if !user.HasPassword() {
-		return nil, fmt.Errorf("this account uses passwordless login. Please use the magic link option")
+		return nil, fmt.Errorf("this account uses passwordless login. Please use a magic link or passkey")
 	}
 
 	err = s.ComparePassword(password, *user.PasswordHash)
 	if err != nil {
+		s.recordFailedLogin(email, user, audit)
 		return nil, fmt.Errorf("invalid credentials: %w", ErrInvalidCredentials)
 	}
 
+	err = s.loginAttemptRepository.Reset(email)
+	if err != nil {
+		slog.Warn("failed to reset login attempts", "error", err, "user_id", user.ID)
+	}
+
 	if user.EmailVerifiedAt == nil {
 		return nil, fmt.Errorf("email not verified: %w", ErrEmailNotVerified)
 	}
@@ -125,12 +165,13 @@ func (s *AuthService) GenerateToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func (s *AuthService) GenerateJWT(user *model.User) (string, error) {
+func (s *AuthService) GenerateJWT(user *model.User, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(s.jwtExpiry).Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":    user.ID,
+		"session_id": sessionID,
+		"email":      user.Email,
+		"exp":        time.Now().Add(s.jwtExpiry).Unix(),
+		"iat":        time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -187,7 +228,7 @@ func (s *AuthService) ClearJWTCookie(w http.ResponseWriter) {
 	})
 }
 
-func (s *AuthService) RequestEmailChange(userID, newEmail string) error {
+func (s *AuthService) RequestEmailChange(userID, newEmail string, audit model.AuditContext) error {
 	newEmail = strings.TrimSpace(strings.ToLower(newEmail))
 
 	err := validation.ValidateEmail(newEmail)
@@ -259,11 +300,12 @@ func (s *AuthService) RequestEmailChange(userID, newEmail string) error {
 		slog.Warn("failed to send email change notification", "error", err, "user_id", user.ID)
 	}
 
+	s.auditService.Record(user, model.AuditEventEmailChangeRequested, audit, map[string]string{"new_email": newEmail})
 	return nil
 }
 
 // VerifyEmailChange completes the email change after verification
-func (s *AuthService) VerifyEmailChange(token string) (*model.User, error) {
+func (s *AuthService) VerifyEmailChange(token string, audit model.AuditContext) (*model.User, error) {
 	// ConsumeToken atomically marks token as used (prevents race conditions)
 	tokenModel, err := s.tokenRepository.ConsumeToken(token)
 	if err != nil {
@@ -285,6 +327,7 @@ func (s *AuthService) VerifyEmailChange(token string) (*model.User, error) {
 	}
 
 	// Move pending email to email
+	oldEmail := user.Email
 	user.Email = *user.PendingEmail
 	user.PendingEmail = nil
 
@@ -293,10 +336,17 @@ func (s *AuthService) VerifyEmailChange(token string) (*model.User, error) {
 		return nil, fmt.Errorf("failed to update email: %w", err)
 	}
 
+	// Sign out every device signed in with the old address
+	err = s.RevokeAllSessions(user.ID)
+	if err != nil {
+		slog.Warn("failed to revoke sessions after email change", "error", err, "user_id", user.ID)
+	}
+
+	s.auditService.Record(user, model.AuditEventEmailChanged, audit, map[string]string{"old_email": oldEmail})
 	return user, nil
 }
 
-func (s *AuthService) SetPassword(userID, newPassword string) error {
+func (s *AuthService) SetPassword(userID, newPassword string, audit model.AuditContext) error {
 	user, err := s.userRepository.ByID(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
@@ -323,10 +373,11 @@ func (s *AuthService) SetPassword(userID, newPassword string) error {
 	}
 
 	slog.Info("password set for passwordless account", "user_id", userID)
+	s.auditService.Record(user, model.AuditEventPasswordSet, audit, nil)
 	return nil
 }
 
-func (s *AuthService) RemovePassword(userID string) error {
+func (s *AuthService) RemovePassword(userID string, audit model.AuditContext) error {
 	user, err := s.userRepository.ByID(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
@@ -343,6 +394,7 @@ func (s *AuthService) RemovePassword(userID string) error {
 	}
 
 	slog.Info("password removed, account is now passwordless", "user_id", userID)
+	s.auditService.Record(user, model.AuditEventPasswordRemoved, audit, nil)
 	return nil
 }
 
@@ -373,29 +425,34 @@ func (s *AuthService) SendMagicLink(email string) error {
 			// password_hash is NULL for passwordless accounts
 		}
 
-		err = s.userRepository.Create(user)
-		if err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
-
-		// Create empty profile (name will be set during onboarding)
-		profile := &model.Profile{
-			ID:        uuid.New().String(),
-			UserID:    userID,
-			Name:      "", // Will be filled in onboarding
-			CreatedAt: now,
-		}
-
-		err = s.profileRepository.Create(profile)
+		err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+			err := tx.Users.Create(user)
+			if err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+
+			// Create empty profile (name will be set during onboarding)
+			profile := &model.Profile{
+				ID:        uuid.New().String(),
+				UserID:    userID,
+				Name:      "", // Will be filled in onboarding
+				CreatedAt: now,
+			}
+
+			err = tx.Profiles.Create(profile)
+			if err != nil {
+				return fmt.Errorf("failed to create profile: %w", err)
+			}
+
+			// Personal workspace with a free subscription
+			_, err = s.organizationService.CreatePersonalWorkspace(tx, userID)
+			if err != nil {
+				return fmt.Errorf("failed to create personal workspace: %w", err)
+			}
+			return nil
+		})
 		if err != nil {
-			return fmt.Errorf("failed to create profile: %w", err)
-		}
-
-		// Create free subscription for new user
-		err = s.subscriptionService.CreateFreeSubscription(userID)
-		if err != nil {
-			slog.Warn("failed to create free subscription", "error", err, "user_id", userID)
-			// Don't fail user creation
+			return err
 		}
 
 		slog.Info("new passwordless user created", "email", email, "user_id", userID)
@@ -582,77 +639,3 @@ func (s *AuthService) CompleteOnboarding(userID, name string) error {
 	slog.Info("onboarding completed", "user_id", userID, "name", name)
 	return nil
 }
-
-// AuthenticateOAuth handles OAuth authentication (Google, GitHub, etc.)
-// It creates a new user if one doesn't exist, or returns existing user
-func (s *AuthService) AuthenticateOAuth(email, provider string) (*model.User, error) {
-	email = strings.TrimSpace(strings.ToLower(email))
-
-	// Validate email
-	err := validation.ValidateEmail(email)
-	if err != nil {
-		return nil, ErrInvalidEmail
-	}
-
-	// Check if user exists
-	user, err := s.userRepository.ByEmail(email)
-	if err != nil {
-		if !errors.Is(err, repository.ErrUserNotFound) {
-			return nil, fmt.Errorf("failed to lookup user: %w", err)
-		}
-
-		// User doesn't exist - create new account via OAuth
-		now := time.Now()
-		userID := uuid.New().String()
-
-		user = &model.User{
-			ID:              userID,
-			Email:           email,
-			EmailVerifiedAt: &now, // OAuth provider has verified email
-			CreatedAt:       now,
-			// password_hash is NULL for OAuth accounts
-		}
-
-		err = s.userRepository.Create(user)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create user: %w", err)
-		}
-
-		// Create empty profile (name will be set during onboarding)
-		profile := &model.Profile{
-			ID:        uuid.New().String(),
-			UserID:    userID,
-			Name:      "", // Will be filled in onboarding
-			CreatedAt: now,
-		}
-
-		err = s.profileRepository.Create(profile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create profile: %w", err)
-		}
-
-		// Create free subscription for new user
-		err = s.subscriptionService.CreateFreeSubscription(userID)
-		if err != nil {
-			slog.Warn("failed to create free subscription", "error", err, "user_id", userID)
-			// Don't fail user creation
-		}
-
-		slog.Info("new OAuth user created", "email", email, "user_id", userID, "provider", provider)
-		return user, nil
-	}
-
-	// User exists - ensure email is verified (OAuth provider has verified it)
-	if user.EmailVerifiedAt == nil {
-		now := time.Now()
-		user.EmailVerifiedAt = &now
-		err = s.userRepository.Update(user)
-		if err != nil {
-			slog.Warn("failed to mark email as verified", "error", err, "user_id", user.ID)
-			// Don't fail login
-		}
-	}
-
-	slog.Info("user authenticated via OAuth", "user_id", user.ID, "email", user.Email, "provider", provider)
-	return user, nil
-}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+// secureAccountTokenExpiry is how long the "this wasn't me" link of a new-device alert works
+const secureAccountTokenExpiry = 7 * 24 * time.Hour
+
+// rememberDevice records the device of a sign-in and alerts the user when it's new
+// The first device of an account is remembered silently, that's the sign-up itself.
+// Returns true if an alert was sent. Failures are logged, they never block a sign-in.
+func (s *AuthService) rememberDevice(user *model.User, audit model.AuditContext) bool {
+	fingerprint := model.DeviceFingerprint(audit.UserAgent, audit.IPAddress)
+	now := time.Now()
+
+	known, err := s.knownDeviceRepository.Touch(user.ID, fingerprint, audit.UserAgent, audit.IPAddress, now)
+	if err != nil {
+		slog.Warn("failed to update known device", "error", err, "user_id", user.ID)
+		return false
+	}
+	if known {
+		return false
+	}
+
+	count, err := s.knownDeviceRepository.CountByUser(user.ID)
+	if err != nil {
+		slog.Warn("failed to count known devices", "error", err, "user_id", user.ID)
+		return false
+	}
+
+	err = s.knownDeviceRepository.Create(&model.KnownDevice{
+		UserID:      user.ID,
+		Fingerprint: fingerprint,
+		UserAgent:   audit.UserAgent,
+		IPAddress:   audit.IPAddress,
+	})
+	if err != nil {
+		slog.Warn("failed to remember device", "error", err, "user_id", user.ID)
+		return false
+	}
+	if count == 0 {
+		return false
+	}
+
+	err = s.sendNewDeviceAlert(user, audit, now)
+	if err != nil {
+		slog.Error("failed to send new device alert", "error", err, "user_id", user.ID)
+		return false
+	}
+
+	slog.Info("new device alert sent", "user_id", user.ID, "fingerprint", fingerprint)
+	return true
+}
+
+func (s *AuthService) sendNewDeviceAlert(user *model.User, audit model.AuditContext, signedInAt time.Time) error {
+	secureToken, err := s.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenRepository.Create(&model.Token{
+		UserID:    user.ID,
+		Type:      model.TokenTypeSecureAccount,
+		Token:     secureToken,
+		ExpiresAt: signedInAt.Add(secureAccountTokenExpiry),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create secure account token: %w", err)
+	}
+
+	name := "User"
+	profile, err := s.profileRepository.ByUserID(user.ID)
+	if err == nil {
+		name = profile.Name
+	}
+
+	return s.emailService.SendNewDeviceAlert(user.Email, name, model.DeviceName(audit.UserAgent), audit.IPAddress, signedInAt, secureToken)
+}
+
+// secureAccountTokenTypes are the pending email links SecureAccount invalidates
+// Whoever got into the account may have requested them.
+var secureAccountTokenTypes = []string{
+	model.TokenTypeMagicLink,
+	model.TokenTypePasswordReset,
+	model.TokenTypeEmailChange,
+}
+
+// SecureAccount handles the "this wasn't me" link of a new-device alert
+// Every session is revoked and the password removed, so whoever signed in has to
+// authenticate again and a leaked password doesn't let them back in. Pending magic
+// links, password resets and email changes stop working, and the known devices are
+// forgotten so the next sign-in from anywhere alerts again. The user signs in with
+// a new magic link, which only reaches their own inbox.
+func (s *AuthService) SecureAccount(token string, audit model.AuditContext) (*model.User, error) {
+	tokenModel, err := s.tokenRepository.ConsumeToken(token)
+	if err != nil {
+		return nil, errors.New("invalid or expired link")
+	}
+	if tokenModel.Type != model.TokenTypeSecureAccount {
+		return nil, errors.New("invalid token type")
+	}
+
+	user, err := s.userRepository.ByID(tokenModel.UserID)
+	if err != nil {
+		return nil, err
+	}
+	hadPassword := user.HasPassword()
+
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := tx.Sessions.DeleteByUser(user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		if hadPassword || user.PendingEmail != nil {
+			user.PasswordHash = nil
+			user.PendingEmail = nil
+			err = tx.Users.Update(user)
+			if err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		for _, tokenType := range secureAccountTokenTypes {
+			err = tx.Tokens.DeleteByUserAndType(user.ID, tokenType)
+			if err != nil {
+				return fmt.Errorf("failed to invalidate %s links: %w", tokenType, err)
+			}
+		}
+
+		err = tx.KnownDevices.DeleteByUser(user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to forget known devices: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("account secured from new device alert", "user_id", user.ID, "password_removed", hadPassword)
+	s.auditService.Record(user, model.AuditEventAccountSecured, audit, nil)
+	if hadPassword {
+		s.auditService.Record(user, model.AuditEventPasswordRemoved, audit, nil)
+	}
+	return user, nil
+}
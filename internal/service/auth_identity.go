@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/validation"
+)
+
+var (
+	ErrOAuthAccountExists        = errors.New("an account with this email already exists. Sign in another way and connect it from your settings")
+	ErrOAuthEmailNotVerified     = errors.New("your email is not verified with this provider")
+	ErrIdentityAlreadyLinked     = errors.New("this provider is already connected to your account")
+	ErrIdentityLinkedToOtherUser = errors.New("this account is already connected to a different user")
+	ErrIdentityNotLinked         = errors.New("this provider is not connected to your account")
+	ErrLastLoginMethod           = errors.New("you can't disconnect your only sign-in method. Set a password or add a passkey first")
+)
+
+// OAuthProfile is what a provider tells us about the signed-in account
+type OAuthProfile struct {
+	Provider      string
+	Subject       string // Stable user id at the provider
+	Email         string
+	EmailVerified bool
+}
+
+// AuthenticateOAuth signs in with a provider identity
+// Lookup is by provider subject, so an email change at the provider keeps the account.
+// Unknown identities create a new account, unless the email already belongs to one:
+// accounts are never merged by email, the owner has to link the provider from settings.
+func (s *AuthService) AuthenticateOAuth(profile OAuthProfile) (*model.User, error) {
+	if profile.Subject == "" {
+		return nil, errors.New("missing provider user id")
+	}
+	email := strings.TrimSpace(strings.ToLower(profile.Email))
+
+	identity, err := s.userIdentityRepository.ByProviderUserID(profile.Provider, profile.Subject)
+	if err == nil {
+		user, err := s.userRepository.ByID(identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		err = s.userIdentityRepository.RecordLogin(identity.ID, email, time.Now())
+		if err != nil {
+			slog.Warn("failed to record identity login", "error", err, "user_id", user.ID, "provider", profile.Provider)
+			// Don't fail login
+		}
+
+		slog.Info("user authenticated via OAuth", "user_id", user.ID, "email", user.Email, "provider", profile.Provider)
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrUserIdentityNotFound) {
+		return nil, fmt.Errorf("failed to lookup identity: %w", err)
+	}
+
+	err = validation.ValidateEmail(email)
+	if err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	if !profile.EmailVerified {
+		return nil, ErrOAuthEmailNotVerified
+	}
+
+	_, err = s.userRepository.ByEmail(email)
+	if err == nil {
+		return nil, ErrOAuthAccountExists
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	// New account via OAuth
+	now := time.Now()
+	userID := uuid.New().String()
+
+	user := &model.User{
+		ID:              userID,
+		Email:           email,
+		EmailVerifiedAt: &now, // OAuth provider has verified email
+		CreatedAt:       now,
+		// password_hash is NULL for OAuth accounts
+	}
+
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := tx.Users.Create(user)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		err = tx.UserIdentities.Create(&model.UserIdentity{
+			UserID:         userID,
+			Provider:       profile.Provider,
+			ProviderUserID: profile.Subject,
+			Email:          email,
+			LastLoginAt:    &now,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to link identity: %w", err)
+		}
+
+		// Create empty profile (name will be set during onboarding)
+		profileRow := &model.Profile{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			Name:      "", // Will be filled in onboarding
+			CreatedAt: now,
+		}
+
+		err = tx.Profiles.Create(profileRow)
+		if err != nil {
+			return fmt.Errorf("failed to create profile: %w", err)
+		}
+
+		// Personal workspace with a free subscription
+		_, err = s.organizationService.CreatePersonalWorkspace(tx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to create personal workspace: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("new OAuth user created", "email", email, "user_id", userID, "provider", profile.Provider)
+	return user, nil
+}
+
+// LinkIdentity connects a provider account to a signed-in user
+func (s *AuthService) LinkIdentity(userID string, profile OAuthProfile, audit model.AuditContext) error {
+	if profile.Subject == "" {
+		return errors.New("missing provider user id")
+	}
+
+	existing, err := s.userIdentityRepository.ByProviderUserID(profile.Provider, profile.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return ErrIdentityLinkedToOtherUser
+		}
+		return ErrIdentityAlreadyLinked
+	}
+	if !errors.Is(err, repository.ErrUserIdentityNotFound) {
+		return fmt.Errorf("failed to lookup identity: %w", err)
+	}
+
+	identities, err := s.userIdentityRepository.ByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == profile.Provider {
+			return ErrIdentityAlreadyLinked
+		}
+	}
+
+	err = s.userIdentityRepository.Create(&model.UserIdentity{
+		UserID:         userID,
+		Provider:       profile.Provider,
+		ProviderUserID: profile.Subject,
+		Email:          strings.TrimSpace(strings.ToLower(profile.Email)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	slog.Info("identity linked", "user_id", userID, "provider", profile.Provider)
+
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		slog.Warn("failed to get user for audit event", "error", err, "user_id", userID)
+		return nil
+	}
+	s.auditService.Record(user, model.AuditEventIdentityLinked, audit, map[string]string{"provider": profile.Provider})
+	return nil
+}
+
+// UnlinkIdentity disconnects a provider
+// The account must keep at least one other way to sign in: a password, a passkey or another provider.
+func (s *AuthService) UnlinkIdentity(userID, provider string, audit model.AuditContext) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	identities, err := s.userIdentityRepository.ByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+
+	linked := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked = true
+			break
+		}
+	}
+	if !linked {
+		return ErrIdentityNotLinked
+	}
+
+	if !user.HasPassword() && len(identities) == 1 {
+		passkeys, err := s.passkeyRepository.ByUserID(userID)
+		if err != nil {
+			return fmt.Errorf("failed to get passkeys: %w", err)
+		}
+		if len(passkeys) == 0 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	err = s.userIdentityRepository.Delete(userID, provider)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserIdentityNotFound) {
+			return ErrIdentityNotLinked
+		}
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+
+	slog.Info("identity unlinked", "user_id", userID, "provider", provider)
+	s.auditService.Record(user, model.AuditEventIdentityUnlinked, audit, map[string]string{"provider": provider})
+	return nil
+}
+
+// Identities returns the user's linked provider accounts
+func (s *AuthService) Identities(userID string) ([]*model.UserIdentity, error) {
+	identities, err := s.userIdentityRepository.ByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identities: %w", err)
+	}
+	return identities, nil
+}
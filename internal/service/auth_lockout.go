@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+// Password sign-in throttling per account, on top of the per-IP rate limit
+// The first failures are free, then every attempt has to wait a doubling delay
+// after the last failure, and too many failures lock the email for a while.
+// Second factor codes are throttled the same way under twoFactorAttemptKey.
+const (
+	loginFreeAttempts    = 3
+	loginBaseDelay       = 5 * time.Second
+	loginMaxDelay        = 5 * time.Minute
+	loginLockoutAttempts = 10
+	loginLockoutDuration = 15 * time.Minute
+	// loginAttemptWindow forgets failures older than this
+	loginAttemptWindow = 24 * time.Hour
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many failed sign-in attempts, please wait a moment and try again")
+	ErrAccountLocked   = errors.New("password sign-in is temporarily locked after too many failed attempts")
+	ErrTwoFactorLocked = errors.New("two-factor sign-in is temporarily locked after too many invalid codes")
+)
+
+// twoFactorAttemptKey keys the invalid second factor codes of a user in login_attempts
+// Kept apart from the email's password failures, a correct password must not reset them.
+func twoFactorAttemptKey(userID string) string {
+	return "two-factor:" + userID
+}
+
+// loginDelay is how long after the last failure the next attempt has to wait
+func loginDelay(failedCount int) time.Duration {
+	if failedCount < loginFreeAttempts {
+		return 0
+	}
+	doublings := failedCount - loginFreeAttempts
+	if doublings > 10 {
+		return loginMaxDelay
+	}
+	return min(loginBaseDelay<<doublings, loginMaxDelay)
+}
+
+// checkLoginAttempts refuses a sign-in while the key is locked or backing off
+// key is the email for passwords, twoFactorAttemptKey for second factor codes.
+func (s *AuthService) checkLoginAttempts(key string) error {
+	attempt, err := s.loginAttemptRepository.ByEmail(key)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	if attempt.IsLocked() {
+		return ErrAccountLocked
+	}
+
+	since := time.Since(attempt.LastFailedAt)
+	if since < loginAttemptWindow && since < loginDelay(attempt.FailedCount) {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed password and locks the email once there are too many
+// user is nil for unknown emails, they are locked the same way but nobody is notified.
+func (s *AuthService) recordFailedLogin(email string, user *model.User, audit model.AuditContext) {
+	attempt, until, locked := s.recordFailure(email, audit)
+	if !locked {
+		return
+	}
+
+	slog.Warn("password sign-in locked", "email", email, "failed_count", attempt.FailedCount, "ip_address", audit.IPAddress)
+	if user == nil {
+		return
+	}
+
+	s.auditService.Record(user, model.AuditEventAccountLocked, audit, map[string]string{"failed_attempts": fmt.Sprint(attempt.FailedCount)})
+
+	name := "User"
+	profile, err := s.profileRepository.ByUserID(user.ID)
+	if err == nil {
+		name = profile.Name
+	}
+	err = s.emailService.SendAccountLockedEmail(user.Email, name, until)
+	if err != nil {
+		slog.Error("failed to send account locked email", "error", err, "user_id", user.ID)
+	}
+}
+
+// recordFailedTwoFactor counts an invalid second factor code and locks the user's
+// challenges once there are too many, whoever guesses already passed the first factor
+// Returns true if this failure took the lock.
+func (s *AuthService) recordFailedTwoFactor(user *model.User, audit model.AuditContext) bool {
+	attempt, _, locked := s.recordFailure(twoFactorAttemptKey(user.ID), audit)
+	if !locked {
+		return false
+	}
+
+	slog.Warn("two-factor sign-in locked", "user_id", user.ID, "failed_count", attempt.FailedCount, "ip_address", audit.IPAddress)
+	s.auditService.Record(user, model.AuditEventAccountLocked, audit, map[string]string{
+		"failed_attempts": fmt.Sprint(attempt.FailedCount),
+		"factor":          "two_factor",
+	})
+	return true
+}
+
+// recordFailure counts a failure for key and locks it once there are too many
+// locked is only true for the failure that took the lock.
+func (s *AuthService) recordFailure(key string, audit model.AuditContext) (attempt *model.LoginAttempt, until time.Time, locked bool) {
+	now := time.Now()
+
+	attempt, err := s.loginAttemptRepository.RecordFailure(key, now, now.Add(-loginAttemptWindow))
+	if err != nil {
+		slog.Error("failed to record failed login", "error", err, "key", key)
+		return nil, time.Time{}, false
+	}
+	if attempt.FailedCount < loginLockoutAttempts {
+		return attempt, time.Time{}, false
+	}
+
+	until = now.Add(loginLockoutDuration)
+	locked, err = s.loginAttemptRepository.Lock(key, until, now)
+	if err != nil {
+		slog.Error("failed to lock login", "error", err, "key", key, "ip_address", audit.IPAddress)
+		return attempt, time.Time{}, false
+	}
+	return attempt, until, locked
+}
+
+// LoginAttempt returns the failed sign-ins of an email, nil if there are none
+func (s *AuthService) LoginAttempt(email string) (*model.LoginAttempt, error) {
+	attempt, err := s.loginAttemptRepository.ByEmail(email)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+	return attempt, nil
+}
+
+// UnlockLogin forgets the failed sign-ins and second factor codes of a user, used by admins
+func (s *AuthService) UnlockLogin(user *model.User) error {
+	err := s.loginAttemptRepository.Reset(user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+
+	err = s.loginAttemptRepository.Reset(twoFactorAttemptKey(user.ID))
+	if err != nil {
+		return fmt.Errorf("failed to unlock two-factor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+// sessionTouchInterval limits last_seen_at writes to one per session per interval
+const sessionTouchInterval = 5 * time.Minute
+
+// impersonationExpiry bounds how long an admin can act as another user
+const impersonationExpiry = time.Hour
+
+var (
+	ErrSessionRevoked = errors.New("session has been revoked or expired")
+)
+
+// StartSession records a new server-side session and sets the auth cookie
+// The JWT carries the session id, so deleting the row signs the device out.
+// method is how the user signed in (e.g. password, passkey, google) and goes into the audit event.
+// Sign-ins from a device the user hasn't used before send a new-device alert.
+func (s *AuthService) StartSession(w http.ResponseWriter, user *model.User, method string, audit model.AuditContext) error {
+	session := &model.Session{
+		UserID:    user.ID,
+		UserAgent: audit.UserAgent,
+		IPAddress: audit.IPAddress,
+		ExpiresAt: time.Now().Add(s.jwtExpiry),
+	}
+
+	err := s.sessionRepository.Create(session)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	jwtToken, err := s.GenerateJWT(user, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to generate JWT: %w", err)
+	}
+
+	s.SetJWTCookie(w, jwtToken, session.ExpiresAt)
+
+	metadata := map[string]string{"method": method}
+	if s.rememberDevice(user, audit) {
+		metadata["new_device"] = "true"
+	}
+	s.auditService.Record(user, model.AuditEventLogin, audit, metadata)
+	return nil
+}
+
+// ValidateSession checks that the session from a JWT still exists and belongs to the user
+func (s *AuthService) ValidateSession(sessionID, userID string) (*model.Session, error) {
+	session, err := s.sessionRepository.ByID(sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.UserID != userID || session.IsExpired() {
+		return nil, ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		now := time.Now()
+		err = s.sessionRepository.Touch(session.ID, now)
+		if err != nil {
+			slog.Warn("failed to update session last seen", "error", err, "session_id", session.ID)
+		} else {
+			session.LastSeenAt = now
+		}
+	}
+
+	return session, nil
+}
+
+func (s *AuthService) Sessions(userID string) ([]*model.Session, error) {
+	return s.sessionRepository.ByUserID(userID)
+}
+
+// RevokeSession signs a single device out
+func (s *AuthService) RevokeSession(userID, sessionID string) error {
+	err := s.sessionRepository.Delete(userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	slog.Info("session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// RevokeOtherSessions signs out every device except the current one
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID string) error {
+	err := s.sessionRepository.DeleteByUserExcept(userID, currentSessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	slog.Info("other sessions revoked", "user_id", userID)
+	return nil
+}
+
+// RevokeAllSessions signs out every device, including the current one
+func (s *AuthService) RevokeAllSessions(userID string) error {
+	err := s.sessionRepository.DeleteByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	slog.Info("all sessions revoked", "user_id", userID)
+	return nil
+}
+
+// StartImpersonation signs an admin in as another user
+// The JWT keeps the admin's own session id and adds impersonator_id, so revoking
+// the admin's session ends the impersonation and StopImpersonation can restore it.
+func (s *AuthService) StartImpersonation(w http.ResponseWriter, admin *model.User, session *model.Session, target *model.User) error {
+	expiresAt := time.Now().Add(impersonationExpiry)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	claims := jwt.MapClaims{
+		"user_id":         target.ID,
+		"session_id":      session.ID,
+		"impersonator_id": admin.ID,
+		"email":           target.Email,
+		"exp":             expiresAt.Unix(),
+		"iat":             time.Now().Unix(),
+	}
+
+	jwtToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return fmt.Errorf("failed to generate JWT: %w", err)
+	}
+
+	s.SetJWTCookie(w, jwtToken, expiresAt)
+	slog.Info("impersonation started", "admin_id", admin.ID, "user_id", target.ID, "session_id", session.ID)
+	return nil
+}
+
+// StopImpersonation gives the admin their own JWT for the same session back
+func (s *AuthService) StopImpersonation(w http.ResponseWriter, admin *model.User, session *model.Session) error {
+	jwtToken, err := s.GenerateJWT(admin, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to generate JWT: %w", err)
+	}
+
+	s.SetJWTCookie(w, jwtToken, session.ExpiresAt)
+	slog.Info("impersonation stopped", "admin_id", admin.ID, "session_id", session.ID)
+	return nil
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/totp"
+)
+
+const (
+	// TwoFactorCookieName holds the pending sign-in between the first factor and the TOTP challenge
+	TwoFactorCookieName = "two_factor_challenge"
+
+	twoFactorChallengeExpiry = 5 * time.Minute
+	recoveryCodeCount        = 10
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("invalid authentication code")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorSetupRequired  = errors.New("two-factor setup has not been started")
+	ErrInvalidChallenge        = errors.New("invalid or expired two-factor challenge")
+)
+
+// BeginTwoFactorSetup generates a new TOTP secret for the user
+// The secret is stored but 2FA stays disabled until EnableTwoFactor confirms a code
+func (s *AuthService) BeginTwoFactorSetup(userID string) (secret, uri string, err error) {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.HasTwoFactor() {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	user.TOTPSecret = &secret
+	err = s.userRepository.Update(user)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to save secret: %w", err)
+	}
+
+	return secret, totp.ProvisioningURI(secret, s.appName, user.Email), nil
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app
+// Returns the plain-text recovery codes (shown once, only hashes are stored)
+func (s *AuthService) EnableTwoFactor(userID, code string) ([]string, error) {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.TOTPSecret == nil || *user.TOTPSecret == "" {
+		return nil, ErrTwoFactorSetupRequired
+	}
+
+	err = s.acceptTOTPCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	err = s.userRepository.Update(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor: %w", err)
+	}
+
+	slog.Info("two-factor authentication enabled", "user_id", user.ID)
+	return codes, nil
+}
+
+// DisableTwoFactor turns off 2FA after verifying a current code or recovery code
+func (s *AuthService) DisableTwoFactor(userID, code string, audit model.AuditContext) error {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	err = s.VerifyTwoFactorCode(user, code, audit)
+	if err != nil {
+		return err
+	}
+
+	// Update also resets the last accepted time step along with the secret
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	user.TOTPLastCounter = nil
+	err = s.userRepository.Update(user)
+	if err != nil {
+		return fmt.Errorf("failed to disable two-factor: %w", err)
+	}
+
+	err = s.recoveryCodeRepository.DeleteByUser(user.ID)
+	if err != nil {
+		slog.Warn("failed to delete recovery codes", "error", err, "user_id", user.ID)
+	}
+
+	slog.Info("two-factor authentication disabled", "user_id", user.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after verifying a current code
+func (s *AuthService) RegenerateRecoveryCodes(userID, code string, audit model.AuditContext) ([]string, error) {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.HasTwoFactor() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	err = s.VerifyTwoFactorCode(user, code, audit)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("recovery codes regenerated", "user_id", user.ID)
+	return codes, nil
+}
+
+// VerifyTwoFactorCode accepts either a TOTP code or an unused recovery code
+// Recovery codes are consumed on success, TOTP codes work once (see acceptTOTPCode).
+// Invalid codes are throttled per user like passwords (see auth_lockout.go), so
+// a challenge can't be brute-forced from many addresses.
+func (s *AuthService) VerifyTwoFactorCode(user *model.User, code string, audit model.AuditContext) error {
+	if !user.HasTwoFactor() || user.TOTPSecret == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	key := twoFactorAttemptKey(user.ID)
+	err := s.checkLoginAttempts(key)
+	if errors.Is(err, ErrAccountLocked) {
+		return ErrTwoFactorLocked
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.checkTwoFactorCode(user, code)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if s.recordFailedTwoFactor(user, audit) {
+			return ErrTwoFactorLocked
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.loginAttemptRepository.Reset(key)
+	if err != nil {
+		slog.Warn("failed to reset two-factor attempts", "error", err, "user_id", user.ID)
+	}
+	return nil
+}
+
+func (s *AuthService) checkTwoFactorCode(user *model.User, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if len(code) == totp.Digits {
+		return s.acceptTOTPCode(user, code)
+	}
+
+	err := s.recoveryCodeRepository.Consume(user.ID, hashRecoveryCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return ErrInvalidTwoFactorCode
+		}
+		return fmt.Errorf("failed to check recovery code: %w", err)
+	}
+
+	slog.Info("recovery code used", "user_id", user.ID)
+	return nil
+}
+
+// acceptTOTPCode checks a TOTP code and marks its time step used
+// A code that was already accepted is invalid, even while it's still current.
+func (s *AuthService) acceptTOTPCode(user *model.User, code string) error {
+	counter, ok := totp.Match(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	accepted, err := s.userRepository.AcceptTOTPCounter(user.ID, counter)
+	if err != nil {
+		return fmt.Errorf("failed to store code time step: %w", err)
+	}
+	if !accepted {
+		slog.Warn("two-factor code replayed", "user_id", user.ID)
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPLastCounter = &counter
+	return nil
+}
+
+// TwoFactorChallenge is a sign-in that passed the first factor and waits for the second
+type TwoFactorChallenge struct {
+	User *model.User
+	// Next is where to redirect after the second factor succeeds
+	Next string
+	// RemovePassword is set by the forgot password flow, the password is only
+	// removed once the second factor succeeds
+	RemovePassword bool
+}
+
+// GenerateTwoFactorChallenge creates a short-lived token for a user who passed the first factor
+// next is where to redirect after the second factor succeeds
+func (s *AuthService) GenerateTwoFactorChallenge(user *model.User, next string, removePassword bool) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":         user.ID,
+		"next":            next,
+		"remove_password": removePassword,
+		"exp":             time.Now().Add(twoFactorChallengeExpiry).Unix(),
+		"iat":             time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.twoFactorKey())
+}
+
+// VerifyTwoFactorChallenge returns the pending sign-in of a challenge token
+func (s *AuthService) VerifyTwoFactorChallenge(tokenString string) (*TwoFactorChallenge, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.twoFactorKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidChallenge
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidChallenge
+	}
+
+	userID, _ := claims["user_id"].(string)
+	next, _ := claims["next"].(string)
+	removePassword, _ := claims["remove_password"].(bool)
+
+	// Only allow local redirects
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/app/dashboard"
+	}
+
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	return &TwoFactorChallenge{User: user, Next: next, RemovePassword: removePassword}, nil
+}
+
+func (s *AuthService) SetTwoFactorCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     TwoFactorCookieName,
+		Value:    token,
+		Path:     "/auth",
+		MaxAge:   int(twoFactorChallengeExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   s.isProduction,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (s *AuthService) ClearTwoFactorCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     TwoFactorCookieName,
+		Value:    "",
+		Path:     "/auth",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.isProduction,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// twoFactorKey derives a separate signing key so challenge tokens
+// can never be used as auth_token session cookies
+func (s *AuthService) twoFactorKey() []byte {
+	sum := sha256.Sum256([]byte("two-factor-challenge:" + s.jwtSecret))
+	return sum[:]
+}
+
+// replaceRecoveryCodes generates a fresh set of codes and stores their hashes
+func (s *AuthService) replaceRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	err := s.recoveryCodeRepository.ReplaceAll(userID, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code like "k3v9q-a7m2x" (50 bits of entropy)
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 7)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode normalizes (case, dashes, spaces) and hashes a recovery code
+// Codes are high-entropy random values, so a fast hash is sufficient
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.ReplaceAll(normalized, "-", "")
+	normalized = strings.ReplaceAll(normalized, " ", "")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
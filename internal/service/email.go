@@ -1,90 +1,91 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/a-h/templ"
 	"github.com/resend/resend-go/v2"
+	"github.com/templui/goilerplate/internal/mail"
+	"github.com/templui/goilerplate/internal/queue"
+	"github.com/templui/goilerplate/internal/ui/emails"
 )
 
+// JobSendEmail is the queue job type for outbound email, handled by EmailService.Deliver
+const JobSendEmail = "email.send"
+
+// EmailService renders emails and queues them for delivery
+// Send* methods only fail if the email can't be queued, delivery is retried by the job queue.
 type EmailService struct {
-	client     *resend.Client
-	fromEmail  string
-	audienceID string
-	isDev      bool
-	appURL     string
-	appName    string
+	client       *resend.Client // Newsletter contacts only, emails go through the transport
+	transport    mail.Transport
+	queue        *queue.Queue
+	fromEmail    string
+	audienceID   string
+	isDev        bool
+	appURL       string
+	appName      string
+	supportEmail string
+	logoURL      string
+}
+
+// emailMessage is the queued job payload of an outbound email
+type emailMessage struct {
+	Type    string            `json:"type"` // For logs, e.g. "magic_link"
+	To      string            `json:"to"`
+	Subject string            `json:"subject"`
+	Text    string            `json:"text"`
+	HTML    string            `json:"html"`
+	Headers map[string]string `json:"headers,omitempty"`
+	URL     string            `json:"url,omitempty"` // Logged in dev mode, so links can be opened without a mailbox
 }
 
-func NewEmailService(apiKey, fromEmail, audienceID, appURL, appName string, isDev bool) *EmailService {
+func NewEmailService(apiKey, fromEmail, audienceID, appURL, appName, supportEmail, logoURL string, isDev bool, transport mail.Transport, jobQueue *queue.Queue) *EmailService {
 	var client *resend.Client
 	if apiKey != "" && !isDev {
 		client = resend.NewClient(apiKey)
 	}
 
 	return &EmailService{
-		client:     client,
-		fromEmail:  fromEmail,
-		audienceID: audienceID,
-		isDev:      isDev,
-		appURL:     appURL,
-		appName:    appName,
+		client:       client,
+		transport:    transport,
+		queue:        jobQueue,
+		fromEmail:    fromEmail,
+		audienceID:   audienceID,
+		isDev:        isDev,
+		appURL:       appURL,
+		appName:      appName,
+		supportEmail: supportEmail,
+		logoURL:      logoURL,
 	}
 }
 
 func (s *EmailService) SendForgotPasswordEmail(email, token, name string) error {
 	signInURL := fmt.Sprintf("%s/auth/forgot-password/%s", s.appURL, token)
-	subject, body := forgotPasswordEmailTemplate(signInURL, s.appName)
-
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "forgot_password", "to", email, "subject", subject, "url", signInURL)
-		return nil
-	}
+	subject, content := forgotPasswordEmailTemplate(s.layout(""), signInURL)
 
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
-	}
-
-	params := &resend.SendEmailRequest{
-		From:    s.fromEmail,
-		To:      []string{email},
+	return s.enqueue(emailMessage{
+		Type:    "forgot_password",
+		To:      email,
 		Subject: subject,
-		Text:    body,
-	}
-
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "forgot_password", "to", email)
-	}
-	return err
+		URL:     signInURL,
+	}, content)
 }
 
 func (s *EmailService) SendMagicLinkEmail(email, token, name string) error {
 	magicURL := fmt.Sprintf("%s/auth/magic-link/%s", s.appURL, token)
-	subject, body := magicLinkEmailTemplate(magicURL, s.appName)
+	subject, content := magicLinkEmailTemplate(s.layout(""), magicURL)
 
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "magic_link", "to", email, "subject", subject, "url", magicURL)
-		return nil
-	}
-
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
-	}
-
-	params := &resend.SendEmailRequest{
-		From:    s.fromEmail,
-		To:      []string{email},
+	return s.enqueue(emailMessage{
+		Type:    "magic_link",
+		To:      email,
 		Subject: subject,
-		Text:    body,
-	}
-
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "magic_link", "to", email)
-	}
-	return err
+		URL:     magicURL,
+	}, content)
 }
 
 func (s *EmailService) SubscribeNewsletter(email string) error {
@@ -122,106 +123,191 @@ func (s *EmailService) SubscribeNewsletter(email string) error {
 
 func (s *EmailService) SendWelcomeEmail(email, name string) error {
 	dashboardURL := fmt.Sprintf("%s/app/dashboard", s.appURL)
-	subject, body := welcomeEmailTemplate(name, dashboardURL, s.appName)
+	subject, content := welcomeEmailTemplate(s.layout(""), name, dashboardURL)
 
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "welcome", "to", email, "subject", subject, "url", dashboardURL)
-		return nil
-	}
+	return s.enqueue(emailMessage{
+		Type:    "welcome",
+		To:      email,
+		Subject: subject,
+		URL:     dashboardURL,
+	}, content)
+}
 
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
-	}
+func (s *EmailService) SendEmailChangeVerification(newEmail, token, userName string) error {
+	verifyURL := fmt.Sprintf("%s/auth/verify-email-change/%s", s.appURL, token)
+	subject, content := emailChangeVerificationTemplate(s.layout(""), userName, verifyURL)
 
-	params := &resend.SendEmailRequest{
-		From:    s.fromEmail,
-		To:      []string{email},
+	return s.enqueue(emailMessage{
+		Type:    "email_change_verification",
+		To:      newEmail,
 		Subject: subject,
-		Text:    body,
-	}
+		URL:     verifyURL,
+	}, content)
+}
 
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "welcome", "to", email)
-	}
-	return err
+func (s *EmailService) SendEmailChangeNotification(oldEmail, newEmail, userName string) error {
+	subject, content := emailChangeNotificationTemplate(s.layout(""), userName, newEmail)
+
+	return s.enqueue(emailMessage{
+		Type:    "email_change_notification",
+		To:      oldEmail,
+		Subject: subject,
+	}, content)
 }
 
-func (s *EmailService) SendEmailChangeVerification(newEmail, token, userName string) error {
-	verifyURL := fmt.Sprintf("%s/auth/verify-email-change/%s", s.appURL, token)
-	subject, body := emailChangeVerificationTemplate(userName, verifyURL, s.appName)
+// SendNewDeviceAlert tells the user about a sign-in from a device they haven't used before
+func (s *EmailService) SendNewDeviceAlert(email, name, device, ipAddress string, signedInAt time.Time, token string) error {
+	secureURL := fmt.Sprintf("%s/auth/secure-account/%s", s.appURL, token)
+	subject, content := newDeviceAlertEmailTemplate(s.layout(""), name, device, ipAddress, signedInAt.UTC().Format("Jan 2, 2006 at 15:04 UTC"), secureURL)
 
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "email_change_verification", "to", newEmail, "subject", subject, "url", verifyURL)
-		return nil
-	}
+	return s.enqueue(emailMessage{
+		Type:    "new_device_alert",
+		To:      email,
+		Subject: subject,
+		URL:     secureURL,
+	}, content)
+}
 
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
-	}
+// SendAccountLockedEmail warns the user that password sign-in was locked after too many failures
+func (s *EmailService) SendAccountLockedEmail(email, name string, until time.Time) error {
+	signInURL := fmt.Sprintf("%s/auth", s.appURL)
+	subject, content := accountLockedEmailTemplate(s.layout(""), name, until.UTC().Format("15:04 UTC"), signInURL)
 
-	params := &resend.SendEmailRequest{
-		From:    s.fromEmail,
-		To:      []string{newEmail},
+	return s.enqueue(emailMessage{
+		Type:    "account_locked",
+		To:      email,
 		Subject: subject,
-		Text:    body,
-	}
+		URL:     signInURL,
+	}, content)
+}
 
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "email_change_verification", "to", newEmail)
-	}
-	return err
+func (s *EmailService) SendWorkspaceInvitationEmail(email, token, inviterName, workspaceName, role string) error {
+	acceptURL := fmt.Sprintf("%s/invitations/%s", s.appURL, token)
+	subject, content := workspaceInvitationEmailTemplate(s.layout(""), inviterName, workspaceName, role, acceptURL)
+
+	return s.enqueue(emailMessage{
+		Type:    "workspace_invitation",
+		To:      email,
+		Subject: subject,
+		URL:     acceptURL,
+	}, content)
 }
 
-func (s *EmailService) SendEmailChangeNotification(oldEmail, newEmail, userName string) error {
-	subject, body := emailChangeNotificationTemplate(userName, newEmail, s.appName)
+func (s *EmailService) SendAccountDeletedEmail(email, name string) error {
+	subject, content := accountDeletedEmailTemplate(s.layout(""), name)
 
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "email_change_notification", "to", oldEmail, "new_email", newEmail)
-		return nil
-	}
+	return s.enqueue(emailMessage{
+		Type:    "account_deleted",
+		To:      email,
+		Subject: subject,
+	}, content)
+}
 
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
+func (s *EmailService) SendGoalReminderEmail(email, name string, goalTitles []string, inactiveDays int, unsubscribeToken string) error {
+	goalsURL := fmt.Sprintf("%s/app/goals", s.appURL)
+	unsubscribeURL := fmt.Sprintf("%s/unsubscribe/%s", s.appURL, unsubscribeToken)
+	subject, content := goalReminderEmailTemplate(s.layout(unsubscribeURL), name, goalTitles, inactiveDays, goalsURL)
+
+	return s.enqueue(emailMessage{
+		Type:    "goal_reminder",
+		To:      email,
+		Subject: subject,
+		Headers: unsubscribeHeaders(unsubscribeURL),
+		URL:     unsubscribeURL,
+	}, content)
+}
+
+func (s *EmailService) SendWeeklyDigestEmail(email, name string, goals []emails.GoalDigest, unsubscribeToken string) error {
+	goalsURL := fmt.Sprintf("%s/app/goals", s.appURL)
+	unsubscribeURL := fmt.Sprintf("%s/unsubscribe/%s", s.appURL, unsubscribeToken)
+	subject, content := weeklyDigestEmailTemplate(s.layout(unsubscribeURL), name, goals, goalsURL)
+
+	return s.enqueue(emailMessage{
+		Type:    "weekly_digest",
+		To:      email,
+		Subject: subject,
+		Headers: unsubscribeHeaders(unsubscribeURL),
+		URL:     unsubscribeURL,
+	}, content)
+}
+
+// Deliver sends a queued email, registered as the JobSendEmail queue handler
+// Returned errors make the queue retry with backoff
+func (s *EmailService) Deliver(ctx context.Context, payload []byte) error {
+	var msg emailMessage
+	err := json.Unmarshal(payload, &msg)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("invalid email payload: %w", err))
 	}
 
-	params := &resend.SendEmailRequest{
+	err = s.transport.Send(ctx, &mail.Message{
 		From:    s.fromEmail,
-		To:      []string{oldEmail},
-		Subject: subject,
-		Text:    body,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+		Headers: msg.Headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send %s email: %w", msg.Type, err)
 	}
 
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "email_change_notification", "to", oldEmail)
+	if s.isDev {
+		slog.Info("email sent (dev mode)", "type", msg.Type, "to", msg.To, "subject", msg.Subject, "url", msg.URL)
+		return nil
 	}
-	return err
+
+	slog.Info("email sent", "type", msg.Type, "to", msg.To)
+	return nil
 }
 
-func (s *EmailService) SendAccountDeletedEmail(email, name string) error {
-	subject, body := accountDeletedEmailTemplate(name, s.appName)
+// enqueue renders an email and stores it for delivery by the job queue workers
+// Rendering happens once, so retries send exactly the same email
+func (s *EmailService) enqueue(msg emailMessage, content templ.Component) error {
+	var err error
+	msg.HTML, msg.Text, err = renderEmail(content)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", msg.Type, err)
+	}
 
-	if s.isDev {
-		slog.Info("email sent (dev mode)", "type", "account_deleted", "to", email, "subject", subject)
-		return nil
+	err = s.queue.Enqueue(JobSendEmail, msg)
+	if err != nil {
+		return fmt.Errorf("failed to queue %s email: %w", msg.Type, err)
 	}
+	return nil
+}
 
-	if s.client == nil {
-		return fmt.Errorf("email service not configured (missing RESEND_API_KEY)")
+// layout returns the shared email branding, unsubscribeURL is optional
+func (s *EmailService) layout(unsubscribeURL string) emails.LayoutProps {
+	return emails.LayoutProps{
+		AppName:        s.appName,
+		AppURL:         s.appURL,
+		LogoURL:        s.logoURL,
+		SupportEmail:   s.supportEmail,
+		UnsubscribeURL: unsubscribeURL,
 	}
+}
 
-	params := &resend.SendEmailRequest{
-		From:    s.fromEmail,
-		To:      []string{email},
-		Subject: subject,
-		Text:    body,
+// renderEmail renders the HTML email and its generated plain-text alternative
+func renderEmail(content templ.Component) (html, text string, err error) {
+	var buf bytes.Buffer
+	err = content.Render(context.Background(), &buf)
+	if err != nil {
+		return "", "", err
+	}
+
+	text, err = mail.HTMLToText(buf.String())
+	if err != nil {
+		return "", "", err
 	}
 
-	_, err := s.client.Emails.SendWithContext(context.Background(), params)
-	if err == nil {
-		slog.Info("email sent", "type", "account_deleted", "to", email)
+	return buf.String(), text, nil
+}
+
+// unsubscribeHeaders enables the one-click unsubscribe button in mail clients (RFC 8058)
+func unsubscribeHeaders(unsubscribeURL string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
 	}
-	return err
 }
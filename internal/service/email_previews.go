@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a-h/templ"
+	"github.com/templui/goilerplate/internal/ui/emails"
+)
+
+var (
+	ErrEmailPreviewNotFound = errors.New("email preview not found")
+)
+
+// EmailPreview is an email rendered with sample data (development preview)
+type EmailPreview struct {
+	Name    string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type emailPreviewTemplate struct {
+	name   string
+	render func(s *EmailService) (string, templ.Component)
+}
+
+// emailPreviewTemplates lists every email with sample data, add new emails here
+var emailPreviewTemplates = []emailPreviewTemplate{
+	{"magic_link", func(s *EmailService) (string, templ.Component) {
+		return magicLinkEmailTemplate(s.layout(""), s.appURL+"/auth/magic-link/sample-token")
+	}},
+	{"forgot_password", func(s *EmailService) (string, templ.Component) {
+		return forgotPasswordEmailTemplate(s.layout(""), s.appURL+"/auth/forgot-password/sample-token")
+	}},
+	{"welcome", func(s *EmailService) (string, templ.Component) {
+		return welcomeEmailTemplate(s.layout(""), "Jane", s.appURL+"/app/dashboard")
+	}},
+	{"email_change_verification", func(s *EmailService) (string, templ.Component) {
+		return emailChangeVerificationTemplate(s.layout(""), "Jane", s.appURL+"/auth/verify-email-change/sample-token")
+	}},
+	{"email_change_notification", func(s *EmailService) (string, templ.Component) {
+		return emailChangeNotificationTemplate(s.layout(""), "Jane", "jane.new@example.com")
+	}},
+	{"new_device_alert", func(s *EmailService) (string, templ.Component) {
+		return newDeviceAlertEmailTemplate(s.layout(""), "Jane", "Firefox on Windows", "203.0.113.42", "Jan 2, 2026 at 15:04 UTC", s.appURL+"/auth/secure-account/sample-token")
+	}},
+	{"account_locked", func(s *EmailService) (string, templ.Component) {
+		return accountLockedEmailTemplate(s.layout(""), "Jane", "15:04 UTC", s.appURL+"/auth")
+	}},
+	{"account_deleted", func(s *EmailService) (string, templ.Component) {
+		return accountDeletedEmailTemplate(s.layout(""), "Jane")
+	}},
+	{"workspace_invitation", func(s *EmailService) (string, templ.Component) {
+		return workspaceInvitationEmailTemplate(s.layout(""), "Jane", "Acme Inc", "Member", s.appURL+"/invitations/sample-token")
+	}},
+	{"goal_reminder", func(s *EmailService) (string, templ.Component) {
+		goals := []string{"Learn Spanish", "Run a marathon"}
+		return goalReminderEmailTemplate(s.layout(s.appURL+"/unsubscribe/sample-token"), "Jane", goals, 3, s.appURL+"/app/goals")
+	}},
+	{"weekly_digest", func(s *EmailService) (string, templ.Component) {
+		goals := []emails.GoalDigest{
+			{Title: "Learn Spanish", CurrentStep: 42, TargetSteps: 100, StepsThisWeek: 6},
+			{Title: "30-day yoga challenge", CurrentStep: 30, TargetSteps: 30, StepsThisWeek: 3, Completed: true},
+			{Title: "Read 12 books", CurrentStep: 4, TargetSteps: 12, StepsThisWeek: 0},
+		}
+		return weeklyDigestEmailTemplate(s.layout(s.appURL+"/unsubscribe/sample-token"), "Jane", goals, s.appURL+"/app/goals")
+	}},
+}
+
+// EmailPreviewNames returns the names of all previewable emails
+func (s *EmailService) EmailPreviewNames() []string {
+	names := make([]string, len(emailPreviewTemplates))
+	for i, template := range emailPreviewTemplates {
+		names[i] = template.name
+	}
+	return names
+}
+
+// EmailPreview renders an email with sample data
+func (s *EmailService) EmailPreview(name string) (*EmailPreview, error) {
+	for _, template := range emailPreviewTemplates {
+		if template.name != name {
+			continue
+		}
+
+		subject, content := template.render(s)
+		html, text, err := renderEmail(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s email: %w", name, err)
+		}
+
+		return &EmailPreview{
+			Name:    name,
+			Subject: subject,
+			HTML:    html,
+			Text:    text,
+		}, nil
+	}
+
+	return nil, ErrEmailPreviewNotFound
+}
@@ -1,102 +1,80 @@
 package service
 
-import "fmt"
+import (
+	"fmt"
 
-func forgotPasswordEmailTemplate(signInURL, appName string) (string, string) {
-	subject := fmt.Sprintf("Reset your password for %s", appName)
-	body := fmt.Sprintf(`You requested to reset your password. For security, we'll remove your password and sign you in with this link:
-%s
+	"github.com/a-h/templ"
+	"github.com/templui/goilerplate/internal/ui/emails"
+)
 
-After signing in, you can set a new password in Settings.
+// Email templates return the subject and the HTML content (internal/ui/emails)
+// The plain-text alternative is generated from the HTML when the email is rendered.
 
-This link expires in 10 minutes and can only be used once.
-
-If you didn't request this, you can safely ignore this email. Your password won't be changed.
-
-Best,
-The %s Team`, signInURL, appName)
-
-	return subject, body
+func forgotPasswordEmailTemplate(layout emails.LayoutProps, signInURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Reset your password for %s", layout.AppName)
+	layout.Preheader = "Sign in with this link to set a new password."
+	return subject, emails.ForgotPassword(layout, signInURL)
 }
 
-func magicLinkEmailTemplate(magicURL, appName string) (string, string) {
-	subject := fmt.Sprintf("Sign in to %s", appName)
-	body := fmt.Sprintf(`Click this link to sign in to your account:
-%s
-
-This link expires in 10 minutes and can only be used once.
-
-If you didn't request this, ignore this email.
-
-Best,
-The %s Team`, magicURL, appName)
-
-	return subject, body
+func magicLinkEmailTemplate(layout emails.LayoutProps, magicURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Sign in to %s", layout.AppName)
+	layout.Preheader = "Your sign-in link expires in 10 minutes."
+	return subject, emails.MagicLink(layout, magicURL)
 }
 
-func welcomeEmailTemplate(name, dashboardURL, appName string) (string, string) {
-	subject := fmt.Sprintf("Welcome to %s!", appName)
-	body := fmt.Sprintf(`Hi %s,
-
-Your email is verified and your account is active!
-
-Get started: %s
-
-If you have questions, reach out to our support team.
-
-Best,
-The %s Team`, name, dashboardURL, appName)
-
-	return subject, body
+func welcomeEmailTemplate(layout emails.LayoutProps, name, dashboardURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Welcome to %s!", layout.AppName)
+	layout.Preheader = "Your account is active, let's get started."
+	return subject, emails.Welcome(layout, name, dashboardURL)
 }
 
-func emailChangeVerificationTemplate(name, verifyURL, appName string) (string, string) {
-	subject := fmt.Sprintf("Verify your new email for %s", appName)
-	body := fmt.Sprintf(`Hi %s,
-
-You requested to change your email address. Please verify your new email by clicking this link:
-%s
-
-This link expires in 24 hours.
-
-If you didn't request this change, you can safely ignore this email.
-
-Best,
-The %s Team`, name, verifyURL, appName)
-
-	return subject, body
+func emailChangeVerificationTemplate(layout emails.LayoutProps, name, verifyURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Verify your new email for %s", layout.AppName)
+	layout.Preheader = "Confirm this address to finish changing your email."
+	return subject, emails.EmailChangeVerification(layout, name, verifyURL)
 }
 
-func emailChangeNotificationTemplate(name, newEmail, appName string) (string, string) {
-	subject := fmt.Sprintf("Email change requested for %s", appName)
-	body := fmt.Sprintf(`Hi %s,
-
-A request was made to change your email address to: %s
-
-If this was you, please verify the new email address by clicking the link we sent to it.
-
-If you didn't request this change, your account may be compromised. Please secure your account immediately by changing your password.
-
-Best,
-The %s Team`, name, newEmail, appName)
-
-	return subject, body
+func emailChangeNotificationTemplate(layout emails.LayoutProps, name, newEmail string) (string, templ.Component) {
+	subject := fmt.Sprintf("Email change requested for %s", layout.AppName)
+	layout.Preheader = fmt.Sprintf("A request was made to change your email to %s.", newEmail)
+	return subject, emails.EmailChangeNotification(layout, name, newEmail)
 }
 
-func accountDeletedEmailTemplate(name, appName string) (string, string) {
-	subject := fmt.Sprintf("Your %s account has been deleted", appName)
-	body := fmt.Sprintf(`Hi %s,
-
-Your account has been permanently deleted from %s.
+func newDeviceAlertEmailTemplate(layout emails.LayoutProps, name, device, ipAddress, signedInAt, secureURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("New sign-in to your %s account", layout.AppName)
+	layout.Preheader = fmt.Sprintf("%s signed in to your account.", device)
+	return subject, emails.NewDeviceAlert(layout, name, device, ipAddress, signedInAt, secureURL)
+}
 
-All your data, including your profile, files, and settings, has been removed from our systems.
+func accountLockedEmailTemplate(layout emails.LayoutProps, name, until, signInURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Password sign-in to your %s account was locked", layout.AppName)
+	layout.Preheader = "We noticed too many failed sign-in attempts."
+	return subject, emails.AccountLocked(layout, name, until, signInURL)
+}
 
-If you didn't request this deletion, please contact our support team immediately, though we won't be able to recover your account.
+func accountDeletedEmailTemplate(layout emails.LayoutProps, name string) (string, templ.Component) {
+	subject := fmt.Sprintf("Your %s account has been deleted", layout.AppName)
+	layout.Preheader = "All your data has been removed."
+	return subject, emails.AccountDeleted(layout, name)
+}
 
-We're sorry to see you go. If you change your mind, you're welcome to create a new account anytime.
+func goalReminderEmailTemplate(layout emails.LayoutProps, name string, goalTitles []string, inactiveDays int, goalsURL string) (string, templ.Component) {
+	subject := "Keep your goals going"
+	if len(goalTitles) == 1 {
+		subject = fmt.Sprintf("Keep going with \"%s\"", goalTitles[0])
+	}
+	layout.Preheader = "Small steps add up. Log your next step today."
+	return subject, emails.GoalReminder(layout, name, goalTitles, inactiveDays, goalsURL)
+}
 
-Best,
-The %s Team`, name, appName, appName)
+func weeklyDigestEmailTemplate(layout emails.LayoutProps, name string, goals []emails.GoalDigest, goalsURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("Your weekly progress on %s", layout.AppName)
+	layout.Preheader = "Here's what you achieved in the last 7 days."
+	return subject, emails.WeeklyDigest(layout, name, goals, goalsURL)
+}
 
-	return subject, body
+func workspaceInvitationEmailTemplate(layout emails.LayoutProps, inviterName, workspaceName, role, acceptURL string) (string, templ.Component) {
+	subject := fmt.Sprintf("%s invited you to %s on %s", inviterName, workspaceName, layout.AppName)
+	layout.Preheader = fmt.Sprintf("Join the %s workspace.", workspaceName)
+	return subject, emails.WorkspaceInvitation(layout, inviterName, workspaceName, role, acceptURL)
 }
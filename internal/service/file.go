@@ -86,23 +86,23 @@ func (s *FileService) URL(file *model.File) string {
 		return ""
 	}
 
-	// Type assert to check if S3 storage
-	s3Storage, ok := s.storage.(*storage.S3Storage)
+	// Type assert to check if storage issues expiring URLs (S3, local)
+	presigner, ok := s.storage.(storage.Presigner)
 	if ok {
 		if file.Public {
 			// Public files: presigned URL with long expiry (7 days)
-			return s3Storage.PublicURL(file.StoragePath)
+			return presigner.PublicURL(file.StoragePath)
 		}
 		// Private files: presigned URL with short expiry (1 hour)
-		url, err := s3Storage.PresignedURL(file.StoragePath, s3Storage.GetPresignExpiryPrivate())
+		url, err := presigner.PresignedURL(file.StoragePath, presigner.GetPresignExpiryPrivate())
 		if err != nil {
 			// Fallback to public URL if presigning fails
-			return s3Storage.PublicURL(file.StoragePath)
+			return presigner.PublicURL(file.StoragePath)
 		}
 		return url
 	}
 
-	// Local storage or other: use default URL method
+	// Other storage: use default URL method
 	return s.storage.URL(file.StoragePath)
 }
 
@@ -129,6 +129,27 @@ func (s *FileService) Delete(fileID string) error {
 	return nil
 }
 
+// DeleteFiles removes files from storage and database
+// Best effort: failures are logged so the rest are still removed.
+func (s *FileService) DeleteFiles(files []*model.File) {
+	for _, file := range files {
+		err := s.storage.Delete(file.StoragePath)
+		if err != nil {
+			slog.Warn("failed to delete file from storage", "storage_path", file.StoragePath, "error", err)
+		}
+
+		err = s.fileRepo.Delete(file.ID)
+		if err != nil {
+			slog.Error("failed to delete file record", "file_id", file.ID, "error", err)
+		}
+	}
+}
+
+// OrganizationAttachments retrieves all files attached to goal entries of a workspace
+func (s *FileService) OrganizationAttachments(organizationID string) ([]*model.File, error) {
+	return s.fileRepo.OrganizationAttachments(organizationID)
+}
+
 // DeleteUserAvatar deletes the user's avatar
 func (s *FileService) DeleteUserAvatar(userID string) error {
 	file, err := s.Avatar("user", userID)
@@ -147,19 +168,13 @@ func (s *FileService) AllUserFiles(userID string) ([]*model.File, error) {
 	return s.fileRepo.AllUserFiles(userID)
 }
 
-func (s *FileService) DeleteAllUserFilesFromStorage(userID string) error {
-	files, err := s.fileRepo.AllUserFiles(userID)
-	if err != nil {
-		return fmt.Errorf("failed to get user files: %w", err)
-	}
-
+// DeleteFilesFromStorage removes files from storage only, their records are already gone
+func (s *FileService) DeleteFilesFromStorage(files []*model.File) {
 	for _, file := range files {
-		err = s.storage.Delete(file.StoragePath)
+		err := s.storage.Delete(file.StoragePath)
 		if err != nil {
 			// Log but continue - physical file may already be gone
 			slog.Warn("failed to delete file from storage", "storage_path", file.StoragePath, "error", err)
 		}
 	}
-
-	return nil
 }
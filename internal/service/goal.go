@@ -15,90 +15,119 @@ var (
 	ErrGoalLimitReached     = errors.New("free plan goal limit reached")
 	ErrInvalidStep          = errors.New("invalid step: must complete previous steps first")
 	ErrGoalAlreadyCompleted = errors.New("goal already completed")
+	ErrInvalidGoalSteps     = fmt.Errorf("number of steps must be between 1 and %d", model.MaxGoalSteps)
+	ErrInvalidGoalCadence   = errors.New("invalid cadence")
+	ErrEntryNotCompleted    = errors.New("cannot update incomplete entry")
+	ErrNotLastStep          = errors.New("can only uncomplete the last completed step")
 )
 
+// GoalService manages the goals of a workspace
+// Goals are scoped by organization, userID is the acting member: the creator
+// of new goals and the user whose webhook endpoints receive the events.
 type GoalService struct {
 	repo                repository.GoalRepository
 	entryRepo           repository.GoalEntryRepository
 	fileRepo            repository.FileRepository
+	fileService         *FileService
+	txManager           repository.TxManager
 	subscriptionService *SubscriptionService
+	webhookService      *WebhookService
 }
 
 func NewGoalService(
 	repo repository.GoalRepository,
 	entryRepo repository.GoalEntryRepository,
 	fileRepo repository.FileRepository,
+	fileService *FileService,
+	txManager repository.TxManager,
 	subscriptionService *SubscriptionService,
+	webhookService *WebhookService,
 ) *GoalService {
 	return &GoalService{
 		repo:                repo,
 		entryRepo:           entryRepo,
 		fileRepo:            fileRepo,
+		fileService:         fileService,
+		txManager:           txManager,
 		subscriptionService: subscriptionService,
+		webhookService:      webhookService,
 	}
 }
 
-func (s *GoalService) Create(userID, title, description string) (*model.Goal, error) {
-	subscription, err := s.subscriptionService.Subscription(userID)
-	if err != nil {
-		return nil, err
+func (s *GoalService) Create(organizationID, userID, title, description string, targetSteps int, cadence string) (*model.Goal, error) {
+	if targetSteps < 1 || targetSteps > model.MaxGoalSteps {
+		return nil, ErrInvalidGoalSteps
 	}
 
-	// Check goal limit based on plan
-	limit := subscription.GetGoalLimit()
-	if limit != -1 { // -1 means unlimited
-		count, err := s.repo.CountUserGoals(userID)
-		if err != nil {
-			return nil, err
-		}
+	if !validCadence(cadence) {
+		return nil, ErrInvalidGoalCadence
+	}
 
-		if count >= limit {
-			return nil, ErrGoalLimitReached
-		}
+	subscription, err := s.subscriptionService.Subscription(organizationID)
+	if err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 	goal := &model.Goal{
-		ID:          uuid.New().String(),
-		UserID:      userID,
-		Title:       title,
-		Description: description,
-		Status:      model.GoalStatusActive,
-		CurrentStep: 0,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:             uuid.New().String(),
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Title:          title,
+		Description:    description,
+		Status:         model.GoalStatusActive,
+		CurrentStep:    0,
+		TargetSteps:    targetSteps,
+		Cadence:        cadence,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
-	err = s.repo.Create(goal)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create goal: %w", err)
-	}
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Check goal limit based on plan
+		limit := subscription.GetGoalLimit()
+		if limit != -1 { // -1 means unlimited
+			count, err := tx.Goals.CountActiveGoals(organizationID)
+			if err != nil {
+				return err
+			}
+
+			if count >= limit {
+				return ErrGoalLimitReached
+			}
+		}
 
-	// Create 100 entries for the goal
-	err = s.entryRepo.CreateEntries(goal.ID, 100)
-	if err != nil {
-		// Rollback: delete the goal if entries creation fails
-		delErr := s.repo.Delete(userID, goal.ID)
-		if delErr != nil {
-			slog.Error("failed to delete goal during rollback", "error", delErr, "goalID", goal.ID)
+		err := tx.Goals.Create(goal)
+		if err != nil {
+			return fmt.Errorf("failed to create goal: %w", err)
+		}
+
+		// Create one entry per step
+		err = tx.GoalEntries.CreateEntries(goal.ID, goal.TargetSteps)
+		if err != nil {
+			return fmt.Errorf("failed to create goal entries: %w", err)
 		}
-		return nil, fmt.Errorf("failed to create goal entries: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.webhookService.Publish(userID, model.WebhookEventGoalCreated, newWebhookGoal(goal))
 	return goal, nil
 }
 
-func (s *GoalService) ByID(userID, goalID string) (*model.Goal, error) {
-	return s.repo.ByID(userID, goalID)
+func (s *GoalService) ByID(organizationID, goalID string) (*model.Goal, error) {
+	return s.repo.ByID(organizationID, goalID)
 }
 
-func (s *GoalService) Goals(userID, sortBy string) ([]*model.Goal, error) {
-	return s.repo.Goals(userID, sortBy)
+func (s *GoalService) Goals(organizationID, sortBy string) ([]*model.Goal, error) {
+	return s.repo.Goals(organizationID, sortBy)
 }
 
-func (s *GoalService) GoalWithEntries(userID, goalID string) (*model.Goal, []*model.GoalEntry, error) {
+func (s *GoalService) GoalWithEntries(organizationID, goalID string) (*model.Goal, []*model.GoalEntry, error) {
 	// Verify ownership
-	goal, err := s.repo.ByID(userID, goalID)
+	goal, err := s.repo.ByID(organizationID, goalID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -111,13 +140,17 @@ func (s *GoalService) GoalWithEntries(userID, goalID string) (*model.Goal, []*mo
 	return goal, entries, nil
 }
 
-func (s *GoalService) CountUserGoals(userID string) (int, error) {
-	return s.repo.CountUserGoals(userID)
+func (s *GoalService) CountActiveGoals(organizationID string) (int, error) {
+	return s.repo.CountActiveGoals(organizationID)
 }
 
-func (s *GoalService) Update(userID, goalID, title, description, status string) error {
+func (s *GoalService) Update(organizationID, goalID, title, description, status, cadence string) error {
+	if !validCadence(cadence) {
+		return ErrInvalidGoalCadence
+	}
+
 	// Verify ownership
-	goal, err := s.repo.ByID(userID, goalID)
+	goal, err := s.repo.ByID(organizationID, goalID)
 	if err != nil {
 		return err
 	}
@@ -125,58 +158,100 @@ func (s *GoalService) Update(userID, goalID, title, description, status string)
 	goal.Title = title
 	goal.Description = description
 	goal.Status = status
+	goal.Cadence = cadence
 	goal.UpdatedAt = time.Now()
 
 	return s.repo.Update(goal)
 }
 
-func (s *GoalService) CompleteEntry(userID, goalID string, step int) error {
-	// Verify ownership
-	goal, err := s.repo.ByID(userID, goalID)
-	if err != nil {
-		return err
-	}
+// CompleteEntry completes the next step, entry and goal are updated in one transaction
+// A concurrent request that already moved the goal gets ErrInvalidStep.
+func (s *GoalService) CompleteEntry(organizationID, userID, goalID string, step int) error {
+	var goal *model.Goal
+	err := s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Verify ownership
+		var err error
+		goal, err = tx.Goals.ByID(organizationID, goalID)
+		if err != nil {
+			return err
+		}
 
-	if goal.Status == model.GoalStatusCompleted {
-		return ErrGoalAlreadyCompleted
-	}
+		if goal.Status == model.GoalStatusCompleted {
+			return ErrGoalAlreadyCompleted
+		}
 
-	if step != goal.CurrentStep+1 {
-		return ErrInvalidStep
-	}
+		if step != goal.CurrentStep+1 {
+			return ErrInvalidStep
+		}
+
+		err = tx.GoalEntries.CompleteEntry(goalID, step)
+		if errors.Is(err, repository.ErrGoalEntryNotFound) {
+			return ErrInvalidStep
+		}
+		if err != nil {
+			return err
+		}
 
-	err = s.entryRepo.CompleteEntry(goalID, step)
+		goal.CurrentStep = step
+
+		if step == goal.TargetSteps {
+			goal.Status = model.GoalStatusCompleted
+		}
+
+		goal.UpdatedAt = time.Now()
+		err = tx.Goals.UpdateProgress(goal, step-1)
+		if errors.Is(err, repository.ErrGoalStepConflict) {
+			return ErrInvalidStep
+		}
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	goal.CurrentStep = step
-
-	if step == 100 {
-		goal.Status = model.GoalStatusCompleted
+	s.webhookService.Publish(userID, model.WebhookEventGoalEntryCompleted, webhookGoalEntry{Goal: newWebhookGoal(goal), Step: step})
+	if goal.Status == model.GoalStatusCompleted {
+		s.webhookService.Publish(userID, model.WebhookEventGoalCompleted, newWebhookGoal(goal))
 	}
-
-	goal.UpdatedAt = time.Now()
-	return s.repo.Update(goal)
+	return nil
 }
 
-func (s *GoalService) Delete(userID, goalID string) error {
-	// Verify ownership
-	_, err := s.repo.ByID(userID, goalID)
+// Delete deletes a goal with its entries and their attachments
+// Files leave storage after the goal is gone, like in UncompleteEntry.
+func (s *GoalService) Delete(organizationID, goalID string) error {
+	var attachments []*model.File
+	err := s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Verify ownership
+		_, err := tx.Goals.ByID(organizationID, goalID)
+		if err != nil {
+			return err
+		}
+
+		// Collected first, the entries they point to cascade with the goal
+		attachments, err = tx.Files.GoalAttachments(goalID)
+		if err != nil {
+			return fmt.Errorf("failed to get attachments: %w", err)
+		}
+
+		return tx.Goals.Delete(organizationID, goalID)
+	})
 	if err != nil {
 		return err
 	}
 
-	return s.repo.Delete(userID, goalID)
+	s.fileService.DeleteFiles(attachments)
+	return nil
 }
 
 func (s *GoalService) EntryByGoalAndStep(goalID string, step int) (*model.GoalEntry, error) {
 	return s.entryRepo.Entry(goalID, step)
 }
 
-func (s *GoalService) UpdateEntry(userID, goalID string, step int, note string, completedAt *time.Time) error {
+// UpdateEntry changes the note and date of a completed step
+// noteShared shows the note on the goal's share page.
+func (s *GoalService) UpdateEntry(organizationID, goalID string, step int, note string, noteShared bool, completedAt *time.Time) error {
 	// Verify ownership
-	_, err := s.repo.ByID(userID, goalID)
+	_, err := s.repo.ByID(organizationID, goalID)
 	if err != nil {
 		return err
 	}
@@ -187,34 +262,76 @@ func (s *GoalService) UpdateEntry(userID, goalID string, step int, note string,
 	}
 
 	if !entry.Completed {
-		return errors.New("cannot update incomplete entry")
+		return ErrEntryNotCompleted
 	}
 
-	return s.entryRepo.UpdateEntry(goalID, step, note, completedAt)
+	return s.entryRepo.UpdateEntry(goalID, step, note, noteShared, completedAt)
 }
 
-func (s *GoalService) UncompleteEntry(userID, goalID string, step int) error {
-	// Verify ownership
-	goal, err := s.repo.ByID(userID, goalID)
-	if err != nil {
-		return err
-	}
+// UncompleteEntry reopens the last completed step, in one transaction like CompleteEntry
+// Attachments are proof of a completed step, they are deleted with the completion.
+func (s *GoalService) UncompleteEntry(organizationID, goalID string, step int) error {
+	var entryID string
+	err := s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Verify ownership
+		goal, err := tx.Goals.ByID(organizationID, goalID)
+		if err != nil {
+			return err
+		}
 
-	if step != goal.CurrentStep {
-		return errors.New("can only uncomplete the last completed step")
-	}
+		if step != goal.CurrentStep {
+			return ErrNotLastStep
+		}
 
-	err = s.entryRepo.UncompleteEntry(goalID, step)
+		entry, err := tx.GoalEntries.Entry(goalID, step)
+		if errors.Is(err, repository.ErrGoalEntryNotFound) {
+			return ErrNotLastStep
+		}
+		if err != nil {
+			return err
+		}
+		entryID = entry.ID
+
+		err = tx.GoalEntries.UncompleteEntry(goalID, step)
+		if errors.Is(err, repository.ErrGoalEntryNotFound) {
+			return ErrNotLastStep
+		}
+		if err != nil {
+			return err
+		}
+
+		goal.CurrentStep = step - 1
+
+		if goal.Status == model.GoalStatusCompleted {
+			goal.Status = model.GoalStatusActive
+		}
+
+		goal.UpdatedAt = time.Now()
+		err = tx.Goals.UpdateProgress(goal, step)
+		if errors.Is(err, repository.ErrGoalStepConflict) {
+			return ErrNotLastStep
+		}
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	goal.CurrentStep = step - 1
-
-	if goal.Status == model.GoalStatusCompleted {
-		goal.Status = model.GoalStatusActive
+	// The step is reopened either way, leftover files only cost storage
+	attachments, err := s.fileRepo.Files(model.FileOwnerGoalEntry, entryID)
+	if err != nil {
+		slog.Error("failed to get attachments of uncompleted entry", "goal_id", goalID, "step", step, "error", err)
+		return nil
 	}
+	s.fileService.DeleteFiles(attachments)
+	return nil
+}
 
-	goal.UpdatedAt = time.Now()
-	return s.repo.Update(goal)
+func validCadence(cadence string) bool {
+	switch cadence {
+	case model.GoalCadenceNone, model.GoalCadenceDaily, model.GoalCadenceWeekly:
+		return true
+	default:
+		return false
+	}
 }
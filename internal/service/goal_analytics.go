@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	// AnalyticsWeeks is how many weeks the weekly chart and average cover
+	AnalyticsWeeks = 12
+	// AnalyticsHeatmapWeeks is how many weeks the heatmap covers, including the current one
+	AnalyticsHeatmapWeeks = 53
+)
+
+// GoalAnalyticsService computes streaks, pace and the completion heatmap of a workspace
+// Everything is derived from GoalEntry.CompletedAt in the user's timezone.
+type GoalAnalyticsService struct {
+	goalRepository      repository.GoalRepository
+	goalEntryRepository repository.GoalEntryRepository
+}
+
+func NewGoalAnalyticsService(goalRepository repository.GoalRepository, goalEntryRepository repository.GoalEntryRepository) *GoalAnalyticsService {
+	return &GoalAnalyticsService{
+		goalRepository:      goalRepository,
+		goalEntryRepository: goalEntryRepository,
+	}
+}
+
+// Analytics returns the analytics of all goals in the workspace as of now
+func (s *GoalAnalyticsService) Analytics(organizationID string, now time.Time, loc *time.Location) (*model.GoalAnalytics, error) {
+	goals, err := s.goalRepository.Goals(organizationID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	entries, err := s.goalEntryRepository.CompletedEntries(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completed entries: %w", err)
+	}
+
+	entriesByGoal := make(map[string][]*model.GoalEntry, len(goals))
+	for _, entry := range entries {
+		entriesByGoal[entry.GoalID] = append(entriesByGoal[entry.GoalID], entry)
+	}
+
+	analytics := &model.GoalAnalytics{
+		GeneratedAt:    now,
+		Timezone:       loc.String(),
+		CompletedSteps: len(entries),
+		Weeks:          []*model.WeekCount{},
+		Heatmap:        []*model.HeatmapDay{},
+		Goals:          []*model.GoalAnalyticsGoal{},
+	}
+
+	for _, goal := range goals {
+		analytics.Goals = append(analytics.Goals, &model.GoalAnalyticsGoal{
+			ID:          goal.ID,
+			Title:       goal.Title,
+			Status:      goal.Status,
+			CurrentStep: goal.CurrentStep,
+			TargetSteps: goal.TargetSteps,
+			GoalStats:   goal.Stats(entriesByGoal[goal.ID], now, loc),
+		})
+	}
+
+	// Completions per calendar day, keyed by PeriodIndex
+	days := make(map[int]int)
+	periods := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		day := model.PeriodIndex(*entry.CompletedAt, loc, false)
+		days[day]++
+		periods = append(periods, day)
+	}
+	today := model.PeriodIndex(now, loc, false)
+	analytics.CurrentStreak, analytics.LongestStreak = model.Streaks(periods, today)
+
+	// Heatmap starts on the Monday AnalyticsHeatmapWeeks-1 weeks before this week's
+	monday := startOfWeek(now, loc)
+	start := monday.AddDate(0, 0, -7*(AnalyticsHeatmapWeeks-1))
+	for date := start; !date.After(now); date = date.AddDate(0, 0, 1) {
+		analytics.Heatmap = append(analytics.Heatmap, &model.HeatmapDay{
+			Date:  date,
+			Count: days[model.PeriodIndex(date, loc, false)],
+		})
+	}
+
+	total := 0
+	for week := AnalyticsWeeks - 1; week >= 0; week-- {
+		weekStart := monday.AddDate(0, 0, -7*week)
+		count := 0
+		for day := range 7 {
+			count += days[model.PeriodIndex(weekStart.AddDate(0, 0, day), loc, false)]
+		}
+		total += count
+		analytics.Weeks = append(analytics.Weeks, &model.WeekCount{Start: weekStart, Count: count})
+	}
+	analytics.StepsPerWeek = float64(total) / AnalyticsWeeks
+
+	return analytics, nil
+}
+
+// startOfWeek returns midnight of the Monday of t's week in loc
+func startOfWeek(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+}
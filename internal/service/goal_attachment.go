@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+var (
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrTooManyAttachments   = fmt.Errorf("a step can have at most %d attachments", model.MaxEntryAttachments)
+	ErrStorageQuotaExceeded = errors.New("the storage limit of your plan is reached")
+)
+
+// Attachments returns the files attached to a completed step with URLs to show them
+func (s *GoalService) Attachments(organizationID, goalID string, step int) ([]*model.Attachment, error) {
+	entry, err := s.completedEntry(organizationID, goalID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.fileRepo.Files(model.FileOwnerGoalEntry, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	attachments := make([]*model.Attachment, 0, len(files))
+	for _, file := range files {
+		attachments = append(attachments, &model.Attachment{File: file, URL: s.fileService.URL(file)})
+	}
+	return attachments, nil
+}
+
+// AddAttachment stores a file as proof of a completed step
+// Attachments are private, the caller validates type and size of the file.
+func (s *GoalService) AddAttachment(organizationID, userID, goalID string, step int, file multipart.File, header *multipart.FileHeader) (*model.File, error) {
+	entry, err := s.completedEntry(organizationID, goalID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.fileRepo.Files(model.FileOwnerGoalEntry, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	if len(files) >= model.MaxEntryAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	usage, err := s.StorageUsage(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if usage.Exceeds(header.Size) {
+		return nil, ErrStorageQuotaExceeded
+	}
+
+	return s.fileService.Upload(userID, model.FileOwnerGoalEntry, entry.ID, model.FileTypeAttachment, file, header, false)
+}
+
+// DeleteAttachment removes a file from a completed step
+func (s *GoalService) DeleteAttachment(organizationID, goalID string, step int, fileID string) error {
+	entry, err := s.completedEntry(organizationID, goalID, step)
+	if err != nil {
+		return err
+	}
+
+	file, err := s.fileRepo.ByID(fileID)
+	if errors.Is(err, repository.ErrFileNotFound) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	// The file must belong to this entry, IDs from the request are not trusted
+	if file.OwnerType != model.FileOwnerGoalEntry || file.OwnerID != entry.ID {
+		return ErrAttachmentNotFound
+	}
+
+	return s.fileService.Delete(file.ID)
+}
+
+// StorageUsage returns the attachment storage used by a workspace and its plan's limit
+func (s *GoalService) StorageUsage(organizationID string) (model.StorageUsage, error) {
+	subscription, err := s.subscriptionService.Subscription(organizationID)
+	if err != nil {
+		return model.StorageUsage{}, err
+	}
+
+	used, err := s.fileRepo.OrganizationAttachmentsSize(organizationID)
+	if err != nil {
+		return model.StorageUsage{}, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return model.StorageUsage{Used: used, Limit: subscription.GetStorageLimit()}, nil
+}
+
+// completedEntry returns the entry of a step after checking the goal belongs to the workspace
+// Only completed steps have attachments.
+func (s *GoalService) completedEntry(organizationID, goalID string, step int) (*model.GoalEntry, error) {
+	_, err := s.repo.ByID(organizationID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.entryRepo.Entry(goalID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.Completed {
+		return nil, ErrEntryNotCompleted
+	}
+	return entry, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+// shareSlugBytes of randomness make share links unguessable, 22 characters encoded
+const shareSlugBytes = 16
+
+// Share makes a goal readable at its share page, a shared goal keeps its slug
+func (s *GoalService) Share(organizationID, goalID string) (*model.Goal, error) {
+	goal, err := s.repo.ByID(organizationID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.IsShared() {
+		return goal, nil
+	}
+
+	slug, err := newShareSlug()
+	if err != nil {
+		return nil, err
+	}
+	goal.ShareSlug = &slug
+	goal.ShareIndexable = false
+
+	err = s.repo.UpdateShare(goal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to share goal: %w", err)
+	}
+
+	slog.Info("goal shared", "organization_id", organizationID, "goal_id", goalID)
+	return goal, nil
+}
+
+// Unshare revokes the share link, sharing again creates a new one
+func (s *GoalService) Unshare(organizationID, goalID string) (*model.Goal, error) {
+	goal, err := s.repo.ByID(organizationID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if !goal.IsShared() {
+		return goal, nil
+	}
+
+	goal.ShareSlug = nil
+	goal.ShareIndexable = false
+
+	err = s.repo.UpdateShare(goal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	slog.Info("goal share link revoked", "organization_id", organizationID, "goal_id", goalID)
+	return goal, nil
+}
+
+// SetShareIndexable lets search engines index a shared goal, share pages are noindex by default
+func (s *GoalService) SetShareIndexable(organizationID, goalID string, indexable bool) (*model.Goal, error) {
+	goal, err := s.repo.ByID(organizationID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if !goal.IsShared() {
+		return goal, nil
+	}
+
+	goal.ShareIndexable = indexable
+	err = s.repo.UpdateShare(goal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update share settings: %w", err)
+	}
+	return goal, nil
+}
+
+// SharedGoal returns a shared goal by its slug with the completed steps whose note was shared
+// Notes of other steps never leave the service.
+func (s *GoalService) SharedGoal(slug string) (*model.Goal, []*model.GoalEntry, error) {
+	goal, err := s.repo.BySlug(slug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := s.entryRepo.Entries(goal.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+
+	var notes []*model.GoalEntry
+	for _, entry := range entries {
+		if entry.Completed && entry.NoteShared && entry.Note != "" {
+			notes = append(notes, entry)
+		}
+	}
+
+	// Latest progress first
+	slices.Reverse(notes)
+
+	return goal, notes, nil
+}
+
+func newShareSlug() (string, error) {
+	bytes := make([]byte, shareSlugBytes)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate share slug: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
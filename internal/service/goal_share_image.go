@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+// Open Graph images are shown at 1.91:1, 1200x630 is what most platforms recommend
+const (
+	ShareImageWidth  = 1200
+	ShareImageHeight = 630
+)
+
+var (
+	shareImageBackground = color.RGBA{0x09, 0x09, 0x0b, 0xff}
+	shareImageText       = color.RGBA{0xfa, 0xfa, 0xfa, 0xff}
+	shareImageMuted      = color.RGBA{0xa1, 0xa1, 0xaa, 0xff}
+	shareImageTrack      = color.RGBA{0x27, 0x27, 0x2a, 0xff}
+	shareImageFill       = color.RGBA{0x10, 0xb9, 0x81, 0xff}
+)
+
+// shareImageGlyphs is a 5x7 pixel font for the numbers on the image
+// The title is left to og:title, there is no font renderer in the standard library.
+var shareImageGlyphs = map[rune][7]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'%': {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	'/': {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+}
+
+// ShareImage renders the Open Graph image of a shared goal as PNG
+// It shows the percentage, the steps done and a progress bar.
+func ShareImage(goal *model.Goal) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, ShareImageWidth, ShareImageHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{shareImageBackground}, image.Point{}, draw.Src)
+
+	percent := 0
+	if goal.TargetSteps > 0 {
+		percent = goal.CurrentStep * 100 / goal.TargetSteps
+	}
+
+	drawShareText(img, fmt.Sprintf("%d%%", percent), 110, 24, shareImageText)
+	drawShareText(img, fmt.Sprintf("%d/%d", goal.CurrentStep, goal.TargetSteps), 320, 8, shareImageMuted)
+
+	track := image.Rect(120, 440, ShareImageWidth-120, 496)
+	fillPill(img, track, shareImageTrack)
+	if goal.CurrentStep > 0 {
+		width := track.Dx() * goal.CurrentStep / max(goal.TargetSteps, 1)
+		// A sliver would not show the rounded ends
+		fill := image.Rect(track.Min.X, track.Min.Y, track.Min.X+max(width, track.Dy()), track.Max.Y)
+		fillPill(img, fill, shareImageFill)
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode share image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawShareText draws text horizontally centered at y, each font pixel scale pixels wide
+func drawShareText(img *image.RGBA, text string, y, scale int, c color.Color) {
+	advance := 6 * scale // 5 pixels and a gap
+	width := len(text)*advance - scale
+	x := (img.Bounds().Dx() - width) / 2
+
+	for _, r := range text {
+		glyph, ok := shareImageGlyphs[r]
+		if ok {
+			for row, line := range glyph {
+				for col, pixel := range line {
+					if pixel != '#' {
+						continue
+					}
+					rect := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+					draw.Draw(img, rect, &image.Uniform{c}, image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += advance
+	}
+}
+
+// fillPill fills a rectangle with fully rounded ends
+func fillPill(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	radius := rect.Dy() / 2
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cx := min(max(x, rect.Min.X+radius), rect.Max.X-radius-1)
+			cy := rect.Min.Y + radius
+			dx, dy := x-cx, y-cy
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	// goalImportMaxGoals caps a single import, far above any plan limit
+	goalImportMaxGoals = 1000
+
+	goalImportTitleExists  = "a goal with this title already exists"
+	goalImportLimitReached = "the goal limit of your plan is reached"
+)
+
+var (
+	ErrInvalidGoalExport    = errors.New("this file is not a valid goal export")
+	ErrGoalExportVersion    = fmt.Errorf("unsupported export version, this app reads version %d", model.GoalExportVersion)
+	ErrGoalExportEmpty      = errors.New("the file doesn't contain any goals")
+	ErrGoalExportTooLarge   = fmt.Errorf("a file can contain at most %d goals", goalImportMaxGoals)
+	errGoalImportTitle      = errors.New("title is missing")
+	errGoalImportStep       = errors.New("entries reference steps outside the goal")
+	errGoalImportDuplicate  = errors.New("entries contain the same step twice")
+	errGoalImportNotInOrder = errors.New("completed steps must follow each other from step 1")
+)
+
+// ExportGoals returns all goals of the workspace with their entries
+func (s *GoalService) ExportGoals(organizationID string) (*model.GoalExport, error) {
+	goals, err := s.repo.Goals(organizationID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	export := &model.GoalExport{
+		Version:    model.GoalExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Goals:      make([]*model.ExportedGoal, 0, len(goals)),
+	}
+
+	for _, goal := range goals {
+		entries, err := s.entryRepo.Entries(goal.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entries: %w", err)
+		}
+
+		exported := &model.ExportedGoal{
+			ID:          goal.ID,
+			Title:       goal.Title,
+			Description: goal.Description,
+			Status:      goal.Status,
+			Cadence:     goal.Cadence,
+			CurrentStep: goal.CurrentStep,
+			TargetSteps: goal.TargetSteps,
+			CreatedAt:   goal.CreatedAt.UTC(),
+			Entries:     make([]*model.ExportedGoalEntry, 0, len(entries)),
+		}
+		for _, entry := range entries {
+			exported.Entries = append(exported.Entries, &model.ExportedGoalEntry{
+				Step:        entry.Step,
+				Completed:   entry.Completed,
+				Note:        entry.Note,
+				CompletedAt: entry.CompletedAt,
+			})
+		}
+		export.Goals = append(export.Goals, exported)
+	}
+
+	return export, nil
+}
+
+// ParseGoalExport reads and checks the envelope of an export file
+// The goals themselves are validated one by one in ImportGoals.
+func ParseGoalExport(r io.Reader) (*model.GoalExport, error) {
+	export := &model.GoalExport{}
+	err := json.NewDecoder(r).Decode(export)
+	if err != nil {
+		return nil, ErrInvalidGoalExport
+	}
+
+	if export.Version != model.GoalExportVersion {
+		return nil, ErrGoalExportVersion
+	}
+	if len(export.Goals) == 0 {
+		return nil, ErrGoalExportEmpty
+	}
+	if len(export.Goals) > goalImportMaxGoals {
+		return nil, ErrGoalExportTooLarge
+	}
+
+	return export, nil
+}
+
+// ImportGoals creates the goals of an export in the workspace and reports each one
+// Invalid goals, goals whose title already exists and goals over the plan's
+// limit are skipped, all others are created with their entries.
+func (s *GoalService) ImportGoals(organizationID, userID string, export *model.GoalExport) ([]*model.GoalImportResult, error) {
+	subscription, err := s.subscriptionService.Subscription(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	limit := subscription.GetGoalLimit()
+
+	existing, err := s.repo.Goals(organizationID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+	titles := make(map[string]bool, len(existing))
+	for _, goal := range existing {
+		titles[strings.ToLower(goal.Title)] = true
+	}
+
+	results := make([]*model.GoalImportResult, 0, len(export.Goals))
+	for _, exported := range export.Goals {
+		result := &model.GoalImportResult{Title: strings.TrimSpace(exported.Title)}
+		results = append(results, result)
+
+		goal, entries, err := importedGoal(organizationID, userID, exported)
+		if err != nil {
+			result.Status = model.GoalImportInvalid
+			result.Reason = err.Error()
+			continue
+		}
+
+		if titles[strings.ToLower(goal.Title)] {
+			result.Status = model.GoalImportSkipped
+			result.Reason = goalImportTitleExists
+			continue
+		}
+
+		err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+			if limit != -1 && goal.Status == model.GoalStatusActive {
+				count, err := tx.Goals.CountActiveGoals(organizationID)
+				if err != nil {
+					return err
+				}
+				if count >= limit {
+					return ErrGoalLimitReached
+				}
+			}
+
+			err := tx.Goals.Create(goal)
+			if err != nil {
+				return fmt.Errorf("failed to create goal: %w", err)
+			}
+
+			err = tx.GoalEntries.InsertEntries(entries)
+			if err != nil {
+				return fmt.Errorf("failed to create goal entries: %w", err)
+			}
+			return nil
+		})
+		if errors.Is(err, ErrGoalLimitReached) {
+			result.Status = model.GoalImportSkipped
+			result.Reason = goalImportLimitReached
+			continue
+		}
+		if err != nil {
+			return results, err
+		}
+
+		titles[strings.ToLower(goal.Title)] = true
+		result.Status = model.GoalImportImported
+		result.GoalID = goal.ID
+		s.webhookService.Publish(userID, model.WebhookEventGoalCreated, newWebhookGoal(goal))
+	}
+
+	return results, nil
+}
+
+// importedGoal validates an exported goal and turns it into a new goal with entries
+// Progress is derived from the entries, the exported current step and status are ignored.
+func importedGoal(organizationID, userID string, exported *model.ExportedGoal) (*model.Goal, []*model.GoalEntry, error) {
+	title := strings.TrimSpace(exported.Title)
+	if title == "" {
+		return nil, nil, errGoalImportTitle
+	}
+
+	if exported.TargetSteps < 1 || exported.TargetSteps > model.MaxGoalSteps {
+		return nil, nil, ErrInvalidGoalSteps
+	}
+
+	cadence := exported.Cadence
+	if cadence == "" {
+		cadence = model.GoalCadenceNone
+	}
+	if !validCadence(cadence) {
+		return nil, nil, ErrInvalidGoalCadence
+	}
+
+	now := time.Now()
+	createdAt := exported.CreatedAt
+	if createdAt.IsZero() || createdAt.After(now) {
+		createdAt = now
+	}
+
+	goal := &model.Goal{
+		ID:             uuid.New().String(),
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Title:          title,
+		Description:    exported.Description,
+		Status:         model.GoalStatusActive,
+		TargetSteps:    exported.TargetSteps,
+		Cadence:        cadence,
+		CreatedAt:      createdAt,
+		UpdatedAt:      now,
+	}
+
+	entries := make([]*model.GoalEntry, goal.TargetSteps)
+	for i := range entries {
+		entries[i] = &model.GoalEntry{
+			ID:        uuid.New().String(),
+			GoalID:    goal.ID,
+			Step:      i + 1,
+			CreatedAt: createdAt,
+		}
+	}
+
+	seen := make(map[int]bool, len(exported.Entries))
+	for _, exportedEntry := range exported.Entries {
+		if exportedEntry.Step < 1 || exportedEntry.Step > goal.TargetSteps {
+			return nil, nil, errGoalImportStep
+		}
+		if seen[exportedEntry.Step] {
+			return nil, nil, errGoalImportDuplicate
+		}
+		seen[exportedEntry.Step] = true
+
+		// Notes only exist on completed steps, like in the app
+		if !exportedEntry.Completed {
+			continue
+		}
+		entry := entries[exportedEntry.Step-1]
+		entry.Completed = true
+		entry.Note = exportedEntry.Note
+		entry.CompletedAt = exportedEntry.CompletedAt
+	}
+
+	for _, entry := range entries {
+		if !entry.Completed {
+			break
+		}
+		goal.CurrentStep = entry.Step
+	}
+	for _, entry := range entries[goal.CurrentStep:] {
+		if entry.Completed {
+			return nil, nil, errGoalImportNotInOrder
+		}
+	}
+
+	if goal.CurrentStep == goal.TargetSteps {
+		goal.Status = model.GoalStatusCompleted
+	}
+
+	return goal, entries, nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embed timezone data so user timezones work in minimal containers
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/ui/emails"
+)
+
+const (
+	NotificationReminders = "reminders"
+	NotificationDigest    = "digest"
+)
+
+var (
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe link")
+)
+
+// NotificationService sends goal reminders and weekly digests
+// Called periodically by the scheduler, each run sends whatever is due
+// according to the user's timezone and preferences on their profile.
+// Both cover the goals a user created, in every workspace they belong to.
+type NotificationService struct {
+	profileRepository   repository.ProfileRepository
+	userRepository      repository.UserRepository
+	goalRepository      repository.GoalRepository
+	goalEntryRepository repository.GoalEntryRepository
+	emailService        *EmailService
+	signingKey          []byte
+}
+
+func NewNotificationService(
+	profileRepository repository.ProfileRepository,
+	userRepository repository.UserRepository,
+	goalRepository repository.GoalRepository,
+	goalEntryRepository repository.GoalEntryRepository,
+	emailService *EmailService,
+	signingKey string,
+) *NotificationService {
+	return &NotificationService{
+		profileRepository:   profileRepository,
+		userRepository:      userRepository,
+		goalRepository:      goalRepository,
+		goalEntryRepository: goalEntryRepository,
+		emailService:        emailService,
+		signingKey:          []byte(signingKey),
+	}
+}
+
+// SendDueReminders emails users whose active goals had no progress for their configured number of days
+// Sent at most once per inactivity period, at the user's reminder hour
+func (s *NotificationService) SendDueReminders(now time.Time) error {
+	profiles, err := s.profileRepository.NotificationProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	for _, profile := range profiles {
+		if !profile.RemindersEnabled || !reminderDue(profile, now) {
+			continue
+		}
+
+		err := s.sendReminder(profile, now)
+		if err != nil {
+			slog.Error("failed to send goal reminder", "error", err, "user_id", profile.UserID)
+		}
+	}
+
+	return nil
+}
+
+// SendDueDigests emails the weekly progress summary on the user's chosen weekday
+func (s *NotificationService) SendDueDigests(now time.Time) error {
+	profiles, err := s.profileRepository.NotificationProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	for _, profile := range profiles {
+		if !profile.DigestEnabled || !digestDue(profile, now) {
+			continue
+		}
+
+		err := s.sendDigest(profile, now)
+		if err != nil {
+			slog.Error("failed to send weekly digest", "error", err, "user_id", profile.UserID)
+		}
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendReminder(profile *model.Profile, now time.Time) error {
+	user, err := s.userRepository.ByID(profile.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil
+	}
+
+	goals, err := s.goalRepository.CreatedBy(profile.UserID, repository.GoalSortRecent)
+	if err != nil {
+		return fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	cutoff := now.AddDate(0, 0, -profile.ReminderInactiveDays)
+	var stale []string
+	for _, goal := range goals {
+		if goal.Status != model.GoalStatusActive {
+			continue
+		}
+
+		lastActivity := goal.CreatedAt
+		lastCompleted, err := s.goalEntryRepository.LastCompletedAt(goal.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get last completed entry: %w", err)
+		}
+		if lastCompleted != nil && lastCompleted.After(lastActivity) {
+			lastActivity = *lastCompleted
+		}
+
+		if lastActivity.Before(cutoff) {
+			stale = append(stale, goal.Title)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	// Claimed before sending, so with several instances running only one sends it
+	claimed, err := s.profileRepository.ClaimReminder(profile.UserID, now, reminderCutoff(profile, now).In(now.Location()))
+	if err != nil {
+		return fmt.Errorf("failed to claim reminder: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	err = s.emailService.SendGoalReminderEmail(user.Email, profile.Name, stale, profile.ReminderInactiveDays, s.UnsubscribeToken(user.ID, NotificationReminders))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendDigest(profile *model.Profile, now time.Time) error {
+	user, err := s.userRepository.ByID(profile.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil
+	}
+
+	goals, err := s.goalRepository.CreatedBy(profile.UserID, repository.GoalSortProgress)
+	if err != nil {
+		return fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	weekAgo := now.AddDate(0, 0, -7)
+	var digest []emails.GoalDigest
+	for _, goal := range goals {
+		count, err := s.goalEntryRepository.CountCompletedSince(goal.ID, weekAgo)
+		if err != nil {
+			return fmt.Errorf("failed to count completed entries: %w", err)
+		}
+
+		// Skip goals completed before this week
+		if goal.Status != model.GoalStatusActive && count == 0 {
+			continue
+		}
+
+		digest = append(digest, emails.GoalDigest{
+			Title:         goal.Title,
+			CurrentStep:   goal.CurrentStep,
+			TargetSteps:   goal.TargetSteps,
+			StepsThisWeek: count,
+			Completed:     goal.Status == model.GoalStatusCompleted,
+		})
+	}
+
+	// Claimed even without goals, so we don't re-check every tick today.
+	// Claimed before sending, so with several instances running only one sends it
+	claimed, err := s.profileRepository.ClaimDigest(profile.UserID, now, digestCutoff(profile, now).In(now.Location()))
+	if err != nil {
+		return fmt.Errorf("failed to claim digest: %w", err)
+	}
+	if !claimed || len(digest) == 0 {
+		return nil
+	}
+
+	err = s.emailService.SendWeeklyDigestEmail(user.Email, profile.Name, digest, s.UnsubscribeToken(user.ID, NotificationDigest))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// UnsubscribeToken returns a signed token for the one-click unsubscribe link
+// Format: base64(userID:kind).hmac — no login required, can't be forged for other users
+func (s *NotificationService) UnsubscribeToken(userID, kind string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + ":" + kind))
+	return payload + "." + s.sign(payload)
+}
+
+// Unsubscribe disables the notification kind encoded in the token
+// Returns the kind so the confirmation page can say what was turned off
+func (s *NotificationService) Unsubscribe(token string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(s.sign(payload)), []byte(signature)) {
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	userID, kind, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	profile, err := s.profileRepository.ByUserID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	switch kind {
+	case NotificationReminders:
+		profile.RemindersEnabled = false
+	case NotificationDigest:
+		profile.DigestEnabled = false
+	default:
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	err = s.profileRepository.UpdateNotificationSettings(profile)
+	if err != nil {
+		return "", fmt.Errorf("failed to update notification settings: %w", err)
+	}
+
+	slog.Info("unsubscribed from notifications", "user_id", userID, "kind", kind)
+	return kind, nil
+}
+
+func (s *NotificationService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("unsubscribe:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// reminderDue reports whether the reminder hour has passed today (user's time)
+// and at least ReminderInactiveDays calendar days have passed since the last reminder
+func reminderDue(profile *model.Profile, now time.Time) bool {
+	local := now.In(profile.Location())
+	if local.Hour() < profile.ReminderHour {
+		return false
+	}
+
+	return profile.LastReminderAt == nil || profile.LastReminderAt.Before(reminderCutoff(profile, now))
+}
+
+// digestDue reports whether it's the user's digest weekday, past their reminder hour,
+// and the digest hasn't been sent today
+func digestDue(profile *model.Profile, now time.Time) bool {
+	local := now.In(profile.Location())
+	if int(local.Weekday()) != profile.DigestWeekday || local.Hour() < profile.ReminderHour {
+		return false
+	}
+
+	return profile.LastDigestAt == nil || profile.LastDigestAt.Before(digestCutoff(profile, now))
+}
+
+// reminderCutoff is local midnight ReminderInactiveDays-1 days ago (user's time)
+// A reminder sent before it is at least ReminderInactiveDays calendar days old
+func reminderCutoff(profile *model.Profile, now time.Time) time.Time {
+	local := now.In(profile.Location())
+	return time.Date(local.Year(), local.Month(), local.Day()-profile.ReminderInactiveDays+1, 0, 0, 0, 0, local.Location())
+}
+
+// digestCutoff is the start of today (user's time), one digest per day at most
+func digestCutoff(profile *model.Profile, now time.Time) time.Time {
+	local := now.In(profile.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
@@ -0,0 +1,819 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+	"github.com/templui/goilerplate/internal/validation"
+)
+
+const (
+	workspaceMaxNameLen = 60
+	workspaceMaxSeats   = 1000
+	invitationExpiry    = 7 * 24 * time.Hour // Also stated in the invitation email
+)
+
+var (
+	ErrWorkspaceNameRequired    = fmt.Errorf("workspace name is required (max %d characters)", workspaceMaxNameLen)
+	ErrWorkspacePermission      = errors.New("you don't have permission to do this in this workspace")
+	ErrPersonalWorkspace        = errors.New("your personal workspace can't be shared, create a new workspace to invite people")
+	ErrInvalidRole              = errors.New("invalid role")
+	ErrAlreadyMember            = errors.New("this person is already a member of the workspace")
+	ErrInvitationExists         = errors.New("this email already has a pending invitation")
+	ErrInvalidInvitation        = errors.New("this invitation is invalid or has expired")
+	ErrInvitationEmailMismatch  = errors.New("this invitation was sent to a different email address")
+	ErrLastOwner                = errors.New("a workspace needs at least one owner")
+	ErrSoleWorkspaceOwner       = errors.New("you are the only owner of a workspace with other members. Make someone else an owner or delete the workspace first")
+	ErrWorkspaceHasSubscription = errors.New("cannot delete a workspace with an active subscription")
+	ErrNoSeatsAvailable         = errors.New("all seats are in use, add seats or upgrade on the billing page to invite more people")
+	ErrSeatsNotAvailable        = errors.New("seats can only be changed on an active paid plan")
+	ErrInvalidSeats             = fmt.Errorf("seats must be between 1 and %d", workspaceMaxSeats)
+	ErrSeatsInUse               = errors.New("more seats are in use, remove members or revoke invitations first")
+)
+
+// SeatUpdater pushes a new seat quantity for a paid subscription to the payment provider
+// Implemented by payment.Provider, declared here because the payment package imports service
+type SeatUpdater interface {
+	UpdateSeats(subscription *model.Subscription, seats int) error
+}
+
+// OrganizationService manages workspaces, their members and invitations
+// Every user has a personal workspace, shared workspaces are created on top of it.
+// Owners manage billing and can delete the workspace, admins manage members and invitations.
+// Members and pending invitations each take up a seat of the workspace's plan.
+type OrganizationService struct {
+	organizationRepository repository.OrganizationRepository
+	membershipRepository   repository.MembershipRepository
+	invitationRepository   repository.InvitationRepository
+	userRepository         repository.UserRepository
+	goalRepository         repository.GoalRepository
+	fileService            *FileService
+	txManager              repository.TxManager
+	subscriptionService    *SubscriptionService
+	seatUpdater            SeatUpdater
+	emailService           *EmailService
+}
+
+func NewOrganizationService(
+	organizationRepository repository.OrganizationRepository,
+	membershipRepository repository.MembershipRepository,
+	invitationRepository repository.InvitationRepository,
+	userRepository repository.UserRepository,
+	goalRepository repository.GoalRepository,
+	fileService *FileService,
+	txManager repository.TxManager,
+	subscriptionService *SubscriptionService,
+	seatUpdater SeatUpdater,
+	emailService *EmailService,
+) *OrganizationService {
+	return &OrganizationService{
+		organizationRepository: organizationRepository,
+		membershipRepository:   membershipRepository,
+		invitationRepository:   invitationRepository,
+		userRepository:         userRepository,
+		goalRepository:         goalRepository,
+		fileService:            fileService,
+		txManager:              txManager,
+		subscriptionService:    subscriptionService,
+		seatUpdater:            seatUpdater,
+		emailService:           emailService,
+	}
+}
+
+// CreatePersonalWorkspace sets up the workspace every new account starts with
+// It runs in the signup transaction, so the account is never left without one.
+func (s *OrganizationService) CreatePersonalWorkspace(tx *repository.Repositories, userID string) (*model.Organization, error) {
+	return s.create(tx, userID, model.PersonalOrganizationName, true)
+}
+
+// CreateWorkspace creates a shared workspace owned by the user
+func (s *OrganizationService) CreateWorkspace(userID, name string) (*model.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > workspaceMaxNameLen {
+		return nil, ErrWorkspaceNameRequired
+	}
+
+	var organization *model.Organization
+	err := s.txManager.WithTx(func(tx *repository.Repositories) error {
+		var err error
+		organization, err = s.create(tx, userID, name, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace created", "organization_id", organization.ID, "user_id", userID)
+	return organization, nil
+}
+
+// create inserts the workspace, its owner and a free subscription
+func (s *OrganizationService) create(tx *repository.Repositories, userID, name string, personal bool) (*model.Organization, error) {
+	organization := &model.Organization{
+		Name:     name,
+		Personal: personal,
+	}
+
+	err := tx.Organizations.Create(organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	err = tx.Memberships.Create(&model.Membership{
+		OrganizationID: organization.ID,
+		UserID:         userID,
+		Role:           model.RoleOwner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	err = tx.Subscriptions.Create(newFreeSubscription(organization.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create free subscription: %w", err)
+	}
+
+	return organization, nil
+}
+
+// Memberships returns all workspaces of the user, personal first
+func (s *OrganizationService) Memberships(userID string) ([]*model.Membership, error) {
+	memberships, err := s.membershipRepository.ByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+	return memberships, nil
+}
+
+// Membership returns the user's membership in a workspace
+func (s *OrganizationService) Membership(organizationID, userID string) (*model.Membership, error) {
+	return s.membershipRepository.ByOrganizationAndUser(organizationID, userID)
+}
+
+// Members returns everyone in the workspace with their email and name
+func (s *OrganizationService) Members(organizationID string) ([]*model.Membership, error) {
+	members, err := s.membershipRepository.Members(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	return members, nil
+}
+
+// Rename changes the workspace name, admins and owners only
+func (s *OrganizationService) Rename(membership *model.Membership, name string) error {
+	if !membership.CanManageMembers() {
+		return ErrWorkspacePermission
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > workspaceMaxNameLen {
+		return ErrWorkspaceNameRequired
+	}
+
+	err := s.organizationRepository.UpdateName(membership.OrganizationID, name)
+	if err != nil {
+		return fmt.Errorf("failed to rename workspace: %w", err)
+	}
+	return nil
+}
+
+// Invitations returns the workspace's pending invitations
+func (s *OrganizationService) Invitations(organizationID string) ([]*model.Invitation, error) {
+	invitations, err := s.invitationRepository.Pending(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// PendingInvitations returns the invitations sent to an email that can still be accepted
+func (s *OrganizationService) PendingInvitations(email string) ([]*model.Invitation, error) {
+	invitations, err := s.invitationRepository.PendingByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// Invite emails an invitation link to join the workspace
+// The link is a regular token (TokenTypeInvitation) owned by the inviting user.
+func (s *OrganizationService) Invite(membership *model.Membership, inviterName, email, role string) (*model.Invitation, error) {
+	if !membership.CanManageMembers() {
+		return nil, ErrWorkspacePermission
+	}
+	if membership.Personal {
+		return nil, ErrPersonalWorkspace
+	}
+	if !model.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if role == model.RoleOwner && !membership.IsOwner() {
+		return nil, ErrWorkspacePermission
+	}
+
+	email = strings.TrimSpace(strings.ToLower(email))
+	err := validation.ValidateEmail(email)
+	if err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	plain, err := generateInvitationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	// Checks and inserts in one transaction, so concurrent invitations can't both take the last seat
+	var invitation *model.Invitation
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := tx.Organizations.Lock(membership.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to lock workspace: %w", err)
+		}
+
+		invitee, err := tx.Users.ByEmail(email)
+		if err == nil {
+			_, err = tx.Memberships.ByOrganizationAndUser(membership.OrganizationID, invitee.ID)
+			if err == nil {
+				return ErrAlreadyMember
+			}
+			if !errors.Is(err, repository.ErrMembershipNotFound) {
+				return fmt.Errorf("failed to check membership: %w", err)
+			}
+		} else if !errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("failed to lookup user: %w", err)
+		}
+
+		pending, err := tx.Invitations.Pending(membership.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to get invitations: %w", err)
+		}
+		for _, invitation := range pending {
+			if invitation.Email == email {
+				return ErrInvitationExists
+			}
+		}
+
+		subscription, err := tx.Subscriptions.ByOrganizationID(membership.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to get subscription: %w", err)
+		}
+		used, err := seatsUsed(tx.Memberships, tx.Invitations, membership.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if used >= subscription.SeatLimit() {
+			return ErrNoSeatsAvailable
+		}
+
+		token := &model.Token{
+			UserID:    membership.UserID,
+			Type:      model.TokenTypeInvitation,
+			Token:     plain,
+			ExpiresAt: time.Now().Add(invitationExpiry),
+		}
+		err = tx.Tokens.Create(token)
+		if err != nil {
+			return fmt.Errorf("failed to create token: %w", err)
+		}
+
+		invitation = &model.Invitation{
+			OrganizationID: membership.OrganizationID,
+			Email:          email,
+			Role:           role,
+			TokenID:        token.ID,
+			InvitedBy:      membership.UserID,
+		}
+		err = tx.Invitations.Create(invitation)
+		if err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.emailService.SendWorkspaceInvitationEmail(email, plain, inviterName, membership.OrganizationName, model.RoleName(role))
+	if err != nil {
+		slog.Error("failed to send invitation email", "error", err, "organization_id", membership.OrganizationID, "email", email)
+		s.discardInvitation(invitation)
+		return nil, fmt.Errorf("failed to send email: %w", err)
+	}
+
+	slog.Info("workspace invitation sent", "organization_id", membership.OrganizationID, "invited_by", membership.UserID, "email", email, "role", role)
+	s.syncSeats(membership.OrganizationID)
+	return invitation, nil
+}
+
+// discardInvitation removes an invitation whose email was never sent
+// so it doesn't hold a seat or block inviting the same email again
+func (s *OrganizationService) discardInvitation(invitation *model.Invitation) {
+	err := s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := tx.Invitations.Delete(invitation.OrganizationID, invitation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete invitation: %w", err)
+		}
+
+		err = tx.Tokens.Delete(invitation.TokenID)
+		if err != nil {
+			return fmt.Errorf("failed to delete token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to discard invitation", "error", err, "organization_id", invitation.OrganizationID, "invitation_id", invitation.ID)
+	}
+}
+
+// RevokeInvitation cancels a pending invitation, its link stops working
+func (s *OrganizationService) RevokeInvitation(membership *model.Membership, invitationID string) error {
+	if !membership.CanManageMembers() {
+		return ErrWorkspacePermission
+	}
+
+	err := s.invitationRepository.Delete(membership.OrganizationID, invitationID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	s.syncSeats(membership.OrganizationID)
+	return nil
+}
+
+// InvitationByToken returns a pending invitation for the accept page
+func (s *OrganizationService) InvitationByToken(token string) (*model.Invitation, error) {
+	invitation, err := s.invitationRepository.ByToken(token)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the invitation's workspace
+// Only the invited email can accept, the token is consumed so the link works once.
+// Consuming the token, adding the member and marking the invitation accepted happen
+// in one transaction, so a failure never leaves a used link without a membership.
+func (s *OrganizationService) AcceptInvitation(user *model.User, token string) (*model.Membership, error) {
+	invitation, err := s.invitationFor(user, token)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := false
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := claimInvitation(tx, token)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Memberships.ByOrganizationAndUser(invitation.OrganizationID, user.ID)
+		if err == nil {
+			// Joined in the meantime through another invitation, nothing to add
+			return markAccepted(tx, invitation)
+		}
+		if !errors.Is(err, repository.ErrMembershipNotFound) {
+			return fmt.Errorf("failed to check membership: %w", err)
+		}
+
+		err = tx.Memberships.Create(&model.Membership{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         user.ID,
+			Role:           invitation.Role,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create membership: %w", err)
+		}
+		joined = true
+
+		return markAccepted(tx, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if joined {
+		slog.Info("workspace invitation accepted", "organization_id", invitation.OrganizationID, "user_id", user.ID, "role", invitation.Role)
+	}
+	s.syncSeats(invitation.OrganizationID)
+	return s.membershipRepository.ByOrganizationAndUser(invitation.OrganizationID, user.ID)
+}
+
+// DeclineInvitation removes an invitation sent to the user
+func (s *OrganizationService) DeclineInvitation(user *model.User, token string) error {
+	invitation, err := s.invitationFor(user, token)
+	if err != nil {
+		return err
+	}
+
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		err := claimInvitation(tx, token)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Invitations.Delete(invitation.OrganizationID, invitation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("workspace invitation declined", "organization_id", invitation.OrganizationID, "user_id", user.ID)
+	s.syncSeats(invitation.OrganizationID)
+	return nil
+}
+
+// invitationFor returns the pending invitation if it was sent to the user
+func (s *OrganizationService) invitationFor(user *model.User, token string) (*model.Invitation, error) {
+	invitation, err := s.InvitationByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	return invitation, nil
+}
+
+// claimInvitation consumes the invitation's token
+func claimInvitation(tx *repository.Repositories, token string) error {
+	// ConsumeToken atomically marks token as used (prevents race conditions)
+	tokenModel, err := tx.Tokens.ConsumeToken(token)
+	if err != nil {
+		return ErrInvalidInvitation
+	}
+	if tokenModel.Type != model.TokenTypeInvitation {
+		return ErrInvalidInvitation
+	}
+	return nil
+}
+
+func markAccepted(tx *repository.Repositories, invitation *model.Invitation) error {
+	err := tx.Invitations.MarkAccepted(invitation.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark invitation accepted: %w", err)
+	}
+	return nil
+}
+
+// ChangeRole updates a member's role
+// Admins manage admins and members, only owners can grant or take away ownership.
+func (s *OrganizationService) ChangeRole(membership *model.Membership, memberID, role string) error {
+	if !membership.CanManageMembers() {
+		return ErrWorkspacePermission
+	}
+	if !model.ValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	target, err := s.membershipRepository.ByID(membership.OrganizationID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+	if target.Role == role {
+		return nil
+	}
+
+	if (target.IsOwner() || role == model.RoleOwner) && !membership.IsOwner() {
+		return ErrWorkspacePermission
+	}
+
+	if target.IsOwner() {
+		err = s.requireAnotherOwner(membership.OrganizationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.membershipRepository.UpdateRole(membership.OrganizationID, memberID, role)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	slog.Info("member role changed", "organization_id", membership.OrganizationID, "user_id", target.UserID, "role", role, "changed_by", membership.UserID)
+	return nil
+}
+
+// RemoveMember removes someone else from the workspace, members leave with Leave
+func (s *OrganizationService) RemoveMember(membership *model.Membership, memberID string) error {
+	if !membership.CanManageMembers() {
+		return ErrWorkspacePermission
+	}
+
+	target, err := s.membershipRepository.ByID(membership.OrganizationID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+
+	if target.UserID == membership.UserID {
+		return s.Leave(membership)
+	}
+
+	if target.IsOwner() && !membership.IsOwner() {
+		return ErrWorkspacePermission
+	}
+
+	err = s.membershipRepository.Delete(membership.OrganizationID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	slog.Info("member removed", "organization_id", membership.OrganizationID, "user_id", target.UserID, "removed_by", membership.UserID)
+	s.syncSeats(membership.OrganizationID)
+	return nil
+}
+
+// Leave removes the user from a shared workspace
+// Goals the user created stay in the workspace.
+func (s *OrganizationService) Leave(membership *model.Membership) error {
+	if membership.Personal {
+		return ErrPersonalWorkspace
+	}
+
+	if membership.IsOwner() {
+		err := s.requireAnotherOwner(membership.OrganizationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.membershipRepository.Delete(membership.OrganizationID, membership.ID)
+	if err != nil {
+		return fmt.Errorf("failed to leave workspace: %w", err)
+	}
+
+	slog.Info("member left workspace", "organization_id", membership.OrganizationID, "user_id", membership.UserID)
+	s.syncSeats(membership.OrganizationID)
+	return nil
+}
+
+// DeleteWorkspace deletes a shared workspace with all its goals, owners only
+func (s *OrganizationService) DeleteWorkspace(membership *model.Membership) error {
+	if !membership.IsOwner() {
+		return ErrWorkspacePermission
+	}
+	if membership.Personal {
+		return ErrPersonalWorkspace
+	}
+
+	subscription, err := s.subscriptionService.Subscription(membership.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if subscription.HasRunningPaidPlan() {
+		return ErrWorkspaceHasSubscription
+	}
+
+	var attachments []*model.File
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Collected first, the entries they point to cascade with the workspace
+		attachments, err = tx.Files.OrganizationAttachments(membership.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to get attachments: %w", err)
+		}
+
+		err = tx.Organizations.Delete(membership.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to delete workspace: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.fileService.DeleteFiles(attachments)
+
+	slog.Info("workspace deleted", "organization_id", membership.OrganizationID, "user_id", membership.UserID)
+	return nil
+}
+
+// UserRemoval is what RemoveUser leaves for after the account deletion committed
+type UserRemoval struct {
+	attachments     []*model.File // of the deleted workspaces
+	organizationIDs []string      // shared workspaces the user left
+}
+
+// RemoveUser takes a user out of all workspaces before their account is deleted
+// It runs in the account deletion transaction, FinishRemoveUser does the rest after commit.
+// Workspaces only the user belongs to are deleted, including the personal one.
+// Goals the user created in shared workspaces are handed to another owner so they
+// don't cascade with the account, so are the attachments the user uploaded there. Nothing is changed when the deletion is blocked.
+func (s *OrganizationService) RemoveUser(tx *repository.Repositories, userID string) (*UserRemoval, error) {
+	memberships, err := tx.Memberships.ByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+
+	var deleteIDs []string
+	var leave []*model.Membership
+	reassignTo := map[string]string{} // organization id -> owner that takes over the user's goals
+	for _, membership := range memberships {
+		members, err := tx.Memberships.Members(membership.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get members: %w", err)
+		}
+
+		var otherOwner string
+		for _, member := range members {
+			if member.UserID != userID && member.IsOwner() {
+				otherOwner = member.UserID
+				break
+			}
+		}
+
+		switch {
+		case len(members) == 1:
+			subscription, err := tx.Subscriptions.ByOrganizationID(membership.OrganizationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get subscription: %w", err)
+			}
+			if subscription.HasRunningPaidPlan() {
+				return nil, ErrActiveSubscription
+			}
+			deleteIDs = append(deleteIDs, membership.OrganizationID)
+		case otherOwner != "":
+			reassignTo[membership.OrganizationID] = otherOwner
+			leave = append(leave, membership)
+		default:
+			return nil, ErrSoleWorkspaceOwner
+		}
+	}
+
+	removal := &UserRemoval{}
+	for organizationID, ownerID := range reassignTo {
+		err = tx.Goals.ReassignCreator(organizationID, userID, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reassign goals: %w", err)
+		}
+
+		err = tx.Files.ReassignAttachments(organizationID, userID, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reassign attachments: %w", err)
+		}
+	}
+
+	for _, membership := range leave {
+		err = tx.Memberships.Delete(membership.OrganizationID, membership.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to leave workspace: %w", err)
+		}
+		removal.organizationIDs = append(removal.organizationIDs, membership.OrganizationID)
+	}
+
+	for _, organizationID := range deleteIDs {
+		attachments, err := tx.Files.OrganizationAttachments(organizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attachments: %w", err)
+		}
+		removal.attachments = append(removal.attachments, attachments...)
+
+		err = tx.Organizations.Delete(organizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete workspace: %w", err)
+		}
+	}
+
+	return removal, nil
+}
+
+// FinishRemoveUser deletes the files of the removed workspaces and releases seats
+// Best effort, the account is already gone.
+func (s *OrganizationService) FinishRemoveUser(removal *UserRemoval) {
+	s.fileService.DeleteFiles(removal.attachments)
+	for _, organizationID := range removal.organizationIDs {
+		s.syncSeats(organizationID)
+	}
+}
+
+// SeatsUsed counts members and pending invitations, both take up a seat
+func (s *OrganizationService) SeatsUsed(organizationID string) (int, error) {
+	return seatsUsed(s.membershipRepository, s.invitationRepository, organizationID)
+}
+
+func seatsUsed(memberships repository.MembershipRepository, invitations repository.InvitationRepository, organizationID string) (int, error) {
+	members, err := memberships.Count(organizationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count members: %w", err)
+	}
+
+	pending, err := invitations.CountPending(organizationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count invitations: %w", err)
+	}
+
+	return members + pending, nil
+}
+
+// UpdateSeats changes how many seats a paid workspace pays for, owners only
+// Seats can't go below what is in use, members have to be removed first.
+// Extra seats make room for the next invitations, the next membership change
+// brings the quantity back to the seats in use (see syncSeats).
+func (s *OrganizationService) UpdateSeats(membership *model.Membership, seats int) error {
+	if !membership.CanManageBilling() {
+		return ErrWorkspacePermission
+	}
+	if seats < 1 || seats > workspaceMaxSeats {
+		return ErrInvalidSeats
+	}
+
+	subscription, err := s.subscriptionService.Subscription(membership.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !subscription.IsPaid() || subscription.ProviderSubscriptionID == nil {
+		return ErrSeatsNotAvailable
+	}
+
+	used, err := s.SeatsUsed(membership.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if seats < used {
+		return ErrSeatsInUse
+	}
+
+	err = s.pushSeats(subscription, seats)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("workspace seats updated", "organization_id", membership.OrganizationID, "seats", seats, "user_id", membership.UserID)
+	return nil
+}
+
+// syncSeats sets the paid seats to the seats in use after people join or leave
+// Called after the change committed, never inside a transaction. Failures are
+// logged, the membership change itself already happened.
+func (s *OrganizationService) syncSeats(organizationID string) {
+	subscription, err := s.subscriptionService.Subscription(organizationID)
+	if err != nil {
+		slog.Error("failed to get subscription for seat update", "error", err, "organization_id", organizationID)
+		return
+	}
+	if !subscription.IsPaid() || subscription.ProviderSubscriptionID == nil {
+		return
+	}
+
+	used, err := s.SeatsUsed(organizationID)
+	if err != nil {
+		slog.Error("failed to count seats", "error", err, "organization_id", organizationID)
+		return
+	}
+
+	seats := max(used, 1)
+	err = s.pushSeats(subscription, seats)
+	if err != nil {
+		slog.Error("failed to update seats", "error", err, "organization_id", organizationID, "seats", seats)
+	}
+}
+
+// pushSeats updates the quantity at the payment provider first, then stores it
+func (s *OrganizationService) pushSeats(subscription *model.Subscription, seats int) error {
+	if subscription.Seats == seats {
+		return nil
+	}
+
+	err := s.seatUpdater.UpdateSeats(subscription, seats)
+	if err != nil {
+		return fmt.Errorf("failed to update seats: %w", err)
+	}
+
+	subscription.Seats = seats
+	return s.subscriptionService.UpdateSubscription(subscription)
+}
+
+func (s *OrganizationService) requireAnotherOwner(organizationID string) error {
+	owners, err := s.membershipRepository.CountOwners(organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// generateInvitationToken returns a random link token (256 bits of entropy)
+func generateInvitationToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
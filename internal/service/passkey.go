@@ -0,0 +1,465 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	// PasskeyRegistrationCookieName holds the pending registration ceremony started from settings
+	PasskeyRegistrationCookieName = "passkey_registration"
+	// PasskeyLoginCookieName holds the pending sign-in ceremony started from /auth
+	PasskeyLoginCookieName = "passkey_login"
+
+	passkeyCeremonyExpiry = 5 * time.Minute
+	passkeyMaxPerUser     = 10
+	passkeyMaxNameLen     = 100
+	passkeyDefaultName    = "Passkey"
+
+	passkeyPurposeRegistration = "registration"
+	passkeyPurposeLogin        = "login"
+)
+
+var (
+	ErrPasskeyNameTooLong        = fmt.Errorf("passkey name must be at most %d characters", passkeyMaxNameLen)
+	ErrPasskeyLimitReached       = fmt.Errorf("you can register at most %d passkeys", passkeyMaxPerUser)
+	ErrPasskeyAlreadyRegistered  = errors.New("this passkey is already registered")
+	ErrPasskeyVerificationFailed = errors.New("passkey verification failed")
+	ErrInvalidPasskeyCeremony    = errors.New("invalid or expired passkey request, please try again")
+)
+
+// PasskeyService runs the WebAuthn registration and sign-in ceremonies
+// Ceremony state (challenge, allowed credentials) lives in a short-lived signed cookie,
+// so no server-side storage is needed between the two requests of a ceremony.
+// Finish* methods take the raw JSON credential from navigator.credentials, which keeps
+// verification independent of net/http and testable with software authenticator fixtures.
+type PasskeyService struct {
+	passkeyRepository repository.PasskeyRepository
+	userRepository    repository.UserRepository
+	webAuthn          *webauthn.WebAuthn
+	jwtSecret         string
+	isProduction      bool
+}
+
+// NewPasskeyService configures the relying party from the app URL
+// The RP ID is the host of appURL, so passkeys are bound to that domain
+func NewPasskeyService(
+	passkeyRepository repository.PasskeyRepository,
+	userRepository repository.UserRepository,
+	appName string,
+	appURL string,
+	jwtSecret string,
+	isProduction bool,
+) (*PasskeyService, error) {
+	parsed, err := url.Parse(appURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid app url for passkeys: %q", appURL)
+	}
+
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    passkeyCeremonyExpiry,
+		TimeoutUVD: passkeyCeremonyExpiry,
+	}
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          parsed.Hostname(),
+		RPDisplayName: appName,
+		RPOrigins:     []string{parsed.Scheme + "://" + parsed.Host},
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure webauthn: %w", err)
+	}
+
+	return &PasskeyService{
+		passkeyRepository: passkeyRepository,
+		userRepository:    userRepository,
+		webAuthn:          webAuthn,
+		jwtSecret:         jwtSecret,
+		isProduction:      isProduction,
+	}, nil
+}
+
+func (s *PasskeyService) Passkeys(userID string) ([]*model.Passkey, error) {
+	return s.passkeyRepository.ByUserID(userID)
+}
+
+// BeginRegistration starts adding a passkey to the user's account
+// Returns the PublicKeyCredentialCreationOptions JSON for navigator.credentials.create
+// and the ceremony token to keep until FinishRegistration
+func (s *PasskeyService) BeginRegistration(userID string) (options []byte, ceremony string, err error) {
+	user, err := s.webAuthnUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(user.passkeys) >= passkeyMaxPerUser {
+		return nil, "", ErrPasskeyLimitReached
+	}
+
+	// Exclude existing credentials so the same authenticator isn't registered twice
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin registration: %w", err)
+	}
+
+	return s.startCeremony(passkeyPurposeRegistration, userID, creation, session)
+}
+
+// FinishRegistration verifies the authenticator response and stores the new passkey
+func (s *PasskeyService) FinishRegistration(userID, name, ceremony string, response []byte) (*model.Passkey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = passkeyDefaultName
+	}
+	if len(name) > passkeyMaxNameLen {
+		return nil, ErrPasskeyNameTooLong
+	}
+
+	session, err := s.verifyCeremony(ceremony, passkeyPurposeRegistration, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.webAuthnUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(user.passkeys) >= passkeyMaxPerUser {
+		return nil, ErrPasskeyLimitReached
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyVerificationFailed, err)
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyVerificationFailed, err)
+	}
+
+	credentialID := encodePasskeyBytes(credential.ID)
+	_, err = s.passkeyRepository.ByCredentialID(credentialID)
+	if err == nil {
+		return nil, ErrPasskeyAlreadyRegistered
+	}
+	if !errors.Is(err, repository.ErrPasskeyNotFound) {
+		return nil, fmt.Errorf("failed to check passkey: %w", err)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	passkey := &model.Passkey{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    credentialID,
+		PublicKey:       encodePasskeyBytes(credential.PublicKey),
+		AttestationType: credential.AttestationType,
+		AAGUID:          encodePasskeyBytes(credential.Authenticator.AAGUID),
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Flags:           int(credential.Flags.ProtocolValue()),
+		Transports:      strings.Join(transports, ","),
+	}
+
+	err = s.passkeyRepository.Create(passkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save passkey: %w", err)
+	}
+
+	slog.Info("passkey registered", "user_id", userID, "passkey_id", passkey.ID)
+	return passkey, nil
+}
+
+// BeginLogin starts a discoverable-credential sign-in
+// No email is needed, the authenticator offers the passkeys it holds for this site
+func (s *PasskeyService) BeginLogin() (options []byte, ceremony string, err error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin login: %w", err)
+	}
+
+	return s.startCeremony(passkeyPurposeLogin, "", assertion, session)
+}
+
+// FinishLogin verifies the assertion and returns the signed-in user
+// The passkey is a complete sign-in on its own: it doesn't need a password
+// and user verification (PIN, biometrics) stands in for the TOTP second factor
+func (s *PasskeyService) FinishLogin(ceremony string, response []byte) (*model.User, error) {
+	session, err := s.verifyCeremony(ceremony, passkeyPurposeLogin, "")
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyVerificationFailed, err)
+	}
+
+	// The user handle returned by the authenticator is our user id
+	var owner *passkeyUser
+	findOwner := func(rawID, userHandle []byte) (webauthn.User, error) {
+		user, err := s.webAuthnUser(string(userHandle))
+		if err != nil {
+			return nil, err
+		}
+		owner = user
+		return user, nil
+	}
+
+	credential, err := s.webAuthn.ValidateDiscoverableLogin(findOwner, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasskeyVerificationFailed, err)
+	}
+
+	// A counter that went backwards means the private key may have been cloned
+	if credential.Authenticator.CloneWarning {
+		slog.Warn("passkey sign count regressed, possible cloned authenticator", "user_id", owner.user.ID)
+		return nil, ErrPasskeyVerificationFailed
+	}
+
+	passkey := owner.passkey(credential.ID)
+	if passkey == nil {
+		return nil, ErrPasskeyVerificationFailed
+	}
+
+	flags := int(parsed.Response.AuthenticatorData.Flags)
+	err = s.passkeyRepository.RecordUse(passkey.ID, int64(credential.Authenticator.SignCount), flags, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	return owner.user, nil
+}
+
+func (s *PasskeyService) Rename(userID, passkeyID, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = passkeyDefaultName
+	}
+	if len(name) > passkeyMaxNameLen {
+		return ErrPasskeyNameTooLong
+	}
+
+	err := s.passkeyRepository.Rename(userID, passkeyID, name)
+	if err != nil {
+		return fmt.Errorf("failed to rename passkey: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a passkey
+// Removing the last one is fine, magic links keep working for passwordless accounts
+func (s *PasskeyService) Delete(userID, passkeyID string) error {
+	err := s.passkeyRepository.Delete(userID, passkeyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	slog.Info("passkey deleted", "user_id", userID, "passkey_id", passkeyID)
+	return nil
+}
+
+func (s *PasskeyService) SetCeremonyCookie(w http.ResponseWriter, name, ceremony string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    ceremony,
+		Path:     "/",
+		MaxAge:   int(passkeyCeremonyExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   s.isProduction,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func (s *PasskeyService) ClearCeremonyCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.isProduction,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// startCeremony serializes the browser options and signs the session data into a ceremony token
+func (s *PasskeyService) startCeremony(purpose, userID string, options any, session *webauthn.SessionData) ([]byte, string, error) {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode options: %w", err)
+	}
+
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	claims := jwt.MapClaims{
+		"purpose": purpose,
+		"user_id": userID,
+		"session": string(sessionJSON),
+		"exp":     time.Now().Add(passkeyCeremonyExpiry).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.ceremonyKey())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign ceremony: %w", err)
+	}
+
+	return optionsJSON, token, nil
+}
+
+// verifyCeremony checks a ceremony token and returns its session data
+// userID must match the user who started it (empty for sign-in)
+func (s *PasskeyService) verifyCeremony(tokenString, purpose, userID string) (*webauthn.SessionData, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.ceremonyKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidPasskeyCeremony
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidPasskeyCeremony
+	}
+
+	tokenPurpose, _ := claims["purpose"].(string)
+	tokenUserID, _ := claims["user_id"].(string)
+	sessionJSON, _ := claims["session"].(string)
+	if tokenPurpose != purpose || tokenUserID != userID {
+		return nil, ErrInvalidPasskeyCeremony
+	}
+
+	var session webauthn.SessionData
+	err = json.Unmarshal([]byte(sessionJSON), &session)
+	if err != nil {
+		return nil, ErrInvalidPasskeyCeremony
+	}
+
+	return &session, nil
+}
+
+// ceremonyKey derives a separate signing key so ceremony tokens
+// can never be used as auth_token session cookies or two-factor challenges
+func (s *PasskeyService) ceremonyKey() []byte {
+	sum := sha256.Sum256([]byte("passkey-ceremony:" + s.jwtSecret))
+	return sum[:]
+}
+
+// webAuthnUser loads a user with their passkeys for the webauthn library
+func (s *PasskeyService) webAuthnUser(userID string) (*passkeyUser, error) {
+	user, err := s.userRepository.ByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	passkeys, err := s.passkeyRepository.ByUserID(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	return &passkeyUser{user: user, passkeys: passkeys}, nil
+}
+
+// passkeyUser adapts a user and their passkeys to webauthn.User
+// The user handle is the user id, which lets discoverable sign-in find the account
+type passkeyUser struct {
+	user     *model.User
+	passkeys []*model.Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, passkey := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, 0)
+		for _, transport := range passkey.TransportList() {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              decodePasskeyBytes(passkey.CredentialID),
+			PublicKey:       decodePasskeyBytes(passkey.PublicKey),
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(passkey.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    decodePasskeyBytes(passkey.AAGUID),
+				SignCount: uint32(passkey.SignCount),
+			},
+		})
+	}
+	return credentials
+}
+
+// passkey finds the stored passkey for a credential id
+func (u *passkeyUser) passkey(credentialID []byte) *model.Passkey {
+	encoded := encodePasskeyBytes(credentialID)
+	for _, passkey := range u.passkeys {
+		if passkey.CredentialID == encoded {
+			return passkey
+		}
+	}
+	return nil
+}
+
+func encodePasskeyBytes(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func decodePasskeyBytes(value string) []byte {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	return decoded
+}
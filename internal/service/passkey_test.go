@@ -0,0 +1,472 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/google/uuid"
+	"github.com/templui/goilerplate/internal/db"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+const (
+	testPasskeyAppURL = "https://app.example.com"
+	testPasskeyRPID   = "app.example.com"
+
+	// Authenticator data flags (WebAuthn §6.1)
+	authFlagUserPresent  = 0x01
+	authFlagUserVerified = 0x04
+	authFlagAttestedData = 0x40
+)
+
+// softAuthenticator is a software passkey: an ECDSA P-256 key with a
+// resident credential and a sign counter, producing "none" attestations
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+
+	// Overrides for negative cases
+	rpID   string
+	origin string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	credentialID := make([]byte, 32)
+	_, err = rand.Read(credentialID)
+	if err != nil {
+		t.Fatalf("generate credential id: %v", err)
+	}
+
+	return &softAuthenticator{
+		key:          key,
+		credentialID: credentialID,
+		rpID:         testPasskeyRPID,
+		origin:       testPasskeyAppURL,
+	}
+}
+
+// create answers navigator.credentials.create with the given options
+func (a *softAuthenticator) create(t *testing.T, options []byte) []byte {
+	t.Helper()
+
+	var creation struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			User      struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	}
+	err := json.Unmarshal(options, &creation)
+	if err != nil {
+		t.Fatalf("decode creation options: %v", err)
+	}
+
+	a.userHandle = decodeTestBytes(t, creation.PublicKey.User.ID)
+	clientData := a.clientData(t, "webauthn.create", creation.PublicKey.Challenge)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("encode public key: %v", err)
+	}
+
+	// Attested credential data: AAGUID, credential id length, credential id, COSE key
+	attested := make([]byte, 16, 16+2+len(a.credentialID)+len(publicKey))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	authData := a.authenticatorData(authFlagUserPresent | authFlagUserVerified | authFlagAttestedData)
+	authData = append(authData, attested...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("encode attestation: %v", err)
+	}
+
+	return a.credential(t, map[string]any{
+		"clientDataJSON":    encodePasskeyBytes(clientData),
+		"attestationObject": encodePasskeyBytes(attestation),
+		"transports":        []string{"internal"},
+	})
+}
+
+// get answers navigator.credentials.get with the given options
+func (a *softAuthenticator) get(t *testing.T, options []byte) []byte {
+	t.Helper()
+
+	var assertion struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	err := json.Unmarshal(options, &assertion)
+	if err != nil {
+		t.Fatalf("decode assertion options: %v", err)
+	}
+
+	clientData := a.clientData(t, "webauthn.get", assertion.PublicKey.Challenge)
+	authData := a.authenticatorData(authFlagUserPresent | authFlagUserVerified)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.credential(t, map[string]any{
+		"clientDataJSON":    encodePasskeyBytes(clientData),
+		"authenticatorData": encodePasskeyBytes(authData),
+		"signature":         encodePasskeyBytes(signature),
+		"userHandle":        encodePasskeyBytes(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremonyType, challenge string) []byte {
+	t.Helper()
+
+	clientData, err := json.Marshal(map[string]any{
+		"type":        ceremonyType,
+		"challenge":   challenge,
+		"origin":      a.origin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		t.Fatalf("encode client data: %v", err)
+	}
+	return clientData
+}
+
+// authenticatorData builds the rpIdHash, flags and counter prefix
+func (a *softAuthenticator) authenticatorData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) credential(t *testing.T, response map[string]any) []byte {
+	t.Helper()
+
+	credential, err := json.Marshal(map[string]any{
+		"id":       encodePasskeyBytes(a.credentialID),
+		"rawId":    encodePasskeyBytes(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("encode credential: %v", err)
+	}
+	return credential
+}
+
+func decodeTestBytes(t *testing.T, value string) []byte {
+	t.Helper()
+
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		t.Fatalf("decode %q: %v", value, err)
+	}
+	return decoded
+}
+
+type passkeyFixture struct {
+	service  *PasskeyService
+	passkeys repository.PasskeyRepository
+	users    repository.UserRepository
+}
+
+// newPasskeyFixture runs the migrations on a fresh SQLite database
+func newPasskeyFixture(t *testing.T) *passkeyFixture {
+	t.Helper()
+
+	database, err := db.Init("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close(database) })
+
+	err = db.RunMigrations(database.DB, "sqlite")
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	passkeys := repository.NewPasskeyRepository(database)
+	users := repository.NewUserRepository(database)
+
+	service, err := NewPasskeyService(passkeys, users, "Test", testPasskeyAppURL, "test-secret", false)
+	if err != nil {
+		t.Fatalf("new passkey service: %v", err)
+	}
+
+	return &passkeyFixture{service: service, passkeys: passkeys, users: users}
+}
+
+func (f *passkeyFixture) createUser(t *testing.T, passwordHash *string) *model.User {
+	t.Helper()
+
+	now := time.Now()
+	user := &model.User{
+		ID:              uuid.New().String(),
+		Email:           uuid.New().String() + "@example.com",
+		PasswordHash:    passwordHash,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+	}
+	err := f.users.Create(user)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func (f *passkeyFixture) register(t *testing.T, user *model.User, authenticator *softAuthenticator) (*model.Passkey, error) {
+	t.Helper()
+
+	options, ceremony, err := f.service.BeginRegistration(user.ID)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+	return f.service.FinishRegistration(user.ID, "Laptop", ceremony, authenticator.create(t, options))
+}
+
+func (f *passkeyFixture) login(t *testing.T, authenticator *softAuthenticator) (*model.User, error) {
+	t.Helper()
+
+	options, ceremony, err := f.service.BeginLogin()
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	return f.service.FinishLogin(ceremony, authenticator.get(t, options))
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	f := newPasskeyFixture(t)
+	user := f.createUser(t, nil)
+	authenticator := newSoftAuthenticator(t)
+
+	passkey, err := f.register(t, user, authenticator)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if passkey.UserID != user.ID || passkey.Name != "Laptop" {
+		t.Fatalf("unexpected passkey: %+v", passkey)
+	}
+	if passkey.CredentialID != encodePasskeyBytes(authenticator.credentialID) {
+		t.Fatalf("credential id = %q, want the authenticator's", passkey.CredentialID)
+	}
+	if passkey.Transports != "internal" {
+		t.Fatalf("transports = %q, want internal", passkey.Transports)
+	}
+
+	authenticator.signCount = 1
+	signedIn, err := f.login(t, authenticator)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if signedIn.ID != user.ID {
+		t.Fatalf("signed in as %s, want %s", signedIn.ID, user.ID)
+	}
+
+	stored, err := f.passkeys.ByCredentialID(passkey.CredentialID)
+	if err != nil {
+		t.Fatalf("load passkey: %v", err)
+	}
+	if stored.SignCount != 1 || stored.LastUsedAt == nil {
+		t.Fatalf("use not recorded: sign_count=%d last_used_at=%v", stored.SignCount, stored.LastUsedAt)
+	}
+}
+
+func TestPasskeyLoginPasswordlessAccount(t *testing.T) {
+	f := newPasskeyFixture(t)
+	user := f.createUser(t, nil)
+	authenticator := newSoftAuthenticator(t)
+
+	_, err := f.register(t, user, authenticator)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	signedIn, err := f.login(t, authenticator)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if signedIn.ID != user.ID {
+		t.Fatalf("signed in as %s, want %s", signedIn.ID, user.ID)
+	}
+	if signedIn.PasswordHash != nil {
+		t.Fatal("expected a passwordless account")
+	}
+}
+
+func TestPasskeyRegistrationDuplicate(t *testing.T) {
+	f := newPasskeyFixture(t)
+	user := f.createUser(t, nil)
+	other := f.createUser(t, nil)
+	authenticator := newSoftAuthenticator(t)
+
+	_, err := f.register(t, user, authenticator)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	_, err = f.register(t, other, authenticator)
+	if !errors.Is(err, ErrPasskeyAlreadyRegistered) {
+		t.Fatalf("err = %v, want ErrPasskeyAlreadyRegistered", err)
+	}
+}
+
+func TestPasskeyRegistrationRejectsWrongRelyingParty(t *testing.T) {
+	tests := []struct {
+		name   string
+		rpID   string
+		origin string
+	}{
+		{name: "wrong rp id", rpID: "evil.example.com", origin: testPasskeyAppURL},
+		{name: "wrong origin", rpID: testPasskeyRPID, origin: "https://evil.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPasskeyFixture(t)
+			user := f.createUser(t, nil)
+			authenticator := newSoftAuthenticator(t)
+			authenticator.rpID = tt.rpID
+			authenticator.origin = tt.origin
+
+			_, err := f.register(t, user, authenticator)
+			if !errors.Is(err, ErrPasskeyVerificationFailed) {
+				t.Fatalf("err = %v, want ErrPasskeyVerificationFailed", err)
+			}
+
+			passkeys, err := f.passkeys.ByUserID(user.ID)
+			if err != nil {
+				t.Fatalf("list passkeys: %v", err)
+			}
+			if len(passkeys) != 0 {
+				t.Fatalf("stored %d passkeys, want none", len(passkeys))
+			}
+		})
+	}
+}
+
+func TestPasskeyLoginRejectsWrongRelyingParty(t *testing.T) {
+	tests := []struct {
+		name   string
+		rpID   string
+		origin string
+	}{
+		{name: "wrong rp id", rpID: "evil.example.com", origin: testPasskeyAppURL},
+		{name: "wrong origin", rpID: testPasskeyRPID, origin: "https://evil.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPasskeyFixture(t)
+			user := f.createUser(t, nil)
+			authenticator := newSoftAuthenticator(t)
+
+			_, err := f.register(t, user, authenticator)
+			if err != nil {
+				t.Fatalf("register: %v", err)
+			}
+
+			authenticator.rpID = tt.rpID
+			authenticator.origin = tt.origin
+			authenticator.signCount = 1
+
+			_, err = f.login(t, authenticator)
+			if !errors.Is(err, ErrPasskeyVerificationFailed) {
+				t.Fatalf("err = %v, want ErrPasskeyVerificationFailed", err)
+			}
+		})
+	}
+}
+
+func TestPasskeyLoginRejectsSignCountRegression(t *testing.T) {
+	f := newPasskeyFixture(t)
+	user := f.createUser(t, nil)
+	authenticator := newSoftAuthenticator(t)
+
+	passkey, err := f.register(t, user, authenticator)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	authenticator.signCount = 5
+	_, err = f.login(t, authenticator)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	// A clone of the key replays an older counter
+	authenticator.signCount = 3
+	_, err = f.login(t, authenticator)
+	if !errors.Is(err, ErrPasskeyVerificationFailed) {
+		t.Fatalf("err = %v, want ErrPasskeyVerificationFailed", err)
+	}
+
+	stored, err := f.passkeys.ByCredentialID(passkey.CredentialID)
+	if err != nil {
+		t.Fatalf("load passkey: %v", err)
+	}
+	if stored.SignCount != 5 {
+		t.Fatalf("sign_count = %d, want 5", stored.SignCount)
+	}
+}
+
+func TestPasskeyCeremonyBoundToUser(t *testing.T) {
+	f := newPasskeyFixture(t)
+	user := f.createUser(t, nil)
+	other := f.createUser(t, nil)
+	authenticator := newSoftAuthenticator(t)
+
+	options, ceremony, err := f.service.BeginRegistration(user.ID)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+
+	_, err = f.service.FinishRegistration(other.ID, "", ceremony, authenticator.create(t, options))
+	if !errors.Is(err, ErrInvalidPasskeyCeremony) {
+		t.Fatalf("err = %v, want ErrInvalidPasskeyCeremony", err)
+	}
+
+	// A registration ceremony can't finish a sign-in
+	_, err = f.service.FinishLogin(ceremony, authenticator.get(t, options))
+	if !errors.Is(err, ErrInvalidPasskeyCeremony) {
+		t.Fatalf("err = %v, want ErrInvalidPasskeyCeremony", err)
+	}
+}
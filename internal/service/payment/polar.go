@@ -1,11 +1,14 @@
 package payment
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	polargo "github.com/polarsource/polar-go"
@@ -21,27 +24,30 @@ type PolarProvider struct {
 	cfg                 *config.Config
 	subscriptionService *service.SubscriptionService
 	client              *polargo.Polar
+	apiURL              string
+	httpClient          *http.Client
 }
 
 func NewPolarProvider(cfg *config.Config, subscriptionService *service.SubscriptionService) *PolarProvider {
-	var serverOption polargo.SDKOption
+	server := polargo.ServerProduction
 	if cfg.PolarSandboxMode {
-		serverOption = polargo.WithServer(polargo.ServerSandbox)
+		server = polargo.ServerSandbox
 		slog.Info("polar using sandbox mode", "app_env", cfg.AppEnv)
 	} else {
-		serverOption = polargo.WithServer(polargo.ServerProduction)
 		slog.Info("polar using production mode", "app_env", cfg.AppEnv)
 	}
 
 	client := polargo.New(
 		polargo.WithSecurity(cfg.PolarAPIKey),
-		serverOption,
+		polargo.WithServer(server),
 	)
 
 	return &PolarProvider{
 		cfg:                 cfg,
 		subscriptionService: subscriptionService,
 		client:              client,
+		apiURL:              polargo.ServerList[server],
+		httpClient:          &http.Client{Timeout: 15 * time.Second},
 	}
 }
 
@@ -49,10 +55,10 @@ func (p *PolarProvider) Name() string {
 	return model.ProviderPolar
 }
 
-func (p *PolarProvider) CreateCheckoutURL(userID, planID, interval, customerEmail, customerName string) (string, error) {
+func (p *PolarProvider) CreateCheckoutURL(organizationID, planID, interval string, seats int, customerEmail, customerName string) (string, error) {
 	ctx := context.Background()
 
-	sub, err := p.subscriptionService.Subscription(userID)
+	sub, err := p.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -66,13 +72,14 @@ func (p *PolarProvider) CreateCheckoutURL(userID, planID, interval, customerEmai
 	returnURL := fmt.Sprintf("%s/app/billing", p.cfg.AppURL)
 
 	metadata := map[string]components.CheckoutCreateMetadata{
-		"user_id":         components.CreateCheckoutCreateMetadataStr(userID),
+		"organization_id": components.CreateCheckoutCreateMetadataStr(organizationID),
 		"subscription_id": components.CreateCheckoutCreateMetadataStr(sub.ID),
 		"plan_id":         components.CreateCheckoutCreateMetadataStr(planID),
 	}
 
 	res, err := p.client.Checkouts.Create(ctx, components.CheckoutCreate{
 		Products:           []string{productID},
+		Seats:              polargo.Int64(int64(seats)),
 		SuccessURL:         polargo.String(successURL),
 		ReturnURL:          polargo.String(returnURL),
 		CustomerEmail:      polargo.String(customerEmail),
@@ -89,14 +96,55 @@ func (p *PolarProvider) CreateCheckoutURL(userID, planID, interval, customerEmai
 		return "", fmt.Errorf("checkout response is nil")
 	}
 
-	slog.Info("polar checkout created", "user_id", userID, "plan_id", planID, "checkout_id", res.Checkout.ID)
+	slog.Info("polar checkout created", "organization_id", organizationID, "plan_id", planID, "seats", seats, "checkout_id", res.Checkout.ID)
 	return res.Checkout.URL, nil
 }
 
-func (p *PolarProvider) CustomerPortalURL(userID string) (string, error) {
+// UpdateSeats changes the seats of a seat-based subscription
+// polar-go has no typed request for seat updates yet, so the API is called directly.
+// Polar confirms the change with a subscription.updated webhook.
+func (p *PolarProvider) UpdateSeats(sub *model.Subscription, seats int) error {
+	if sub.ProviderSubscriptionID == nil || *sub.ProviderSubscriptionID == "" {
+		return fmt.Errorf("no polar subscription for organization: %s", sub.OrganizationID)
+	}
+
+	body, err := json.Marshal(map[string]int{"seats": seats})
+	if err != nil {
+		return fmt.Errorf("failed to encode seat update: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/subscriptions/%s", p.apiURL, url.PathEscape(*sub.ProviderSubscriptionID))
+	req, err := http.NewRequest(http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.cfg.PolarAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update seats: %w", err)
+	}
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			slog.Error("failed to close response body", "error", closeErr, "organization_id", sub.OrganizationID)
+		}
+	}()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("polar seat update failed: %s: %s", resp.Status, message)
+	}
+
+	slog.Info("polar seats updated", "organization_id", sub.OrganizationID, "polar_sub_id", *sub.ProviderSubscriptionID, "seats", seats)
+	return nil
+}
+
+func (p *PolarProvider) CustomerPortalURL(organizationID string) (string, error) {
 	ctx := context.Background()
 
-	sub, err := p.subscriptionService.Subscription(userID)
+	sub, err := p.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -123,7 +171,7 @@ func (p *PolarProvider) CustomerPortalURL(userID string) (string, error) {
 		return "", fmt.Errorf("customer portal response is nil")
 	}
 
-	slog.Info("polar customer portal session created", "user_id", userID)
+	slog.Info("polar customer portal session created", "organization_id", organizationID)
 	return res.CustomerSession.CustomerPortalURL, nil
 }
 
@@ -187,6 +235,7 @@ func (p *PolarProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		Amount            *int              `json:"amount"`
 		Currency          *string           `json:"currency"`
 		RecurringInterval *string           `json:"recurring_interval"`
+		Seats             *int              `json:"seats"`
 		Status            string            `json:"status"`
 		CurrentPeriodEnd  *string           `json:"current_period_end"`
 		Metadata          map[string]string `json:"metadata"`
@@ -197,15 +246,15 @@ func (p *PolarProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		return fmt.Errorf("failed to parse subscription data: %w", err)
 	}
 
-	userID := subscription.Metadata["user_id"]
+	organizationID := metadataOrganizationID(subscription.Metadata)
 	planID := subscription.Metadata["plan_id"]
 
-	if userID == "" {
-		slog.Warn("polar webhook no user_id in subscription metadata, skipping")
+	if organizationID == "" {
+		slog.Warn("polar webhook no organization_id in subscription metadata, skipping")
 		return nil
 	}
 
-	sub, err := p.subscriptionService.Subscription(userID)
+	sub, err := p.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -231,6 +280,10 @@ func (p *PolarProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		sub.Interval = subscription.RecurringInterval
 	}
 
+	if subscription.Seats != nil {
+		sub.Seats = max(*subscription.Seats, 1)
+	}
+
 	if subscription.CurrentPeriodEnd != nil {
 		periodEnd, err := parseTime(*subscription.CurrentPeriodEnd)
 		if err == nil {
@@ -243,7 +296,7 @@ func (p *PolarProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("polar subscription created", "user_id", userID, "plan_id", planID, "polar_sub_id", subscription.ID)
+	slog.Info("polar subscription created", "organization_id", organizationID, "plan_id", planID, "polar_sub_id", subscription.ID)
 	return nil
 }
 
@@ -253,6 +306,7 @@ func (p *PolarProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		Amount            *int    `json:"amount"`
 		Currency          *string `json:"currency"`
 		RecurringInterval *string `json:"recurring_interval"`
+		Seats             *int    `json:"seats"`
 		Status            string  `json:"status"`
 		CurrentPeriodEnd  *string `json:"current_period_end"`
 		EndedAt           *string `json:"ended_at"`
@@ -275,7 +329,7 @@ func (p *PolarProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		if err != nil {
 			return fmt.Errorf("failed to downgrade subscription: %w", err)
 		}
-		slog.Info("polar subscription ended, downgraded to free", "user_id", sub.UserID, "polar_sub_id", subscription.ID)
+		slog.Info("polar subscription ended, downgraded to free", "organization_id", sub.OrganizationID, "polar_sub_id", subscription.ID)
 		return nil
 	}
 
@@ -301,6 +355,10 @@ func (p *PolarProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		sub.Interval = subscription.RecurringInterval
 	}
 
+	if subscription.Seats != nil {
+		sub.Seats = max(*subscription.Seats, 1)
+	}
+
 	if subscription.CurrentPeriodEnd != nil {
 		periodEnd, err := parseTime(*subscription.CurrentPeriodEnd)
 		if err == nil {
@@ -317,7 +375,7 @@ func (p *PolarProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("polar subscription updated", "user_id", sub.UserID, "polar_sub_id", subscription.ID)
+	slog.Info("polar subscription updated", "organization_id", sub.OrganizationID, "polar_sub_id", subscription.ID)
 	return nil
 }
 
@@ -357,7 +415,7 @@ func (p *PolarProvider) handleSubscriptionCanceled(data json.RawMessage) error {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("polar subscription canceled", "user_id", sub.UserID, "polar_sub_id", subData.ID)
+	slog.Info("polar subscription canceled", "organization_id", sub.OrganizationID, "polar_sub_id", subData.ID)
 	return nil
 }
 
@@ -384,7 +442,7 @@ func (p *PolarProvider) handleSubscriptionUncanceled(data json.RawMessage) error
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("polar subscription uncanceled", "user_id", sub.UserID, "polar_sub_id", subData.ID)
+	slog.Info("polar subscription uncanceled", "organization_id", sub.OrganizationID, "polar_sub_id", subData.ID)
 	return nil
 }
 
@@ -414,7 +472,7 @@ func (p *PolarProvider) handleSubscriptionRevoked(data json.RawMessage) error {
 		return fmt.Errorf("failed to downgrade subscription: %w", err)
 	}
 
-	slog.Info("polar subscription revoked, immediate downgrade to free", "user_id", sub.UserID, "polar_sub_id", subData.ID)
+	slog.Info("polar subscription revoked, immediate downgrade to free", "organization_id", sub.OrganizationID, "polar_sub_id", subData.ID)
 	return nil
 }
 
@@ -1,14 +1,22 @@
 package payment
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/templui/goilerplate/internal/model"
+)
 
 // Provider defines the interface that all payment providers must implement
 type Provider interface {
-	// CreateCheckoutURL creates a checkout session and returns the URL
-	CreateCheckoutURL(userID, planID, interval, customerEmail, customerName string) (string, error)
+	// CreateCheckoutURL creates a checkout session for a workspace and returns the URL
+	// Paid plans are priced per seat, seats is the quantity bought
+	CreateCheckoutURL(organizationID, planID, interval string, seats int, customerEmail, customerName string) (string, error)
+
+	// UpdateSeats changes the seat quantity of a workspace's paid subscription
+	UpdateSeats(subscription *model.Subscription, seats int) error
 
-	// CustomerPortalURL creates a customer portal session and returns the URL
-	CustomerPortalURL(userID string) (string, error)
+	// CustomerPortalURL creates a customer portal session for a workspace and returns the URL
+	CustomerPortalURL(organizationID string) (string, error)
 
 	// HandleWebhook processes webhook events from the payment provider
 	HandleWebhook(payload []byte, headers http.Header) error
@@ -16,3 +24,13 @@ type Provider interface {
 	// Name returns the provider name (e.g., "polar", "stripe")
 	Name() string
 }
+
+// metadataOrganizationID returns the workspace a checkout was created for
+// Checkouts created before workspaces only carry user_id, which is also the id
+// of that user's personal workspace
+func metadataOrganizationID(metadata map[string]string) string {
+	if organizationID := metadata["organization_id"]; organizationID != "" {
+		return organizationID
+	}
+	return metadata["user_id"]
+}
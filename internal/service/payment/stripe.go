@@ -10,6 +10,8 @@ import (
 	"github.com/stripe/stripe-go/v81"
 	portalsession "github.com/stripe/stripe-go/v81/billingportal/session"
 	checkoutsession "github.com/stripe/stripe-go/v81/checkout/session"
+	stripesubscription "github.com/stripe/stripe-go/v81/subscription"
+	"github.com/stripe/stripe-go/v81/subscriptionitem"
 	"github.com/stripe/stripe-go/v81/webhook"
 	"github.com/templui/goilerplate/internal/config"
 	"github.com/templui/goilerplate/internal/model"
@@ -37,8 +39,8 @@ func (s *StripeProvider) Name() string {
 	return model.ProviderStripe
 }
 
-func (s *StripeProvider) CreateCheckoutURL(userID, planID, interval, customerEmail, customerName string) (string, error) {
-	sub, err := s.subscriptionService.Subscription(userID)
+func (s *StripeProvider) CreateCheckoutURL(organizationID, planID, interval string, seats int, customerEmail, customerName string) (string, error) {
+	sub, err := s.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -58,12 +60,12 @@ func (s *StripeProvider) CreateCheckoutURL(userID, planID, interval, customerEma
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
 				Price:    stripe.String(priceID),
-				Quantity: stripe.Int64(1),
+				Quantity: stripe.Int64(int64(seats)),
 			},
 		},
 		CustomerEmail: stripe.String(customerEmail),
 		Metadata: map[string]string{
-			"user_id":         userID,
+			"organization_id": organizationID,
 			"subscription_id": sub.ID,
 			"plan_id":         planID,
 		},
@@ -75,12 +77,40 @@ func (s *StripeProvider) CreateCheckoutURL(userID, planID, interval, customerEma
 		return "", fmt.Errorf("failed to create checkout session: %w", err)
 	}
 
-	slog.Info("stripe checkout created", "user_id", userID, "plan_id", planID, "session_id", sess.ID)
+	slog.Info("stripe checkout created", "organization_id", organizationID, "plan_id", planID, "seats", seats, "session_id", sess.ID)
 	return sess.URL, nil
 }
 
-func (s *StripeProvider) CustomerPortalURL(userID string) (string, error) {
-	sub, err := s.subscriptionService.Subscription(userID)
+// UpdateSeats changes the quantity of the subscription's price
+// Stripe prorates the difference on the next invoice and sends customer.subscription.updated
+func (s *StripeProvider) UpdateSeats(sub *model.Subscription, seats int) error {
+	if sub.ProviderSubscriptionID == nil || *sub.ProviderSubscriptionID == "" {
+		return fmt.Errorf("no stripe subscription for organization: %s", sub.OrganizationID)
+	}
+
+	stripeSub, err := stripesubscription.Get(*sub.ProviderSubscriptionID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get stripe subscription: %w", err)
+	}
+
+	if stripeSub.Items == nil || len(stripeSub.Items.Data) == 0 {
+		return fmt.Errorf("subscription has no items")
+	}
+
+	_, err = subscriptionitem.Update(stripeSub.Items.Data[0].ID, &stripe.SubscriptionItemParams{
+		Quantity:          stripe.Int64(int64(seats)),
+		ProrationBehavior: stripe.String("create_prorations"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update subscription quantity: %w", err)
+	}
+
+	slog.Info("stripe seats updated", "organization_id", sub.OrganizationID, "stripe_sub_id", *sub.ProviderSubscriptionID, "seats", seats)
+	return nil
+}
+
+func (s *StripeProvider) CustomerPortalURL(organizationID string) (string, error) {
+	sub, err := s.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -101,7 +131,7 @@ func (s *StripeProvider) CustomerPortalURL(userID string) (string, error) {
 		return "", fmt.Errorf("failed to create customer portal session: %w", err)
 	}
 
-	slog.Info("stripe customer portal session created", "user_id", userID)
+	slog.Info("stripe customer portal session created", "organization_id", organizationID)
 	return portalSession.URL, nil
 }
 
@@ -155,13 +185,13 @@ func (s *StripeProvider) handleCheckoutSessionCompleted(data json.RawMessage) er
 		return fmt.Errorf("failed to parse checkout session: %w", err)
 	}
 
-	userID := checkoutSession.Metadata["user_id"]
-	if userID == "" {
-		slog.Warn("stripe checkout session has no user_id in metadata, skipping")
+	organizationID := metadataOrganizationID(checkoutSession.Metadata)
+	if organizationID == "" {
+		slog.Warn("stripe checkout session has no organization_id in metadata, skipping")
 		return nil
 	}
 
-	sub, err := s.subscriptionService.Subscription(userID)
+	sub, err := s.subscriptionService.Subscription(organizationID)
 	if err != nil {
 		return fmt.Errorf("failed to get subscription: %w", err)
 	}
@@ -175,7 +205,7 @@ func (s *StripeProvider) handleCheckoutSessionCompleted(data json.RawMessage) er
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("stripe checkout completed", "user_id", userID, "customer_id", checkoutSession.CustomerID)
+	slog.Info("stripe checkout completed", "organization_id", organizationID, "customer_id", checkoutSession.CustomerID)
 	return nil
 }
 
@@ -187,7 +217,8 @@ func (s *StripeProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		CurrentPeriodEnd int64  `json:"current_period_end"`
 		Items            struct {
 			Data []struct {
-				Price struct {
+				Quantity int64 `json:"quantity"`
+				Price    struct {
 					ID             string `json:"id"`
 					UnitAmount     int64  `json:"unit_amount"`
 					Currency       string `json:"currency"`
@@ -232,6 +263,7 @@ func (s *StripeProvider) handleSubscriptionCreated(data json.RawMessage) error {
 
 	interval := s.mapStripeInterval(subscription.Items.Data[0].Price.Recurring.Interval)
 	sub.Interval = &interval
+	sub.Seats = max(int(subscription.Items.Data[0].Quantity), 1)
 
 	periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0)
 	sub.CurrentPeriodEnd = &periodEnd
@@ -241,7 +273,7 @@ func (s *StripeProvider) handleSubscriptionCreated(data json.RawMessage) error {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("stripe subscription created", "user_id", sub.UserID, "plan_id", planID, "stripe_sub_id", subscription.ID)
+	slog.Info("stripe subscription created", "organization_id", sub.OrganizationID, "plan_id", planID, "stripe_sub_id", subscription.ID)
 	return nil
 }
 
@@ -253,7 +285,8 @@ func (s *StripeProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		CancelAtPeriodEnd bool  `json:"cancel_at_period_end"`
 		Items            struct {
 			Data []struct {
-				Price struct {
+				Quantity int64 `json:"quantity"`
+				Price    struct {
 					ID        string `json:"id"`
 					UnitAmount int64  `json:"unit_amount"`
 					Currency  string `json:"currency"`
@@ -289,6 +322,7 @@ func (s *StripeProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 
 		interval := s.mapStripeInterval(subscription.Items.Data[0].Price.Recurring.Interval)
 		sub.Interval = &interval
+		sub.Seats = max(int(subscription.Items.Data[0].Quantity), 1)
 	}
 
 	sub.Status = s.mapStripeStatus(subscription.Status)
@@ -306,7 +340,7 @@ func (s *StripeProvider) handleSubscriptionUpdated(data json.RawMessage) error {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	slog.Info("stripe subscription updated", "user_id", sub.UserID, "stripe_sub_id", subscription.ID, "status", sub.Status)
+	slog.Info("stripe subscription updated", "organization_id", sub.OrganizationID, "stripe_sub_id", subscription.ID, "status", sub.Status)
 	return nil
 }
 
@@ -336,7 +370,7 @@ func (s *StripeProvider) handleSubscriptionDeleted(data json.RawMessage) error {
 		return fmt.Errorf("failed to downgrade subscription: %w", err)
 	}
 
-	slog.Info("stripe subscription deleted, downgraded to free", "user_id", sub.UserID, "stripe_sub_id", subscription.ID)
+	slog.Info("stripe subscription deleted, downgraded to free", "organization_id", sub.OrganizationID, "stripe_sub_id", subscription.ID)
 	return nil
 }
 
@@ -370,7 +404,7 @@ func (s *StripeProvider) handleInvoicePaymentSucceeded(data json.RawMessage) err
 		}
 	}
 
-	slog.Info("stripe invoice payment succeeded", "user_id", sub.UserID, "subscription_id", invoice.SubscriptionID)
+	slog.Info("stripe invoice payment succeeded", "organization_id", sub.OrganizationID, "subscription_id", invoice.SubscriptionID)
 	return nil
 }
 
@@ -394,7 +428,7 @@ func (s *StripeProvider) handleInvoicePaymentFailed(data json.RawMessage) error
 		return nil
 	}
 
-	slog.Warn("stripe invoice payment failed", "user_id", sub.UserID, "subscription_id", invoice.SubscriptionID)
+	slog.Warn("stripe invoice payment failed", "organization_id", sub.OrganizationID, "subscription_id", invoice.SubscriptionID)
 	// Note: Don't automatically cancel - Stripe will retry and eventually send subscription.deleted
 	return nil
 }
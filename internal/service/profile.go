@@ -1,13 +1,30 @@
 package service
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/templui/goilerplate/internal/model"
 	"github.com/templui/goilerplate/internal/repository"
 	"github.com/templui/goilerplate/internal/validation"
 )
 
+var (
+	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrInvalidReminderSettings = errors.New("invalid reminder settings")
+)
+
+// NotificationSettings are the user-editable reminder and digest preferences
+type NotificationSettings struct {
+	Timezone             string
+	RemindersEnabled     bool
+	ReminderHour         int
+	ReminderInactiveDays int
+	DigestEnabled        bool
+	DigestWeekday        int
+}
+
 type ProfileService struct {
 	profileRepo repository.ProfileRepository
 }
@@ -36,3 +53,31 @@ func (s *ProfileService) UpdateName(userID, name string) error {
 
 	return s.profileRepo.UpdateName(userID, name)
 }
+
+func (s *ProfileService) UpdateNotificationSettings(userID string, settings NotificationSettings) error {
+	settings.Timezone = strings.TrimSpace(settings.Timezone)
+	_, err := time.LoadLocation(settings.Timezone)
+	if err != nil || settings.Timezone == "" || settings.Timezone == "Local" {
+		return ErrInvalidTimezone
+	}
+
+	if settings.ReminderHour < 0 || settings.ReminderHour > 23 ||
+		settings.ReminderInactiveDays < 1 || settings.ReminderInactiveDays > 30 ||
+		settings.DigestWeekday < 0 || settings.DigestWeekday > 6 {
+		return ErrInvalidReminderSettings
+	}
+
+	profile, err := s.profileRepo.ByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	profile.Timezone = settings.Timezone
+	profile.RemindersEnabled = settings.RemindersEnabled
+	profile.ReminderHour = settings.ReminderHour
+	profile.ReminderInactiveDays = settings.ReminderInactiveDays
+	profile.DigestEnabled = settings.DigestEnabled
+	profile.DigestWeekday = settings.DigestWeekday
+
+	return s.profileRepo.UpdateNotificationSettings(profile)
+}
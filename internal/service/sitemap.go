@@ -11,6 +11,7 @@ import (
 
 // publicRoutes defines all static public routes that should be included in the sitemap
 // Add new public pages here (but not auth-protected pages like /dashboard)
+// Shared goal pages (/g/{slug}) stay out on purpose, they are only found through their link
 var publicRoutes = []struct {
 	Path       string
 	Priority   string
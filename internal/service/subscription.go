@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,37 +12,49 @@ import (
 	"github.com/templui/goilerplate/internal/repository"
 )
 
+var (
+	ErrInvalidPlan = errors.New("unknown plan")
+)
+
+// SubscriptionService manages workspace subscriptions, every organization has exactly one
 type SubscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo           repository.SubscriptionRepository
+	membershipRepo repository.MembershipRepository
+	webhookService *WebhookService
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository) *SubscriptionService {
-	return &SubscriptionService{repo: repo}
+func NewSubscriptionService(repo repository.SubscriptionRepository, membershipRepo repository.MembershipRepository, webhookService *WebhookService) *SubscriptionService {
+	return &SubscriptionService{repo: repo, membershipRepo: membershipRepo, webhookService: webhookService}
 }
 
-func (s *SubscriptionService) CreateFreeSubscription(userID string) error {
+// newFreeSubscription is the subscription every new workspace starts with
+func newFreeSubscription(organizationID string) *model.Subscription {
 	now := time.Now()
-	subscription := &model.Subscription{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		PlanID:    model.SubscriptionPlanFree,
-		Status:    model.SubscriptionStatusActive,
-		CreatedAt: now,
-		UpdatedAt: now,
+	return &model.Subscription{
+		ID:             uuid.New().String(),
+		OrganizationID: organizationID,
+		PlanID:         model.SubscriptionPlanFree,
+		Status:         model.SubscriptionStatusActive,
+		Seats:          1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
+}
 
-	err := s.repo.Create(subscription)
+func (s *SubscriptionService) Subscription(organizationID string) (*model.Subscription, error) {
+	sub, err := s.repo.ByOrganizationID(organizationID)
 	if err != nil {
-		return fmt.Errorf("failed to create free subscription: %w", err)
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
-	return nil
+	return sub, nil
 }
 
-func (s *SubscriptionService) Subscription(userID string) (*model.Subscription, error) {
-	sub, err := s.repo.ByUserID(userID)
+// PersonalSubscription returns the subscription of the user's personal workspace
+func (s *SubscriptionService) PersonalSubscription(userID string) (*model.Subscription, error) {
+	sub, err := s.repo.PersonalByUserID(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
+		return nil, fmt.Errorf("failed to get personal subscription: %w", err)
 	}
 
 	return sub, nil
@@ -71,6 +86,17 @@ func (s *SubscriptionService) UpdateSubscription(sub *model.Subscription) error
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
 
+	// Webhook endpoints belong to users, so every owner of the workspace is told
+	members, err := s.membershipRepo.Members(sub.OrganizationID)
+	if err != nil {
+		slog.Error("failed to list workspace members", "error", err, "organization_id", sub.OrganizationID)
+		return nil
+	}
+	for _, member := range members {
+		if member.IsOwner() {
+			s.webhookService.Publish(member.UserID, model.WebhookEventSubscriptionUpdated, newWebhookSubscription(sub))
+		}
+	}
 	return nil
 }
 
@@ -82,6 +108,31 @@ func (s *SubscriptionService) DowngradeToFree(sub *model.Subscription) error {
 	sub.Amount = nil
 	sub.Currency = ""
 	sub.Interval = nil
+	sub.Seats = 1
 
 	return s.UpdateSubscription(sub)
 }
+
+// OverridePlan sets a plan by hand, used by the admin console for support cases
+// Provider IDs are kept so later provider webhooks still find the subscription,
+// and the next provider update replaces the override.
+func (s *SubscriptionService) OverridePlan(sub *model.Subscription, planID string, seats int) error {
+	if !slices.Contains(model.SubscriptionPlans, planID) {
+		return ErrInvalidPlan
+	}
+
+	sub.PlanID = planID
+	sub.Status = model.SubscriptionStatusActive
+	sub.Seats = max(seats, 1)
+	if planID == model.SubscriptionPlanFree {
+		sub.Seats = 1
+	}
+
+	err := s.UpdateSubscription(sub)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("subscription plan overridden", "organization_id", sub.OrganizationID, "plan_id", planID, "seats", sub.Seats)
+	return nil
+}
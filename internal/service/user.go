@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"time"
 
 	"github.com/templui/goilerplate/internal/model"
 	"github.com/templui/goilerplate/internal/repository"
@@ -22,7 +21,9 @@ type UserService struct {
 	profileRepository   repository.ProfileRepository
 	fileService         *FileService
 	emailService        *EmailService
-	subscriptionService *SubscriptionService
+	organizationService *OrganizationService
+	auditService        *AuditService
+	txManager           repository.TxManager
 }
 
 func NewUserService(
@@ -30,14 +31,18 @@ func NewUserService(
 	profileRepository repository.ProfileRepository,
 	fileService *FileService,
 	emailService *EmailService,
-	subscriptionService *SubscriptionService,
+	organizationService *OrganizationService,
+	auditService *AuditService,
+	txManager repository.TxManager,
 ) *UserService {
 	return &UserService{
 		userRepository:      userRepository,
 		profileRepository:   profileRepository,
 		fileService:         fileService,
 		emailService:        emailService,
-		subscriptionService: subscriptionService,
+		organizationService: organizationService,
+		auditService:        auditService,
+		txManager:           txManager,
 	}
 }
 
@@ -56,7 +61,7 @@ func (s *UserService) ByID(id string) (*model.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UpdatePassword(userID, currentPassword, newPassword string) error {
+func (s *UserService) UpdatePassword(userID, currentPassword, newPassword string, audit model.AuditContext) error {
 	user, err := s.userRepository.ByID(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
@@ -90,23 +95,11 @@ func (s *UserService) UpdatePassword(userID, currentPassword, newPassword string
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	s.auditService.Record(user, model.AuditEventPasswordChanged, audit, nil)
 	return nil
 }
 
-func (s *UserService) DeleteAccount(userID string) error {
-	// Check if user has an active paid subscription or current period is still running
-	subscription, err := s.subscriptionService.Subscription(userID)
-	if err != nil {
-		return fmt.Errorf("failed to check subscription: %w", err)
-	}
-
-	// Block deletion if user has paid plan (not free) AND (subscription is active OR period hasn't ended yet)
-	if subscription.PlanID != model.SubscriptionPlanFree &&
-		(subscription.Status == model.SubscriptionStatusActive ||
-			(subscription.CurrentPeriodEnd != nil && subscription.CurrentPeriodEnd.After(time.Now()))) {
-		return ErrActiveSubscription
-	}
-
+func (s *UserService) DeleteAccount(userID string, audit model.AuditContext) error {
 	user, err := s.userRepository.ByID(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
@@ -123,27 +116,48 @@ func (s *UserService) DeleteAccount(userID string) error {
 		name = profile.Name
 	}
 
-	err = s.fileService.DeleteAllUserFilesFromStorage(userID)
+	// Workspaces and the user go in one transaction, storage and email follow the commit
+	var removal *UserRemoval
+	var files []*model.File
+	err = s.txManager.WithTx(func(tx *repository.Repositories) error {
+		// Blocked by a running paid plan or sole ownership of a shared workspace,
+		// otherwise deletes the user's own workspaces and hands over goals in shared ones
+		removal, err = s.organizationService.RemoveUser(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Collected before the rows cascade with the user, attachments in shared
+		// workspaces were handed over by RemoveUser and stay
+		files, err = tx.Files.AllUserFiles(userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user files: %w", err)
+		}
+
+		// Foreign key CASCADE will automatically delete:
+		// - profiles (ON DELETE CASCADE)
+		// - tokens (ON DELETE CASCADE)
+		// - files (ON DELETE CASCADE) - DB records only, physical files are deleted below
+		// - memberships (ON DELETE CASCADE)
+		err = tx.Users.Delete(userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		// Log warning but don't fail - orphaned files are better than failed deletion
-		slog.Warn("failed to delete user files from storage", "user_id", userID, "error", err)
+		return err
 	}
 
+	s.organizationService.FinishRemoveUser(removal)
+	// Orphaned files are better than a failed deletion, failures are only logged
+	s.fileService.DeleteFilesFromStorage(files)
+
 	err = s.emailService.SendAccountDeletedEmail(user.Email, name)
 	if err != nil {
 		slog.Warn("failed to send account deleted email", "user_id", userID, "email", user.Email, "error", err)
 	}
 
-	// Delete user from database
-	// Foreign key CASCADE will automatically delete:
-	// - profiles (ON DELETE CASCADE)
-	// - tokens (ON DELETE CASCADE)
-	// - files (ON DELETE CASCADE) - DB records only, physical files already deleted above
-	// - subscriptions (ON DELETE CASCADE)
-	err = s.userRepository.Delete(userID)
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
-
+	s.auditService.Record(user, model.AuditEventAccountDeleted, audit, nil)
 	return nil
 }
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	standardwebhooks "github.com/standard-webhooks/standard-webhooks/libraries/go"
+	"github.com/templui/goilerplate/internal/model"
+	"github.com/templui/goilerplate/internal/queue"
+	"github.com/templui/goilerplate/internal/repository"
+)
+
+// JobDeliverWebhook is the queue job type for outbound webhooks, handled by WebhookService.Deliver
+const JobDeliverWebhook = "webhook.deliver"
+
+const (
+	webhookMaxEndpoints     = 10
+	webhookMaxURLLen        = 2048
+	webhookTimeout          = 10 * time.Second
+	webhookResponseLimit    = 1024 // Bytes of the response body kept in the delivery log
+	webhookDeliveryLogLimit = 50
+	webhookRetention        = 30 * 24 * time.Hour
+)
+
+var (
+	ErrWebhooksNotAvailable = errors.New("webhooks are available on paid plans")
+	ErrInvalidWebhookURL    = errors.New("invalid endpoint url")
+	ErrInvalidWebhookEvents = errors.New("select at least one event")
+	ErrWebhookLimitReached  = fmt.Errorf("you can have at most %d endpoints", webhookMaxEndpoints)
+	errWebhookBlockedIP     = errors.New("endpoint resolves to a private address")
+)
+
+// WebhookService sends signed events (Standard Webhooks) to user-registered endpoints
+// Every event is stored as a delivery and sent by the job queue, which retries with backoff.
+type WebhookService struct {
+	endpointRepo     repository.WebhookEndpointRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	subscriptionRepo repository.SubscriptionRepository
+	queue            *queue.Queue
+	client           *http.Client
+	appName          string
+	isDev            bool
+}
+
+// webhookJob is the queued job payload of a delivery
+type webhookJob struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// webhookEvent is the JSON body sent to endpoints
+type webhookEvent struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+// NewWebhookService creates the service
+// Outside development, endpoints must use https and may not resolve to private addresses
+func NewWebhookService(
+	endpointRepo repository.WebhookEndpointRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	jobQueue *queue.Queue,
+	appName string,
+	isDev bool,
+) *WebhookService {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !isDev {
+		dialer.Control = blockPrivateAddresses
+	}
+
+	client := &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			// Never through a proxy, the dialer would only check the proxy's address
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Redirects are reported as failures instead of followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &WebhookService{
+		endpointRepo:     endpointRepo,
+		deliveryRepo:     deliveryRepo,
+		subscriptionRepo: subscriptionRepo,
+		queue:            jobQueue,
+		client:           client,
+		appName:          appName,
+		isDev:            isDev,
+	}
+}
+
+// Available reports whether the user's plan includes webhooks
+// Webhooks belong to the user, so the personal workspace's plan decides.
+func (s *WebhookService) Available(userID string) (bool, error) {
+	subscription, err := s.subscriptionRepo.PersonalByUserID(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return subscription.HasFeature(model.FeatureWebhooks), nil
+}
+
+// CreateEndpoint registers a URL for the given event types
+func (s *WebhookService) CreateEndpoint(userID, endpointURL, description string, events []string) (*model.WebhookEndpoint, error) {
+	available, err := s.Available(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrWebhooksNotAvailable
+	}
+
+	endpointURL = strings.TrimSpace(endpointURL)
+	if !s.validURL(endpointURL) {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	if len(events) == 0 {
+		return nil, ErrInvalidWebhookEvents
+	}
+	for _, event := range events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return nil, ErrInvalidWebhookEvents
+		}
+	}
+
+	existing, err := s.endpointRepo.ByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+	if len(existing) >= webhookMaxEndpoints {
+		return nil, ErrWebhookLimitReached
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	endpoint := &model.WebhookEndpoint{
+		UserID:      userID,
+		URL:         endpointURL,
+		Description: strings.TrimSpace(description),
+		Secret:      secret,
+		Events:      strings.Join(events, ","),
+	}
+
+	err = s.endpointRepo.Create(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create endpoint: %w", err)
+	}
+
+	slog.Info("webhook endpoint created", "user_id", userID, "endpoint_id", endpoint.ID)
+	return endpoint, nil
+}
+
+func (s *WebhookService) Endpoints(userID string) ([]*model.WebhookEndpoint, error) {
+	return s.endpointRepo.ByUserID(userID)
+}
+
+func (s *WebhookService) Endpoint(userID, endpointID string) (*model.WebhookEndpoint, error) {
+	return s.endpointRepo.ByID(userID, endpointID)
+}
+
+// Deliveries returns the recent delivery log of an endpoint owned by the user
+func (s *WebhookService) Deliveries(userID, endpointID string) ([]*model.WebhookDelivery, error) {
+	_, err := s.endpointRepo.ByID(userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliveryRepo.ByEndpointID(endpointID, webhookDeliveryLogLimit)
+}
+
+func (s *WebhookService) DeleteEndpoint(userID, endpointID string) error {
+	err := s.endpointRepo.Delete(userID, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to delete endpoint: %w", err)
+	}
+
+	slog.Info("webhook endpoint deleted", "user_id", userID, "endpoint_id", endpointID)
+	return nil
+}
+
+// SendTest queues a webhook.test event for a single endpoint
+func (s *WebhookService) SendTest(userID, endpointID string) error {
+	available, err := s.Available(userID)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrWebhooksNotAvailable
+	}
+
+	endpoint, err := s.endpointRepo.ByID(userID, endpointID)
+	if err != nil {
+		return err
+	}
+
+	return s.enqueue(endpoint, model.WebhookEventTest, map[string]string{
+		"message": "This is a test event from " + s.appName,
+	})
+}
+
+// Publish queues an event for every endpoint of the user subscribed to it
+// Failures are logged, publishing never fails the action that triggered the event.
+func (s *WebhookService) Publish(userID, eventType string, data any) {
+	endpoints, err := s.endpointRepo.ByUserID(userID)
+	if err != nil {
+		slog.Error("failed to list webhook endpoints", "error", err, "user_id", userID, "event_type", eventType)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	// Endpoints stay registered after a downgrade but only receive the
+	// subscription.updated event that tells them about it
+	if eventType != model.WebhookEventSubscriptionUpdated {
+		available, err := s.Available(userID)
+		if err != nil {
+			slog.Error("failed to check webhook availability", "error", err, "user_id", userID)
+			return
+		}
+		if !available {
+			return
+		}
+	}
+
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(eventType) {
+			continue
+		}
+
+		err = s.enqueue(endpoint, eventType, data)
+		if err != nil {
+			slog.Error("failed to queue webhook", "error", err, "endpoint_id", endpoint.ID, "event_type", eventType)
+		}
+	}
+}
+
+// Deliver sends a queued delivery, registered as the JobDeliverWebhook queue handler
+// Non-2xx responses return an error so the queue retries with backoff
+func (s *WebhookService) Deliver(ctx context.Context, payload []byte) error {
+	var job webhookJob
+	err := json.Unmarshal(payload, &job)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("invalid webhook payload: %w", err))
+	}
+
+	delivery, err := s.deliveryRepo.ByID(job.DeliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return queue.Permanent(err) // Endpoint was deleted
+		}
+		return fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	endpoint, err := s.endpointRepo.ByIDUnscoped(delivery.EndpointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+			return queue.Permanent(err)
+		}
+		return fmt.Errorf("failed to get endpoint: %w", err)
+	}
+
+	statusCode, body, sendErr := s.send(ctx, endpoint, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseBody = body
+	delivery.ResponseStatus = nil
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	}
+	delivery.LastError = ""
+	if sendErr != nil {
+		delivery.LastError = sendErr.Error()
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = model.WebhookDeliveryStatusSucceeded
+	case delivery.Attempts >= queue.DefaultMaxAttempts:
+		delivery.Status = model.WebhookDeliveryStatusFailed
+	default:
+		delivery.Status = model.WebhookDeliveryStatusPending
+	}
+
+	err = s.deliveryRepo.RecordAttempt(delivery)
+	if err != nil {
+		slog.Error("failed to record webhook attempt", "error", err, "delivery_id", delivery.ID)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("webhook %s to endpoint %s failed: %w", delivery.EventType, endpoint.ID, sendErr)
+	}
+
+	slog.Info("webhook delivered", "delivery_id", delivery.ID, "endpoint_id", endpoint.ID, "event_type", delivery.EventType)
+	return nil
+}
+
+// Cleanup deletes deliveries past the retention period
+// Has the scheduler job signature
+func (s *WebhookService) Cleanup(now time.Time) error {
+	deleted, err := s.deliveryRepo.DeleteBefore(now.Add(-webhookRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	if deleted > 0 {
+		slog.Info("webhook deliveries deleted", "count", deleted)
+	}
+	return nil
+}
+
+// enqueue stores a delivery and queues it
+// The payload is built once, so retries send exactly the same body
+func (s *WebhookService) enqueue(endpoint *model.WebhookEndpoint, eventType string, data any) error {
+	payload, err := json.Marshal(webhookEvent{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	delivery := &model.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		EventType:  eventType,
+		Payload:    string(payload),
+	}
+
+	err = s.deliveryRepo.Create(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	err = s.queue.Enqueue(JobDeliverWebhook, webhookJob{DeliveryID: delivery.ID})
+	if err != nil {
+		return fmt.Errorf("failed to queue delivery: %w", err)
+	}
+
+	return nil
+}
+
+// send signs and posts a delivery, returning the response status and truncated body
+// The delivery id is the webhook-id, so receivers can deduplicate retries
+func (s *WebhookService) send(ctx context.Context, endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery) (int, string, error) {
+	wh, err := standardwebhooks.NewWebhook(endpoint.Secret)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid endpoint secret: %w", err)
+	}
+
+	timestamp := time.Now()
+	signature, err := wh.Sign(delivery.ID, timestamp, []byte(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to sign payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.appName+"-Webhooks/1.0")
+	req.Header.Set(standardwebhooks.HeaderWebhookID, delivery.ID)
+	req.Header.Set(standardwebhooks.HeaderWebhookTimestamp, fmt.Sprintf("%d", timestamp.Unix()))
+	req.Header.Set(standardwebhooks.HeaderWebhookSignature, signature)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			slog.Error("failed to close response body", "error", closeErr, "endpoint_id", endpoint.ID)
+		}
+	}()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(body), nil
+}
+
+// validURL accepts absolute https URLs (http too in development)
+func (s *WebhookService) validURL(raw string) bool {
+	if raw == "" || len(raw) > webhookMaxURLLen {
+		return false
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return false
+	}
+
+	return parsed.Scheme == "https" || (s.isDev && parsed.Scheme == "http")
+}
+
+// blockPrivateAddresses is a net.Dialer Control func that refuses loopback, private
+// and link-local addresses. Runs after DNS resolution, so rebinding can't bypass it.
+func blockPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errWebhookBlockedIP
+	}
+
+	return nil
+}
+
+// generateWebhookSecret returns a Standard Webhooks secret: "whsec_" + base64 of 24 random bytes
+func generateWebhookSecret() (string, error) {
+	key := make([]byte, 24)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"time"
+
+	"github.com/templui/goilerplate/internal/model"
+)
+
+// Event payloads, shaped like the /api/v1 responses
+
+type webhookGoal struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	CurrentStep int       `json:"current_step"`
+	TargetSteps int       `json:"target_steps"`
+	Cadence     string    `json:"cadence"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type webhookGoalEntry struct {
+	Goal webhookGoal `json:"goal"`
+	Step int         `json:"step"`
+}
+
+type webhookSubscription struct {
+	Plan             string     `json:"plan"`
+	Status           string     `json:"status"`
+	Interval         *string    `json:"interval"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end"`
+}
+
+func newWebhookGoal(goal *model.Goal) webhookGoal {
+	return webhookGoal{
+		ID:          goal.ID,
+		Title:       goal.Title,
+		Description: goal.Description,
+		Status:      goal.Status,
+		CurrentStep: goal.CurrentStep,
+		TargetSteps: goal.TargetSteps,
+		Cadence:     goal.Cadence,
+		CreatedAt:   goal.CreatedAt,
+		UpdatedAt:   goal.UpdatedAt,
+	}
+}
+
+func newWebhookSubscription(subscription *model.Subscription) webhookSubscription {
+	return webhookSubscription{
+		Plan:             subscription.PlanID,
+		Status:           subscription.Status,
+		Interval:         subscription.Interval,
+		CurrentPeriodEnd: subscription.CurrentPeriodEnd,
+	}
+}
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid file signature")
+	ErrURLExpired       = errors.New("file url has expired")
+	ErrInvalidPath      = errors.New("invalid file path")
+)
+
+// LocalStorage implements Storage on the local filesystem
+// Files are served through the app at /uploads/ with signed, expiring URLs
+// (same semantics as S3 presigned URLs), so no external services are needed.
+// Public files are served without a signature.
+// Works for development, CI and single-server deployments.
+type LocalStorage struct {
+	root                 *os.Root
+	basePath             string
+	urlPrefix            string        // Route prefix files are served from (e.g. /uploads)
+	signingKey           []byte        // HMAC key for signed URLs
+	presignExpiryPrivate time.Duration // Expiry for private files (1 hour default)
+}
+
+// LocalConfig holds configuration for local storage
+type LocalConfig struct {
+	BasePath             string // Directory files are stored in
+	URLPrefix            string // Route prefix files are served from
+	SigningKey           string // Secret used to sign file URLs
+	PresignExpiryPrivate time.Duration
+}
+
+// NewLocalStorage creates a new local storage instance
+// The base directory is created if it doesn't exist
+func NewLocalStorage(cfg LocalConfig) (*LocalStorage, error) {
+	if cfg.SigningKey == "" {
+		return nil, errors.New("signing key is required for local storage")
+	}
+
+	err := os.MkdirAll(cfg.BasePath, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	// os.Root confines all file operations to the base directory
+	// (prevents path traversal via "../" or symlinks)
+	root, err := os.OpenRoot(cfg.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage directory: %w", err)
+	}
+
+	return &LocalStorage{
+		root:                 root,
+		basePath:             cfg.BasePath,
+		urlPrefix:            strings.TrimSuffix(cfg.URLPrefix, "/"),
+		signingKey:           []byte(cfg.SigningKey),
+		presignExpiryPrivate: cfg.PresignExpiryPrivate,
+	}, nil
+}
+
+// Save stores a file on disk
+// Writes to a temp file first and renames it, so readers never see partial files
+func (s *LocalStorage) Save(filePath string, file io.Reader) error {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = s.root.MkdirAll(path.Dir(name), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpName := name + ".tmp"
+	dst, err := s.root.OpenFile(tmpName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(dst, file)
+	closeErr := dst.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		removeErr := s.root.Remove(tmpName)
+		if removeErr != nil {
+			slog.Warn("failed to remove temp file", "error", removeErr, "path", tmpName)
+		}
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	err = s.root.Rename(tmpName, name)
+	if err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a file from disk
+// Missing files are not an error (matches S3 DeleteObject semantics)
+func (s *LocalStorage) Delete(filePath string) error {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = s.root.Remove(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// Open opens a stored file for reading
+func (s *LocalStorage) Open(filePath string) (*os.File, error) {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.root.Open(name)
+}
+
+// URL returns a signed URL for accessing the file
+// Deprecated: Use PublicURL() or PresignedURL() directly
+func (s *LocalStorage) URL(filePath string) string {
+	return s.PublicURL(filePath)
+}
+
+// PublicURL returns the unsigned URL of a public file (avatars, profile pics)
+// Files under public/ are served to anyone, only private files need a signature.
+func (s *LocalStorage) PublicURL(filePath string) string {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", s.urlPrefix, name)
+}
+
+// IsPublic reports whether a storage path is served without a signature
+// Storage paths are prefixed with public/ or private/ (see FileService.Upload)
+func IsPublic(filePath string) bool {
+	name, err := cleanPath(filePath)
+	return err == nil && strings.HasPrefix(name, "public/")
+}
+
+// PresignedURL generates a signed URL for temporary access (for private files)
+// Format: /uploads/{path}?expires={unix}&signature={hmac}
+func (s *LocalStorage) PresignedURL(filePath string, expiry time.Duration) (string, error) {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(name, expires))
+
+	return fmt.Sprintf("%s/%s?%s", s.urlPrefix, name, query.Encode()), nil
+}
+
+// GetPresignExpiryPrivate returns the configured presign expiry for private files
+func (s *LocalStorage) GetPresignExpiryPrivate() time.Duration {
+	return s.presignExpiryPrivate
+}
+
+// Verify checks the signature and expiry of a signed file URL
+func (s *LocalStorage) Verify(filePath, expires, signature string) error {
+	name, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := s.sign(name, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of path and expiry
+func (s *LocalStorage) sign(name string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(name + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Close releases the storage root directory
+func (s *LocalStorage) Close() error {
+	return s.root.Close()
+}
+
+// cleanPath normalizes a storage path to a relative slash-separated name
+// Storage paths may be built with filepath.Join, so OS separators are converted
+func cleanPath(filePath string) (string, error) {
+	name := path.Clean("/" + filepath.ToSlash(filePath))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || name == "." {
+		return "", ErrInvalidPath
+	}
+	return name, nil
+}
@@ -12,21 +12,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	cfg "github.com/templui/goilerplate/internal/config"
 )
 
-// Storage defines the interface for file storage operations
-type Storage interface {
-	// Save stores a file at the given path
-	Save(path string, file io.Reader) error
-
-	// Delete removes a file at the given path
-	Delete(path string) error
-
-	// URL returns the public URL for accessing the file
-	URL(path string) string
-}
-
 // S3Storage implements FileStorage for S3-compatible storage
 // Works with AWS S3, MinIO, DigitalOcean Spaces, Cloudflare R2, etc.
 type S3Storage struct {
@@ -51,27 +38,6 @@ type S3Config struct {
 	PresignExpiryPrivate time.Duration // Expiry for private files
 }
 
-// New creates an S3-compatible storage instance from app config
-// Supports: AWS S3, MinIO, DigitalOcean Spaces, Cloudflare R2, Backblaze B2, etc.
-// For development: Use MinIO (see docker-compose.yml)
-// For production: Use any S3-compatible cloud provider
-func New(c *cfg.Config) (Storage, error) {
-	slog.Info("initializing S3 storage",
-		"bucket", c.S3Bucket,
-		"region", c.S3Region,
-		"endpoint", c.S3Endpoint,
-	)
-	return NewS3Storage(S3Config{
-		Region:               c.S3Region,
-		Bucket:               c.S3Bucket,
-		AccessKey:            c.S3AccessKey,
-		SecretKey:            c.S3SecretKey,
-		Endpoint:             c.S3Endpoint,
-		PresignExpiryPublic:  c.S3PresignExpiryPublic,
-		PresignExpiryPrivate: c.S3PresignExpiryPrivate,
-	})
-}
-
 // NewS3Storage creates a new S3 storage instance
 func NewS3Storage(cfg S3Config) (*S3Storage, error) {
 	ctx := context.Background()
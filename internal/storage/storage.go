@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	cfg "github.com/templui/goilerplate/internal/config"
+)
+
+const (
+	DriverS3    = "s3"
+	DriverLocal = "local"
+)
+
+// Storage defines the interface for file storage operations
+type Storage interface {
+	// Save stores a file at the given path
+	Save(path string, file io.Reader) error
+
+	// Delete removes a file at the given path
+	Delete(path string) error
+
+	// URL returns the public URL for accessing the file
+	URL(path string) string
+}
+
+// Presigner is implemented by storages that issue expiring URLs
+// (S3 presigned URLs, signed local URLs)
+type Presigner interface {
+	// PublicURL returns a URL with long expiry for public files
+	PublicURL(path string) string
+
+	// PresignedURL returns a URL valid for the given duration
+	PresignedURL(path string, expiry time.Duration) (string, error)
+
+	// GetPresignExpiryPrivate returns the expiry used for private files
+	GetPresignExpiryPrivate() time.Duration
+}
+
+// New creates the storage backend selected by STORAGE_DRIVER
+// s3 (default): AWS S3, MinIO, DigitalOcean Spaces, Cloudflare R2, Backblaze B2, etc.
+// local: files on disk, served by the app via signed URLs (no external services)
+func New(c *cfg.Config) (Storage, error) {
+	switch c.StorageDriver {
+	case DriverLocal:
+		slog.Info("initializing local storage", "path", c.StorageLocalPath)
+		return NewLocalStorage(LocalConfig{
+			BasePath:             c.StorageLocalPath,
+			URLPrefix:            "/uploads",
+			SigningKey:           urlSigningKey(c.JWTSecret),
+			PresignExpiryPrivate: c.S3PresignExpiryPrivate,
+		})
+
+	case DriverS3, "":
+		slog.Info("initializing S3 storage",
+			"bucket", c.S3Bucket,
+			"region", c.S3Region,
+			"endpoint", c.S3Endpoint,
+		)
+		return NewS3Storage(S3Config{
+			Region:               c.S3Region,
+			Bucket:               c.S3Bucket,
+			AccessKey:            c.S3AccessKey,
+			SecretKey:            c.S3SecretKey,
+			Endpoint:             c.S3Endpoint,
+			PresignExpiryPublic:  c.S3PresignExpiryPublic,
+			PresignExpiryPrivate: c.S3PresignExpiryPrivate,
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s (supported: s3, local)", c.StorageDriver)
+	}
+}
+
+// urlSigningKey derives the key for signed file URLs from the app secret
+// A separate key keeps file signatures and session tokens from vouching for each other.
+func urlSigningKey(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("storage-url"))
+	return hex.EncodeToString(mac.Sum(nil))
+}